@@ -20,9 +20,13 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"syscall"
 
 	"github.com/jaredallard/ingress-anubis/internal/config"
 	"github.com/jaredallard/ingress-anubis/internal/controller"
@@ -30,21 +34,53 @@ import (
 )
 
 func entrypoint(log slogext.Logger) error {
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	// Kubernetes sends SIGTERM when stopping the pod, giving us the
+	// termination grace period to shut down.
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
-	cfg, err := config.Load()
+	// Restore the default signal behavior once we're shutting down, so
+	// that a second signal exits immediately.
+	go func() {
+		<-ctx.Done()
+		cancel()
+	}()
+
+	fs := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	flags, err := config.NewFlags(fs)
+	if err != nil {
+		return err
+	}
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(flags.Environment())
 	if err != nil {
 		return err
 	}
 
 	svc := controller.NewKubernetesService(cfg, log)
-	return svc.Run(ctx)
+	err = svc.Run(ctx)
+	if errors.Is(err, controller.ErrConfigChanged) {
+		// Start over with the new configuration, in place of the current
+		// process.
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to determine executable: %w", err)
+		}
+
+		//nolint:gosec // Why: Re-executing ourselves with the same arguments.
+		return syscall.Exec(exe, os.Args, os.Environ())
+	}
+	return err
 }
 
 func main() {
 	log := slogext.New()
-	if err := entrypoint(log); err != nil {
+	if err := entrypoint(log); errors.Is(err, flag.ErrHelp) {
+		return
+	} else if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to run: %v\n", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,11 @@
+{{- with .Values.configFile -}}
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ include "ingress-anubis.fullname" $ }}-config
+  labels:
+    {{- include "ingress-anubis.labels" $ | nindent 4 }}
+data:
+  config.yaml: |
+    {{- toYaml . | nindent 4 }}
+{{- end }}
@@ -26,6 +26,7 @@ spec:
         {{- toYaml . | nindent 8 }}
       {{- end }}
       serviceAccountName: {{ include "ingress-anubis.serviceAccountName" . }}
+      terminationGracePeriodSeconds: {{ .Values.terminationGracePeriodSeconds }}
       {{- with .Values.podSecurityContext }}
       securityContext:
         {{- toYaml . | nindent 8 }}
@@ -42,6 +43,14 @@ spec:
             - name: http-metrics
               containerPort: 8080
               protocol: TCP
+            - name: http-health
+              containerPort: 8081
+              protocol: TCP
+            {{- if .Values.webhook.enabled }}
+            - name: https-webhook
+              containerPort: {{ .Values.webhook.port }}
+              protocol: TCP
+            {{- end }}
           env:
             - name: NAMESPACE
               value: {{ .Release.Namespace }}
@@ -59,6 +68,42 @@ spec:
             - name: VOLUME_MOUNTS
               value: {{ toJson . | squote }}
             {{- end }}
+            {{- with .Values.anubisSidecars }}
+            - name: SIDECARS
+              value: {{ toJson . | squote }}
+            {{- end }}
+            {{- with .Values.ingressClassDefaults }}
+            - name: INGRESS_CLASS_DEFAULTS
+              value: {{ toJson . | squote }}
+            {{- end }}
+            {{- with .Values.anubisResources }}
+            - name: ANUBIS_RESOURCES
+              value: {{ toJson . | squote }}
+            {{- end }}
+            {{- with .Values.anubisTolerations }}
+            - name: ANUBIS_TOLERATIONS
+              value: {{ toJson . | squote }}
+            {{- end }}
+            {{- with .Values.anubisAffinity }}
+            - name: ANUBIS_AFFINITY
+              value: {{ toJson . | squote }}
+            {{- end }}
+            {{- with .Values.anubisTopologySpreadConstraints }}
+            - name: ANUBIS_TOPOLOGY_SPREAD_CONSTRAINTS
+              value: {{ toJson . | squote }}
+            {{- end }}
+            {{- if .Values.configFile }}
+            - name: CONFIG_FILE
+              value: /etc/ingress-anubis/config/config.yaml
+            {{- end }}
+            {{- if .Values.webhook.enabled }}
+            - name: WEBHOOK_ENABLED
+              value: "true"
+            - name: WEBHOOK_PORT
+              value: {{ .Values.webhook.port | quote }}
+            - name: WEBHOOK_CERT_DIR
+              value: /etc/ingress-anubis/webhook-certs
+            {{- end }}
           {{- with .Values.livenessProbe }}
           livenessProbe:
             {{- toYaml . | nindent 12 }}
@@ -71,13 +116,37 @@ spec:
           resources:
             {{- toYaml . | nindent 12 }}
           {{- end }}
-          {{- with .Values.volumeMounts }}
+          {{- if or .Values.volumeMounts .Values.webhook.enabled .Values.configFile }}
           volumeMounts:
+            {{- with .Values.volumeMounts }}
             {{- toYaml . | nindent 12 }}
+            {{- end }}
+            {{- if .Values.configFile }}
+            - name: config
+              mountPath: /etc/ingress-anubis/config
+              readOnly: true
+            {{- end }}
+            {{- if .Values.webhook.enabled }}
+            - name: webhook-certs
+              mountPath: /etc/ingress-anubis/webhook-certs
+              readOnly: true
+            {{- end }}
           {{- end }}
-      {{- with .Values.volumes }}
+      {{- if or .Values.volumes .Values.webhook.enabled .Values.configFile }}
       volumes:
+        {{- with .Values.volumes }}
         {{- toYaml . | nindent 8 }}
+        {{- end }}
+        {{- if .Values.configFile }}
+        - name: config
+          configMap:
+            name: {{ include "ingress-anubis.fullname" . }}-config
+        {{- end }}
+        {{- if .Values.webhook.enabled }}
+        - name: webhook-certs
+          secret:
+            secretName: {{ include "ingress-anubis.fullname" . }}-webhook-tls
+        {{- end }}
       {{- end }}
       {{- with .Values.nodeSelector }}
       nodeSelector:
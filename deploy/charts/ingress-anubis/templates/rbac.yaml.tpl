@@ -22,16 +22,36 @@ metadata:
 rules:
   - apiGroups: [""]
     resources: ["services", "events"]
-    verbs: ["get", "update", "list", "create", "delete"]
+    verbs: ["get", "update", "patch", "list", "create", "delete", "deletecollection"]
   - apiGroups: ["apps"]
     resources: ["deployments"]
-    verbs: ["get", "update", "list", "create", "delete"]
+    verbs: ["get", "update", "patch", "list", "create", "delete", "deletecollection"]
+  # Used to copy the TLS secrets of ingresses into the release namespace
+  # and to roll anubis when the secrets it gets its environment from change.
+  - apiGroups: [""]
+    resources: ["secrets"]
+    verbs: ["get", "update", "patch", "list", "watch", "create", "delete", "deletecollection"]
+  # Used to find the anubis pods to scrape for difficulty auto-tuning.
+  - apiGroups: [""]
+    resources: ["pods"]
+    verbs: ["list"]
+  # Used to maintain the Grafana dashboard and bot policy ConfigMaps, and
+  # to roll anubis when the ConfigMaps it gets its environment from change.
+  - apiGroups: [""]
+    resources: ["configmaps"]
+    verbs: ["get", "update", "patch", "list", "watch", "create", "delete", "deletecollection"]
+  - apiGroups: ["policy"]
+    resources: ["poddisruptionbudgets"]
+    verbs: ["get", "update", "patch", "list", "watch", "create", "delete", "deletecollection"]
+  - apiGroups: ["networking.k8s.io"]
+    resources: ["networkpolicies"]
+    verbs: ["get", "update", "patch", "list", "watch", "create", "delete", "deletecollection"]
   - apiGroups: ["coordination.k8s.io"]
     resources: ["leases"]
     verbs: ["get", "update", "list", "create", "delete"]
   - apiGroups: ["extensions", "networking.k8s.io"]
     resources: ["ingresses"]
-    verbs: ["get", "update",  "list", "create", "delete"]
+    verbs: ["get", "update", "patch", "list", "create", "delete", "deletecollection"]
 ---
 apiVersion: rbac.authorization.k8s.io/v1
 kind: ClusterRole
@@ -42,13 +62,42 @@ metadata:
 rules:
   - apiGroups: [""]
     resources: ["services"]
-    verbs: ["list", "watch"]
+    # create/patch/delete(collection) are used by in-place interposition.
+    verbs: ["get", "list", "watch", "create", "update", "patch", "delete", "deletecollection"]
   - apiGroups: ["apps"]
     resources: ["deployments"]
     verbs: ["list", "watch"]
+  # Used to copy bot policies into the release namespace, and to apply
+  # them right away when they're created or changed.
+  - apiGroups: [""]
+    resources: ["configmaps"]
+    verbs: ["get", "list", "watch"]
+  # Used to copy the TLS secrets of ingresses into the release namespace.
+  - apiGroups: [""]
+    resources: ["secrets"]
+    verbs: ["get"]
   - apiGroups: ["extensions", "networking.k8s.io"]
     resources: ["ingresses", "ingresses/status"]
     verbs: ["get", "list", "watch", "patch"]
+  - apiGroups: ["", "events.k8s.io"]
+    resources: ["events"]
+    verbs: ["create", "patch", "update"]
+  # Used to check that the ingress classes of wrapped ingresses exist and
+  # to create ours (see MANAGE_INGRESS_CLASSES).
+  - apiGroups: ["networking.k8s.io"]
+    resources: ["ingressclasses"]
+    verbs: ["get", "list", "watch", "create"]
+  # Used to get the defaults of ingress classes (see INGRESS_CLASS_PARAMS).
+  - apiGroups: ["ingress-anubis.jaredallard.github.com"]
+    resources: ["anubisingressclassparams"]
+    verbs: ["get", "list", "watch"]
+  # Used by the preflight checks gating readiness.
+  - apiGroups: [""]
+    resources: ["namespaces"]
+    verbs: ["get"]
+  - apiGroups: ["authorization.k8s.io"]
+    resources: ["selfsubjectaccessreviews"]
+    verbs: ["create"]
 ---
 apiVersion: rbac.authorization.k8s.io/v1
 kind: ClusterRoleBinding
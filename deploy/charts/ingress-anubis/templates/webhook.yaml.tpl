@@ -0,0 +1,59 @@
+{{- if .Values.webhook.enabled }}
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{ include "ingress-anubis.fullname" . }}-webhook
+  labels:
+    {{- include "ingress-anubis.labels" . | nindent 4 }}
+spec:
+  type: ClusterIP
+  ports:
+    - name: https-webhook
+      port: 443
+      targetPort: https-webhook
+      protocol: TCP
+  selector:
+    {{- include "ingress-anubis.selectorLabels" . | nindent 4 }}
+---
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: {{ include "ingress-anubis.fullname" . }}-webhook
+  labels:
+    {{- include "ingress-anubis.labels" . | nindent 4 }}
+spec:
+  secretName: {{ include "ingress-anubis.fullname" . }}-webhook-tls
+  dnsNames:
+    - {{ include "ingress-anubis.fullname" . }}-webhook.{{ .Release.Namespace }}.svc
+    - {{ include "ingress-anubis.fullname" . }}-webhook.{{ .Release.Namespace }}.svc.cluster.local
+  issuerRef:
+    {{- toYaml .Values.webhook.issuerRef | nindent 4 }}
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: {{ include "ingress-anubis.fullname" . }}
+  labels:
+    {{- include "ingress-anubis.labels" . | nindent 4 }}
+  annotations:
+    cert-manager.io/inject-ca-from: {{ .Release.Namespace }}/{{ include "ingress-anubis.fullname" . }}-webhook
+webhooks:
+  - name: references.ingress-anubis.jaredallard.github.com
+    admissionReviewVersions: ["v1"]
+    sideEffects: None
+    failurePolicy: {{ .Values.webhook.failurePolicy }}
+    clientConfig:
+      service:
+        name: {{ include "ingress-anubis.fullname" . }}-webhook
+        namespace: {{ .Release.Namespace }}
+        path: /validate-v1-references
+    namespaceSelector:
+      matchLabels:
+        kubernetes.io/metadata.name: {{ .Release.Namespace }}
+    rules:
+      - apiGroups: [""]
+        apiVersions: ["v1"]
+        operations: ["DELETE"]
+        resources: ["configmaps", "secrets"]
+        scope: Namespaced
+{{- end }}
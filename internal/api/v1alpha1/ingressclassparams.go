@@ -0,0 +1,151 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package v1alpha1
+
+import (
+	"maps"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// AnubisIngressClassParamsKind is the kind of [AnubisIngressClassParams],
+// referenced by the spec.parameters of ingress classes.
+const AnubisIngressClassParamsKind = "AnubisIngressClassParams"
+
+// AnubisIngressClassParams are the default configuration of the
+// ingresses of the ingress classes referencing them through their
+// spec.parameters, allowing tiers of protection (e.g., "anubis-strict"
+// and "anubis-light") without annotating every ingress. Annotations set
+// on an ingress take precedence.
+type AnubisIngressClassParams struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AnubisIngressClassParamsSpec `json:"spec,omitempty"`
+}
+
+// AnubisIngressClassParamsSpec is the spec of
+// [AnubisIngressClassParams]. Each field is the default of the
+// annotation of the same name.
+type AnubisIngressClassParamsSpec struct {
+	// Difficulty is the default difficulty of the challenges.
+	Difficulty *int `json:"difficulty,omitempty"`
+
+	// AnubisImage is the default anubis image, e.g., to pin another
+	// version.
+	AnubisImage *string `json:"anubisImage,omitempty"`
+
+	// Resources are the default resource requirements of anubis.
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// IngressClass is the default ingress class of the wrapped ingresses.
+	IngressClass *string `json:"ingressClass,omitempty"`
+
+	// Annotations are the defaults of any other annotation, without the
+	// "ingress-anubis.jaredallard.github.com/" prefix. The fields above
+	// take precedence.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// AnubisIngressClassParamsList is a list of [AnubisIngressClassParams].
+type AnubisIngressClassParamsList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []AnubisIngressClassParams `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AnubisIngressClassParams{}, &AnubisIngressClassParamsList{})
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *AnubisIngressClassParamsSpec) DeepCopyInto(out *AnubisIngressClassParamsSpec) {
+	*out = *in
+	if in.Difficulty != nil {
+		out.Difficulty = new(int)
+		*out.Difficulty = *in.Difficulty
+	}
+	if in.AnubisImage != nil {
+		out.AnubisImage = new(string)
+		*out.AnubisImage = *in.AnubisImage
+	}
+	if in.Resources != nil {
+		out.Resources = in.Resources.DeepCopy()
+	}
+	if in.IngressClass != nil {
+		out.IngressClass = new(string)
+		*out.IngressClass = *in.IngressClass
+	}
+	if in.Annotations != nil {
+		out.Annotations = maps.Clone(in.Annotations)
+	}
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *AnubisIngressClassParams) DeepCopyInto(out *AnubisIngressClassParams) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy returns a deep copy of the receiver.
+func (in *AnubisIngressClassParams) DeepCopy() *AnubisIngressClassParams {
+	if in == nil {
+		return nil
+	}
+	out := new(AnubisIngressClassParams)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements [runtime.Object].
+func (in *AnubisIngressClassParams) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *AnubisIngressClassParamsList) DeepCopyInto(out *AnubisIngressClassParamsList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]AnubisIngressClassParams, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+// DeepCopy returns a deep copy of the receiver.
+func (in *AnubisIngressClassParamsList) DeepCopy() *AnubisIngressClassParamsList {
+	if in == nil {
+		return nil
+	}
+	out := new(AnubisIngressClassParamsList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements [runtime.Object].
+func (in *AnubisIngressClassParamsList) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
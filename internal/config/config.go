@@ -18,7 +18,27 @@
 // Package config contains the configuration.
 package config
 
-import "github.com/caarlos0/env/v11"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"iter"
+	"maps"
+	"net"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/env/v11"
+	"github.com/jaredallard/ingress-anubis/internal/translate"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/yaml"
+)
 
 // Config contains the configuration
 type Config struct {
@@ -26,34 +46,260 @@ type Config struct {
 	// create resources in.
 	Namespace string `env:"NAMESPACE" envDefault:"ingress-anubis"`
 
+	// Kubeconfig is the path to a kubeconfig file to use to talk to the
+	// cluster. When not set, the in-cluster configuration is used (or
+	// ~/.kube/config if it exists). Mostly useful for running the
+	// controller out-of-cluster during development.
+	Kubeconfig string `env:"KUBECONFIG"`
+
+	// KubeContext is the kubeconfig context to use. Defaults to the
+	// current context of the kubeconfig.
+	KubeContext string `env:"KUBE_CONTEXT"`
+
+	// ResourcePrefix is prepended to the name of the owning ingress (and
+	// a hash of its namespace and name) to create the name of all
+	// resources created by the controller. When changed, resources
+	// created under the old prefix are cleaned up as each ingress is
+	// reconciled. Must start with a letter and be at most 30 characters.
+	ResourcePrefix string `env:"RESOURCE_PREFIX" envDefault:"ia-"`
+
 	// AnubisVersion is the version of Anubis to use. If not set, then the
 	// latest version known to the controller at build time will be used.
+	// May also be a digest (sha256:...), optionally with a tag
+	// (v1.26.0@sha256:...).
 	//renovate: datasource=github-tags depName=anubis packageName=techarohq/anubis
 	AnubisVersion string `env:"ANUBIS_VERSION" envDefault:"v1.26.0"`
 
 	// AnubisImage is the docker image to use, note that the version (tag)
-	// comes from [Config.AnubisVersion].
+	// comes from [Config.AnubisVersion]. May also be a full reference
+	// pinned to a digest (image@sha256:...), in which case the version
+	// is ignored. See [ImageReference].
 	AnubisImage string `env:"ANUBIS_IMAGE" envDefault:"ghcr.io/techarohq/anubis"`
 
-	// IngressClassName is the ingress class name that Anubis itself
-	// should use.
-	IngressClassName string `env:"INGRESS_CLASS_NAME" envDefault:"anubis"`
+	// CanaryAnubisVersion is a new version of Anubis rolled out to the
+	// ingresses in the canary channel (see [VersionChannelCanary]) first.
+	// Once all of their deployments have been available for
+	// [CanarySoakTime], it's promoted to all other ingresses. Ingresses
+	// setting [IngressConfig.AnubisVersion] aren't affected.
+	CanaryAnubisVersion string `env:"CANARY_ANUBIS_VERSION"`
+
+	// CanarySoakTime is how long the deployments of the canary channel
+	// have to be available with [CanaryAnubisVersion] before it's
+	// promoted.
+	CanarySoakTime time.Duration `env:"CANARY_SOAK_TIME" envDefault:"1h"`
+
+	// AnubisImagePullPolicy is the pull policy of the anubis image. If
+	// not set, the Kubernetes default is used.
+	AnubisImagePullPolicy corev1.PullPolicy `env:"ANUBIS_IMAGE_PULL_POLICY"`
+
+	// ImagePullSecrets are the names of the secrets, in [Config.Namespace],
+	// used to pull the anubis image, e.g., from an internal registry.
+	ImagePullSecrets []string `env:"IMAGE_PULL_SECRETS"`
+
+	// IngressClassNames are the ingress class names that Anubis itself
+	// should use. Example:
+	//
+	// INGRESS_CLASS_NAME="anubis,anubis-strict"
+	IngressClassNames []string `env:"INGRESS_CLASS_NAME" envDefault:"anubis"`
+
+	// ControllerName is the spec.controller of the ingress classes
+	// handled by the controller. Only ingresses whose ingress class, one
+	// of [IngressClassNames], has this controller are managed, allowing
+	// multiple installations per cluster. Must be a domain-prefixed path.
+	ControllerName string `env:"CONTROLLER_NAME" envDefault:"jaredallard.github.io/ingress-anubis"`
+
+	// ManageIngressClasses, when true, creates the ingress classes in
+	// [IngressClassNames] that don't exist, with [ControllerName] as
+	// their controller.
+	ManageIngressClasses bool `env:"MANAGE_INGRESS_CLASSES" envDefault:"true"`
+
+	// WatchNamespaces, when set, limits the ingresses managed by the
+	// controller to those in the listed namespaces. The cache is scoped
+	// to them (and [Namespace]), so resources in other namespaces are
+	// never read. See [Config.WatchesNamespace].
+	WatchNamespaces []string `env:"WATCH_NAMESPACES"`
+
+	// IgnoreNamespaces are namespaces whose ingresses are never managed
+	// by the controller. Ingresses that were are released.
+	IgnoreNamespaces []string `env:"IGNORE_NAMESPACES"`
+
+	// IngressLabelSelector, when set, limits the ingresses managed by the
+	// controller to those matching this label selector, e.g.,
+	// "ingress-anubis.jaredallard.github.com/enabled=true".
+	IngressLabelSelector string `env:"INGRESS_LABEL_SELECTOR"`
+
+	// DryRun makes the controller only observe the cluster: the changes
+	// it would make to the resources it manages are logged, with a diff,
+	// and reported through events on their ingresses, but every write is
+	// sent as a server-side dry run, so nothing is persisted.
+	DryRun bool `env:"DRY_RUN"`
+
+	// ShardCount is the number of instances ingresses are split between
+	// by a hash of their namespace and name, see [Config.InShard]. Each
+	// instance must run in a different [Namespace].
+	ShardCount int `env:"SHARD_COUNT" envDefault:"1"`
+
+	// ShardIndex is the shard of ingresses, from 0 to [ShardCount]-1,
+	// managed by this instance.
+	ShardIndex int `env:"SHARD_INDEX"`
+
+	// IngressClassProfiles maps an ingress class from
+	// [IngressClassNames] to a configmap, in [Namespace], that the
+	// Anubis instances for ingresses of that class get their environment
+	// variables from. These are applied after [EnvFromCM] and before
+	// IngressConfig.EnvFromCM. See [Annotations] for the expected format.
+	// Example:
+	//
+	// INGRESS_CLASS_PROFILES="anubis-strict:anubis-strict-env"
+	IngressClassProfiles map[string]string `env:"INGRESS_CLASS_PROFILES"`
+
+	// IngressClassDefaults is a JSON object mapping an ingress class from
+	// [IngressClassNames] to the default annotations of ingresses of that
+	// class, without the [AnnotationKeyBase] prefix. Annotations set on
+	// the ingress take precedence. See [Config.GetIngressConfig]. Example:
+	//
+	// INGRESS_CLASS_DEFAULTS='{"anubis-strict":{"difficulty":"6","challenge-method":"slow"}}'
+	IngressClassDefaults string `env:"INGRESS_CLASS_DEFAULTS"`
+
+	// IngressClassParams, when true, uses the
+	// AnubisIngressClassParams referenced by the spec.parameters of the
+	// ingress class of ingresses as the defaults of their annotations,
+	// taking precedence over [IngressClassDefaults]. Requires the
+	// AnubisIngressClassParams CRD to be installed in every managed
+	// cluster.
+	IngressClassParams bool `env:"INGRESS_CLASS_PARAMS"`
 
 	// WrappedIngressClassName is the name of the ingressClass to use for
 	// the ingress managed by anubis. While this is configurable, only
 	// nginx has been tested (though, in theory, any should work).
 	WrappedIngressClassName string `env:"WRAPPED_INGRESS_CLASS_NAME" envDefault:"nginx"`
 
+	// WrappedIngressDialect is the annotation dialect of the ingress
+	// controller behind [WrappedIngressClassName]. When not "nginx",
+	// ingress-nginx annotations are translated into their equivalents
+	// (where possible) on the wrapped ingress. Supported values are
+	// nginx, traefik, haproxy and kong.
+	WrappedIngressDialect string `env:"WRAPPED_INGRESS_DIALECT" envDefault:"nginx"`
+
+	// WrappedIngressNamespace is the namespace the ingress controller
+	// behind [WrappedIngressClassName] runs in. See
+	// [NetworkPolicyEnabled].
+	WrappedIngressNamespace string `env:"WRAPPED_INGRESS_NAMESPACE" envDefault:"ingress-nginx"`
+
+	// NetworkPolicyEnabled creates a NetworkPolicy for every anubis
+	// deployment, only allowing traffic to it from
+	// [WrappedIngressNamespace] (and to its metrics port from anywhere)
+	// and from it to the target service and DNS.
+	NetworkPolicyEnabled bool `env:"NETWORK_POLICY_ENABLED"`
+
+	// ServiceIPFamilyPolicy is the spec.ipFamilyPolicy of the anubis
+	// services, e.g., PreferDualStack to serve them over IPv6 as well on
+	// dual-stack clusters. If not set, the Kubernetes default is used.
+	ServiceIPFamilyPolicy corev1.IPFamilyPolicy `env:"SERVICE_IP_FAMILY_POLICY"`
+
+	// ServiceIPFamilies is the spec.ipFamilies of the anubis services,
+	// in order of preference. More than one requires a dual-stack
+	// [ServiceIPFamilyPolicy]. The first (primary) family of existing
+	// services can't be changed, applying them fails until they're
+	// deleted. Example:
+	//
+	// SERVICE_IP_FAMILIES="IPv6,IPv4"
+	ServiceIPFamilies []corev1.IPFamily `env:"SERVICE_IP_FAMILIES"`
+
 	// LeaderElection enables or disables leader election. This should
 	// usually always be on.
 	LeaderElection bool `env:"LEADER_ELECTION" envDefault:"true"`
 
+	// LeaderElectionID is the name of the Lease used for leader
+	// election. Controllers sharing it (e.g., replicas) elect a single
+	// leader between them.
+	LeaderElectionID string `env:"LEADER_ELECTION_ID" envDefault:"ingress-anubis.jaredallard.github.io"`
+
+	// LeaderElectionNamespace is the namespace of the Lease used for
+	// leader election, defaults to [Config.Namespace].
+	LeaderElectionNamespace string `env:"LEADER_ELECTION_NAMESPACE"`
+
+	// LeaderElectionLeaseDuration is how long replicas wait before taking
+	// over leadership when the leader stops renewing it.
+	LeaderElectionLeaseDuration time.Duration `env:"LEADER_ELECTION_LEASE_DURATION" envDefault:"15s"`
+
+	// LeaderElectionRenewDeadline is how long the leader tries to renew
+	// its leadership before giving it up, must be lower than
+	// [Config.LeaderElectionLeaseDuration].
+	LeaderElectionRenewDeadline time.Duration `env:"LEADER_ELECTION_RENEW_DEADLINE" envDefault:"10s"`
+
+	// LeaderElectionRetryPeriod is how long to wait between attempts to
+	// acquire or renew leadership, must be lower than
+	// [Config.LeaderElectionRenewDeadline].
+	LeaderElectionRetryPeriod time.Duration `env:"LEADER_ELECTION_RETRY_PERIOD" envDefault:"2s"`
+
+	// GracefulShutdownTimeout is how long to wait for in-flight
+	// reconciles and other components to stop when shutting down. This
+	// should be lower than the pod's terminationGracePeriodSeconds.
+	GracefulShutdownTimeout time.Duration `env:"GRACEFUL_SHUTDOWN_TIMEOUT" envDefault:"25s"`
+
+	// MaxConcurrentReconciles is the maximum number of ingresses
+	// reconciled at the same time, per cluster.
+	MaxConcurrentReconciles int `env:"MAX_CONCURRENT_RECONCILES" envDefault:"1"`
+
+	// ReconcileRetryBaseDelay and ReconcileRetryMaxDelay are the bounds
+	// of the exponential backoff used when retrying failed reconciles of
+	// an ingress.
+	ReconcileRetryBaseDelay time.Duration `env:"RECONCILE_RETRY_BASE_DELAY" envDefault:"5ms"`
+	ReconcileRetryMaxDelay  time.Duration `env:"RECONCILE_RETRY_MAX_DELAY" envDefault:"1000s"`
+
+	// ReconcileQPS and ReconcileBurst limit how many reconciles are
+	// queued per second overall, across all ingresses.
+	ReconcileQPS   float64 `env:"RECONCILE_QPS" envDefault:"10"`
+	ReconcileBurst int     `env:"RECONCILE_BURST" envDefault:"100"`
+
+	// SyncPeriod is how often every ingress is reconciled again, even if
+	// nothing changed.
+	SyncPeriod time.Duration `env:"SYNC_PERIOD" envDefault:"10h"`
+
+	// DegradedRetryInterval is how often ingresses in a state that often
+	// resolves itself moments later (e.g., without rules while its app is
+	// still being deployed, or referencing a ConfigMap that doesn't exist
+	// yet) are retried, instead of the exponential backoff.
+	DegradedRetryInterval time.Duration `env:"DEGRADED_RETRY_INTERVAL" envDefault:"30s"`
+
+	// DegradedRetryTimeout is how long ingresses are retried every
+	// [DegradedRetryInterval] before they're considered invalid and no
+	// longer retried until they change. 0 considers them invalid right
+	// away.
+	DegradedRetryTimeout time.Duration `env:"DEGRADED_RETRY_TIMEOUT" envDefault:"10m"`
+
 	// Annotations is a map of annotations to set on the managed Anubis
 	// pod. Example:
 	//
 	// ANNOTATIONS="prometheus.io/scrape:true,hello.world/a-thing:1"
 	Annotations map[string]string `env:"ANNOTATIONS"`
 
+	// PodLabels is a map of labels to set on the managed Anubis pod, in
+	// the same format as [Config.Annotations]. Labels used by the
+	// controller itself can't be overridden.
+	PodLabels map[string]string `env:"POD_LABELS"`
+
+	// StripAnnotations is a list of annotations that are never copied
+	// from an ingress to its child ingress. Entries ending in "*" match
+	// all annotations with that prefix. Example:
+	//
+	// STRIP_ANNOTATIONS="external-dns.alpha.kubernetes.io/*,argocd.argoproj.io/tracking-id"
+	StripAnnotations []string `env:"STRIP_ANNOTATIONS"`
+
+	// StripAnnotationPrefixes is a list of annotation prefixes that are
+	// never copied from an ingress to its child ingress, in addition to
+	// [Config.StripAnnotations]. Defaults to annotations of tools
+	// managing the original ingress, which shouldn't act on the child.
+	//nolint:lll // Why: Struct tags can't be wrapped.
+	StripAnnotationPrefixes []string `env:"STRIP_ANNOTATION_PREFIXES" envDefault:"kubectl.kubernetes.io/last-applied-configuration,argocd.argoproj.io/,cert-manager.io/"`
+
+	// ChildIngressAnnotations is a map of annotations, in the same format
+	// as [Config.Annotations], set on every child ingress regardless of
+	// the annotations of the original ingress. Overridden by
+	// [AnnotationKeyChildAnnotations].
+	ChildIngressAnnotations map[string]string `env:"CHILD_INGRESS_ANNOTATIONS"`
+
 	// EnvironmentVariables is a map of environment variables to set on
 	// the manages Anubis pod. See [Annotations] for an example of the
 	// expected format.
@@ -72,12 +318,746 @@ type Config struct {
 	// VolumeMounts is JSON representation of the associated Kubernetes
 	// field applied to the created anubis instances.
 	VolumeMounts string `env:"VOLUME_MOUNTS"`
+
+	// Sidecars is a JSON representation of extra containers
+	// (corev1.Container) added to the created anubis instances, e.g.,
+	// logging or service mesh sidecars.
+	Sidecars string `env:"SIDECARS"`
+
+	// AnubisResources is a JSON representation of the compute resources
+	// (corev1.ResourceRequirements) of the created anubis instances.
+	// Overridden by [AnnotationKeyResources].
+	AnubisResources string `env:"ANUBIS_RESOURCES"`
+
+	// AnubisNodeSelector is the node selector of the created anubis
+	// instances. Overridden by [AnnotationKeyNodeSelector].
+	AnubisNodeSelector map[string]string `env:"ANUBIS_NODE_SELECTOR"`
+
+	// AnubisTolerations is a JSON representation of the tolerations of
+	// the created anubis instances. Overridden by
+	// [AnnotationKeyTolerations].
+	AnubisTolerations string `env:"ANUBIS_TOLERATIONS"`
+
+	// AnubisAffinity is a JSON representation of the affinity of the
+	// created anubis instances. Overridden by [AnnotationKeyAffinity].
+	AnubisAffinity string `env:"ANUBIS_AFFINITY"`
+
+	// AnubisTopologySpreadConstraints is a JSON representation of the
+	// topology spread constraints of the created anubis instances.
+	// Overridden by [AnnotationKeyTopologySpreadConstraints].
+	AnubisTopologySpreadConstraints string `env:"ANUBIS_TOPOLOGY_SPREAD_CONSTRAINTS"`
+
+	// AnubisPriorityClassName is the name of the priority class of the
+	// created anubis instances. Overridden by
+	// [AnnotationKeyPriorityClassName].
+	AnubisPriorityClassName string `env:"ANUBIS_PRIORITY_CLASS_NAME"`
+
+	// PodDisruptionBudgetEnabled creates a PodDisruptionBudget for every
+	// anubis deployment, so that node drains don't evict its pods
+	// without coordination. See [IngressConfig.PDBMinAvailable].
+	PodDisruptionBudgetEnabled bool `env:"POD_DISRUPTION_BUDGET_ENABLED"`
+
+	// AnubisRuntimeClassName is the name of the runtime class of the
+	// created anubis instances, e.g., to sandbox them with gVisor.
+	AnubisRuntimeClassName string `env:"ANUBIS_RUNTIME_CLASS_NAME"`
+
+	// RolloutLimit is the maximum number of managed deployments that
+	// will have their pods rolled (e.g., because of a configuration
+	// change) per [RolloutLimitPeriod]. Rollouts over the limit are
+	// deferred until allowed. Creating new deployments is never limited.
+	// Set to 0 (the default) to disable.
+	RolloutLimit int `env:"ROLLOUT_LIMIT" envDefault:"0"`
+
+	// RolloutLimitPeriod is the period [RolloutLimit] applies to.
+	RolloutLimitPeriod time.Duration `env:"ROLLOUT_LIMIT_PERIOD" envDefault:"1m"`
+
+	// ImageRolloutBatchSize is the maximum number of managed deployments
+	// rolled to a new anubis image (e.g., when [AnubisVersion] changes)
+	// per [ImageRolloutBatchInterval], so that a bad release doesn't take
+	// out every protected site at once. Deployments over the limit keep
+	// their current image until the next batch. Set to 0 (the default)
+	// to roll them all right away.
+	ImageRolloutBatchSize int `env:"IMAGE_ROLLOUT_BATCH_SIZE" envDefault:"0"`
+
+	// ImageRolloutBatchInterval is the minimum time between two batches
+	// of [ImageRolloutBatchSize] deployments.
+	ImageRolloutBatchInterval time.Duration `env:"IMAGE_ROLLOUT_BATCH_INTERVAL" envDefault:"5m"`
+
+	// ImageRolloutHealthGate holds back the next batch of
+	// [ImageRolloutBatchSize] deployments until all of the deployments of
+	// the previous one are available.
+	ImageRolloutHealthGate bool `env:"IMAGE_ROLLOUT_HEALTH_GATE" envDefault:"true"`
+
+	// CircuitBreakerThreshold is the number of consecutive reconcile
+	// failures after which an ingress is considered to need attention.
+	// Those ingresses are then only retried every
+	// [CircuitBreakerRetryInterval] until they reconcile successfully
+	// again. Set to 0 to disable.
+	CircuitBreakerThreshold int `env:"CIRCUIT_BREAKER_THRESHOLD" envDefault:"10"`
+
+	// CircuitBreakerRetryInterval is how often an ingress is retried once
+	// [CircuitBreakerThreshold] has been reached.
+	CircuitBreakerRetryInterval time.Duration `env:"CIRCUIT_BREAKER_RETRY_INTERVAL" envDefault:"15m"`
+
+	// RemoteKubeconfigSecrets is a list of Secrets, in [Namespace], that
+	// each contain a kubeconfig (under the "kubeconfig" key) for a remote
+	// cluster. When set, ingresses are watched and resources are created
+	// in each remote cluster instead of the cluster the controller is
+	// running in. Leader election always happens in the local cluster.
+	// Example:
+	//
+	// REMOTE_KUBECONFIG_SECRETS="cluster-a,cluster-b"
+	RemoteKubeconfigSecrets []string `env:"REMOTE_KUBECONFIG_SECRETS"`
+
+	// WebhookEnabled enables the admission webhook server. Currently
+	// this serves a webhook that blocks deleting ConfigMaps and Secrets
+	// that are still referenced by a managed Anubis instance.
+	WebhookEnabled bool `env:"WEBHOOK_ENABLED" envDefault:"false"`
+
+	// WebhookPort is the port the admission webhook server listens on.
+	WebhookPort int `env:"WEBHOOK_PORT" envDefault:"9443"`
+
+	// WebhookCertDir is the directory containing the serving certificate
+	// (tls.crt) and key (tls.key) for the admission webhook server.
+	WebhookCertDir string `env:"WEBHOOK_CERT_DIR" envDefault:"/tmp/k8s-webhook-server/serving-certs"`
+
+	// HealthProbeBindAddress is the address the health probe endpoints
+	// (/healthz and /readyz) are served on.
+	HealthProbeBindAddress string `env:"HEALTH_PROBE_BIND_ADDRESS" envDefault:":8081"`
+
+	// MetricsBindAddress is the address the controller's metrics are
+	// served on, "0" disables serving them.
+	MetricsBindAddress string `env:"METRICS_BIND_ADDRESS" envDefault:":8080"`
+
+	// PprofBindAddress is the address pprof's debug endpoints
+	// (/debug/pprof/) are served on, disabled when unset. These expose
+	// the internals of the controller, so should never be reachable from
+	// outside of the pod (e.g., use "127.0.0.1:6060" and port-forward).
+	PprofBindAddress string `env:"PPROF_BIND_ADDRESS"`
+
+	// PreflightInterval is how often the environment checks gating
+	// readiness (e.g., that [WrappedIngressClassName] exists and that we
+	// have the permissions we need) are re-ran.
+	PreflightInterval time.Duration `env:"PREFLIGHT_INTERVAL" envDefault:"1m"`
+
+	// TLSSecretSyncInterval is how often the copies of the TLS secrets
+	// of ingresses, made in [Namespace] for their child ingress, are
+	// updated when their source changes (e.g., when a certificate is
+	// renewed). Copies are also updated whenever their ingress is
+	// reconciled. 0 disables it.
+	TLSSecretSyncInterval time.Duration `env:"TLS_SECRET_SYNC_INTERVAL" envDefault:"5m"`
+
+	// GarbageCollectionInterval is how often resources created for
+	// ingresses that no longer exist (e.g., because our finalizer was
+	// removed by hand) are deleted. This also happens on startup. 0
+	// disables it.
+	GarbageCollectionInterval time.Duration `env:"GC_INTERVAL" envDefault:"1h"`
+
+	// NotifyWebhookURL is a URL that notifications are POSTed to when an
+	// ingress enters or leaves a failed state (e.g., it is invalid or
+	// persistently failing to reconcile). Disabled when empty.
+	NotifyWebhookURL string `env:"NOTIFY_WEBHOOK_URL"`
+
+	// NotifyFormat is the payload format of notifications. Either
+	// "generic" (JSON) or "slack" (Slack-compatible incoming webhook).
+	NotifyFormat string `env:"NOTIFY_FORMAT" envDefault:"generic"`
+
+	// NotifyRateLimit is the maximum number of notifications sent per
+	// [NotifyRateLimitPeriod], notifications over the limit are dropped.
+	// Set to 0 to disable.
+	NotifyRateLimit int `env:"NOTIFY_RATE_LIMIT" envDefault:"10"`
+
+	// NotifyRateLimitPeriod is the period [NotifyRateLimit] applies to.
+	NotifyRateLimitPeriod time.Duration `env:"NOTIFY_RATE_LIMIT_PERIOD" envDefault:"1h"`
+
+	// AutoTune enables automatically tuning the difficulty of ingresses
+	// with difficulty bounds (see IngressConfig.DifficultyMin) based on
+	// the metrics of their Anubis instances. Anubis pods are scraped
+	// directly, so in remote clusters (see [RemoteKubeconfigSecrets])
+	// their IPs have to be reachable from the controller. When disabled,
+	// the bounds only limit the difficulty.
+	AutoTune bool `env:"AUTO_TUNE" envDefault:"false"`
+
+	// AutoTuneInterval is how often the Anubis instances are scraped when
+	// [AutoTune] is enabled.
+	AutoTuneInterval time.Duration `env:"AUTO_TUNE_INTERVAL" envDefault:"1m"`
+
+	// AutoTuneRaiseRate is the rate of challenges issued (per second) at
+	// or above which the difficulty is raised.
+	AutoTuneRaiseRate float64 `env:"AUTO_TUNE_RAISE_RATE" envDefault:"5"`
+
+	// AutoTuneLowerRate is the rate of challenges issued (per second) at
+	// or below which the difficulty is lowered. This should be well below
+	// [AutoTuneRaiseRate] to avoid flapping.
+	AutoTuneLowerRate float64 `env:"AUTO_TUNE_LOWER_RATE" envDefault:"0.5"`
+
+	// AutoTuneCooldown is the minimum amount of time between two
+	// difficulty changes of an ingress.
+	AutoTuneCooldown time.Duration `env:"AUTO_TUNE_COOLDOWN" envDefault:"10m"`
+
+	// VerifyRouting enables verifying, after reconciling an ingress, that
+	// requests to it (through its load balancer address) are actually
+	// served by Anubis. The controller must be able to reach the address.
+	VerifyRouting bool `env:"VERIFY_ROUTING" envDefault:"false"`
+
+	// VerifyRoutingTimeout is the timeout of a single verification
+	// request. See [VerifyRouting].
+	VerifyRoutingTimeout time.Duration `env:"VERIFY_ROUTING_TIMEOUT" envDefault:"10s"`
+
+	// VerifyRoutingInterval is how often an ingress that failed
+	// verification is verified again. See [VerifyRouting].
+	VerifyRoutingInterval time.Duration `env:"VERIFY_ROUTING_INTERVAL" envDefault:"5m"`
+
+	// GrafanaDashboard enables maintaining a ConfigMap, in [Namespace],
+	// containing a Grafana dashboard for all managed Anubis instances.
+	GrafanaDashboard bool `env:"GRAFANA_DASHBOARD" envDefault:"false"`
+
+	// GrafanaDashboardLabels are the labels set on the Grafana dashboard
+	// ConfigMap, used by the Grafana sidecar to discover it. See
+	// [Annotations] for the expected format.
+	GrafanaDashboardLabels map[string]string `env:"GRAFANA_DASHBOARD_LABELS" envDefault:"grafana_dashboard:1"`
+
+	// ConfigFile is the path of a YAML configuration file to load, see
+	// [LoadFromFile].
+	ConfigFile string `env:"CONFIG_FILE"`
+
+	// ConfigReloadInterval is how often [ConfigFile] and
+	// [RemoteKubeconfigSecrets] are checked for changes. When either
+	// changes, the controller is restarted with the new configuration. 0
+	// disables reloading.
+	ConfigReloadInterval time.Duration `env:"CONFIG_RELOAD_INTERVAL" envDefault:"30s"`
+
+	// IngressDefaults are the fleet-wide defaults of the per-ingress
+	// configuration, set through DEFAULT_ prefixed variables (e.g.,
+	// DEFAULT_DIFFICULTY).
+	IngressDefaults IngressDefaults `envPrefix:"DEFAULT_"`
+
+	// overrides are the values that took precedence over the
+	// environment when loading the configuration, see [Config.Reload].
+	overrides map[string]string
+}
+
+// IngressDefaults contains the defaults of [IngressConfig] values that
+// are used when the respective annotation isn't set.
+type IngressDefaults struct {
+	// Difficulty is the default of [IngressConfig.Difficulty].
+	Difficulty int `env:"DIFFICULTY" envDefault:"4"`
+
+	// ServeRobotsTxt is the default of [IngressConfig.ServeRobotsTxt].
+	ServeRobotsTxt bool `env:"SERVE_ROBOTS_TXT" envDefault:"true"`
+
+	// OGPassthrough is the default of [IngressConfig.OGPassthrough].
+	OGPassthrough bool `env:"OG_PASSTHROUGH" envDefault:"true"`
+
+	// MetricsPort is the default of [IngressConfig.MetricsPort].
+	MetricsPort uint32 `env:"METRICS_PORT" envDefault:"9090"`
+
+	// Port is the default of [IngressConfig.Port].
+	Port uint32 `env:"PORT" envDefault:"8080"`
+
+	// Mode is the default of [IngressConfig.Mode].
+	Mode Mode `env:"MODE" envDefault:"enforce"`
+
+	// TerminationGracePeriod is the default of
+	// [IngressConfig.TerminationGracePeriod].
+	TerminationGracePeriod time.Duration `env:"TERMINATION_GRACE_PERIOD" envDefault:"30s"`
+
+	// RevisionHistoryLimit is the default of
+	// [IngressConfig.RevisionHistoryLimit].
+	RevisionHistoryLimit int32 `env:"REVISION_HISTORY_LIMIT" envDefault:"10"`
+
+	// MinReadySeconds is the default of [IngressConfig.MinReadySeconds].
+	MinReadySeconds int32 `env:"MIN_READY_SECONDS" envDefault:"0"`
+}
+
+// Load returns a configuration object from the environment, with the
+// provided overrides (e.g., from [Flags]) taking precedence. When a
+// configuration file is set, it's loaded through [LoadFromFile].
+func Load(overrides map[string]string) (*Config, error) {
+	cfg, err := load(nil, overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.ConfigFile != "" {
+		return LoadFromFile(cfg.ConfigFile, overrides)
+	}
+	return cfg, nil
+}
+
+// LoadFromFile returns a configuration object from the YAML file at
+// the provided path, merged with the environment and the provided
+// overrides (which take precedence, in that order). The keys of the
+// file are the names of the environment variables. Lists of values and
+// maps of values are accepted where the environment variable expects a
+// comma-separated list or map, while nested objects (e.g., VOLUMES) are
+// encoded as JSON. Example:
+//
+//	RESOURCE_PREFIX: ia-
+//	ANNOTATIONS:
+//	  prometheus.io/scrape: "true"
+//	VOLUMES:
+//	  - name: policy
+//	    configMap:
+//	      name: anubis-policy
+func LoadFromFile(path string, overrides map[string]string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw map[string]any
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	file := make(map[string]string, len(raw)+1)
+	for k, v := range raw {
+		ev, err := toEnvValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert config file key %s: %w", k, err)
+		}
+		file[k] = ev
+	}
+	file["CONFIG_FILE"] = path
+
+	return load(file, overrides)
+}
+
+// Reload loads the configuration again, from the same sources as the
+// current configuration.
+func (c *Config) Reload() (*Config, error) {
+	return Load(c.overrides)
+}
+
+// toEnvValue converts a value from a config file into the format used
+// by its environment variable.
+func toEnvValue(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []any:
+		if !isScalars(slices.Values(v)) {
+			break
+		}
+
+		vals := make([]string, 0, len(v))
+		for _, e := range v {
+			vals = append(vals, scalarString(e))
+		}
+		return strings.Join(vals, ","), nil
+	case map[string]any:
+		if !isScalars(maps.Values(v)) {
+			break
+		}
+
+		vals := make([]string, 0, len(v))
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			vals = append(vals, k+":"+scalarString(v[k]))
+		}
+		return strings.Join(vals, ","), nil
+	default:
+		return scalarString(v), nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// scalarString returns the string representation of the provided
+// scalar value.
+func scalarString(v any) string {
+	// Numbers are always decoded as float64, format them without an
+	// exponent.
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// isScalars returns true if none of the provided values are lists or
+// objects.
+func isScalars(vals iter.Seq[any]) bool {
+	for v := range vals {
+		switch v.(type) {
+		case []any, map[string]any:
+			return false
+		}
+	}
+	return true
+}
+
+// WatchesNamespace returns true if ingresses in the provided namespace
+// can be managed, according to [Config.WatchNamespaces] and
+// [Config.IgnoreNamespaces].
+func (c *Config) WatchesNamespace(ns string) bool {
+	if len(c.WatchNamespaces) > 0 && !slices.Contains(c.WatchNamespaces, ns) {
+		return false
+	}
+	return !slices.Contains(c.IgnoreNamespaces, ns)
+}
+
+// InShard returns true if the ingress with the provided namespace and
+// name belongs to the shard of this instance, see [Config.ShardCount].
+func (c *Config) InShard(ns, name string) bool {
+	if c.ShardCount <= 1 {
+		return true
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(ns + "/" + name))
+	return int(h.Sum32())%c.ShardCount == c.ShardIndex
+}
+
+// GetIngressLabelSelector returns the parsed
+// [Config.IngressLabelSelector], which matches everything if unset.
+func (c *Config) GetIngressLabelSelector() (labels.Selector, error) {
+	sel, err := labels.Parse(c.IngressLabelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse INGRESS_LABEL_SELECTOR %q: %w", c.IngressLabelSelector, err)
+	}
+	return sel, nil
+}
+
+// ImageReference returns the reference of the provided image at the
+// provided version, which is either a tag, a digest (sha256:...) or
+// both (tag@sha256:...). Images that are already pinned to a digest are
+// returned as-is.
+func ImageReference(image, version string) string {
+	if strings.Contains(image, "@") {
+		return image
+	}
+
+	version = strings.TrimPrefix(version, "@")
+	if strings.HasPrefix(version, "sha256:") {
+		return image + "@" + version
+	}
+	return image + ":" + version
+}
+
+// GetIngressClassDefaults returns the parsed
+// [Config.IngressClassDefaults].
+func (c *Config) GetIngressClassDefaults() (map[string]map[string]string, error) {
+	defaults, err := parseJSON[map[string]map[string]string]("INGRESS_CLASS_DEFAULTS", c.IngressClassDefaults)
+	if defaults == nil {
+		return nil, err
+	}
+
+	for class, annotations := range *defaults {
+		for k := range annotations {
+			if !slices.Contains(AnnotationKeys[:], AnnotationKeyBase+AnnotationKey(k)) {
+				return nil, fmt.Errorf("invalid INGRESS_CLASS_DEFAULTS for class %q: unknown annotation %q", class, k)
+			}
+		}
+	}
+	return *defaults, nil
+}
+
+// GetAnubisResources returns the parsed [Config.AnubisResources], or
+// nil if unset.
+func (c *Config) GetAnubisResources() (*corev1.ResourceRequirements, error) {
+	return parseJSON[corev1.ResourceRequirements]("ANUBIS_RESOURCES", c.AnubisResources)
+}
+
+// GetAnubisTolerations returns the parsed [Config.AnubisTolerations].
+func (c *Config) GetAnubisTolerations() ([]corev1.Toleration, error) {
+	t, err := parseJSON[[]corev1.Toleration]("ANUBIS_TOLERATIONS", c.AnubisTolerations)
+	if t == nil {
+		return nil, err
+	}
+	return *t, err
+}
+
+// GetAnubisAffinity returns the parsed [Config.AnubisAffinity], or nil
+// if unset.
+func (c *Config) GetAnubisAffinity() (*corev1.Affinity, error) {
+	return parseJSON[corev1.Affinity]("ANUBIS_AFFINITY", c.AnubisAffinity)
+}
+
+// GetAnubisTopologySpreadConstraints returns the parsed
+// [Config.AnubisTopologySpreadConstraints].
+func (c *Config) GetAnubisTopologySpreadConstraints() ([]corev1.TopologySpreadConstraint, error) {
+	tsc, err := parseJSON[[]corev1.TopologySpreadConstraint]("ANUBIS_TOPOLOGY_SPREAD_CONSTRAINTS", c.AnubisTopologySpreadConstraints)
+	if tsc == nil {
+		return nil, err
+	}
+	return *tsc, err
+}
+
+// GetVolumes returns the parsed [Config.Volumes].
+func (c *Config) GetVolumes() ([]corev1.Volume, error) {
+	volumes, err := parseJSON[[]corev1.Volume]("VOLUMES", c.Volumes)
+	if volumes == nil {
+		return nil, err
+	}
+	return *volumes, err
+}
+
+// GetVolumeMounts returns the parsed [Config.VolumeMounts].
+func (c *Config) GetVolumeMounts() ([]corev1.VolumeMount, error) {
+	mounts, err := parseJSON[[]corev1.VolumeMount]("VOLUME_MOUNTS", c.VolumeMounts)
+	if mounts == nil {
+		return nil, err
+	}
+	return *mounts, err
+}
+
+// GetSidecars returns the parsed [Config.Sidecars].
+func (c *Config) GetSidecars() ([]corev1.Container, error) {
+	sidecars, err := parseJSON[[]corev1.Container]("SIDECARS", c.Sidecars)
+	if sidecars == nil {
+		return nil, err
+	}
+	return *sidecars, err
+}
+
+// parseJSON parses the JSON value v of the option named key, returning
+// nil if it is unset.
+func parseJSON[T any](key, v string) (*T, error) {
+	if v == "" {
+		return nil, nil
+	}
+
+	var t T
+	if err := json.Unmarshal([]byte(v), &t); err != nil {
+		return nil, fmt.Errorf("failed to parse %s %q as JSON: %w", key, v, err)
+	}
+	return &t, nil
 }
 
-// Load returns a configuration object from the environment.
-func Load() (*Config, error) {
-	var cfg Config
-	if err := env.Parse(&cfg); err != nil {
+var (
+	// imageRegexp matches image names, optionally pinned to a digest.
+	imageRegexp = regexp.MustCompile(`^[a-z0-9]+(?:[._-][a-z0-9]+)*(?::[0-9]+)?(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*` +
+		`(?:@sha256:[a-f0-9]{64})?$`)
+
+	// versionRegexp matches image tags and/or digests, see
+	// [ImageReference].
+	versionRegexp = regexp.MustCompile(`^(?:[\w][\w.-]{0,127}|@?sha256:[a-f0-9]{64}|[\w][\w.-]{0,127}@sha256:[a-f0-9]{64})$`)
+
+	// controllerNameRegexp matches domain-prefixed paths, the format of
+	// the spec.controller of ingress classes.
+	controllerNameRegexp = regexp.MustCompile(`^[a-z0-9]([a-z0-9.-]*[a-z0-9])?/[\w./-]+$`)
+
+	// resourcePrefixRegexp matches resource name prefixes that result in
+	// valid service names (DNS-1035 labels), leaving room for the name
+	// of the ingress within their 63 character limit.
+	resourcePrefixRegexp = regexp.MustCompile(`^[a-z][a-z0-9-]{0,29}$`)
+)
+
+// Validate returns an error describing every invalid configuration
+// value, e.g., JSON values that fail to parse or out of range ports, or
+// nil if the configuration is valid.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if !imageRegexp.MatchString(c.AnubisImage) {
+		errs = append(errs, fmt.Errorf("invalid ANUBIS_IMAGE %q, expected an image name (e.g., ghcr.io/techarohq/anubis)", c.AnubisImage))
+	}
+	if !versionRegexp.MatchString(c.AnubisVersion) {
+		errs = append(errs, fmt.Errorf("invalid ANUBIS_VERSION %q, expected a tag and/or sha256 digest", c.AnubisVersion))
+	}
+	if c.CanaryAnubisVersion != "" && !versionRegexp.MatchString(c.CanaryAnubisVersion) {
+		errs = append(errs, fmt.Errorf("invalid CANARY_ANUBIS_VERSION %q, expected a tag and/or sha256 digest", c.CanaryAnubisVersion))
+	}
+	if c.CanarySoakTime < 0 {
+		errs = append(errs, fmt.Errorf("invalid CANARY_SOAK_TIME %s, expected a non-negative duration", c.CanarySoakTime))
+	}
+
+	switch c.AnubisImagePullPolicy {
+	case "", corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever:
+	default:
+		errs = append(errs, fmt.Errorf("invalid ANUBIS_IMAGE_PULL_POLICY %q, expected one of %q, %q or %q",
+			c.AnubisImagePullPolicy, corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever))
+	}
+
+	switch c.ServiceIPFamilyPolicy {
+	case "", corev1.IPFamilyPolicySingleStack, corev1.IPFamilyPolicyPreferDualStack, corev1.IPFamilyPolicyRequireDualStack:
+	default:
+		errs = append(errs, fmt.Errorf("invalid SERVICE_IP_FAMILY_POLICY %q, expected one of %q, %q or %q",
+			c.ServiceIPFamilyPolicy, corev1.IPFamilyPolicySingleStack, corev1.IPFamilyPolicyPreferDualStack,
+			corev1.IPFamilyPolicyRequireDualStack))
+	}
+	for i, f := range c.ServiceIPFamilies {
+		if (f != corev1.IPv4Protocol && f != corev1.IPv6Protocol) || slices.Contains(c.ServiceIPFamilies[:i], f) {
+			errs = append(errs, fmt.Errorf("invalid SERVICE_IP_FAMILIES %q, expected %q and/or %q, at most once each",
+				c.ServiceIPFamilies, corev1.IPv4Protocol, corev1.IPv6Protocol))
+			break
+		}
+	}
+	// The API server defaults services to a single stack.
+	if len(c.ServiceIPFamilies) > 1 && c.ServiceIPFamilyPolicy != corev1.IPFamilyPolicyPreferDualStack &&
+		c.ServiceIPFamilyPolicy != corev1.IPFamilyPolicyRequireDualStack {
+		errs = append(errs, fmt.Errorf("SERVICE_IP_FAMILIES %q require a SERVICE_IP_FAMILY_POLICY of %q or %q",
+			c.ServiceIPFamilies, corev1.IPFamilyPolicyPreferDualStack, corev1.IPFamilyPolicyRequireDualStack))
+	}
+
+	if !resourcePrefixRegexp.MatchString(c.ResourcePrefix) {
+		errs = append(errs, fmt.Errorf("invalid RESOURCE_PREFIX %q, expected at most 30 lowercase alphanumeric characters "+
+			"or '-', starting with a letter", c.ResourcePrefix))
+	}
+
+	if !controllerNameRegexp.MatchString(c.ControllerName) {
+		errs = append(errs, fmt.Errorf("invalid CONTROLLER_NAME %q, expected a domain-prefixed path (e.g., example.com/ingress-anubis)",
+			c.ControllerName))
+	}
+
+	if _, err := translate.Get(translate.Dialect(c.WrappedIngressDialect)); err != nil {
+		errs = append(errs, fmt.Errorf("invalid WRAPPED_INGRESS_DIALECT: %w", err))
+	}
+
+	if c.WebhookPort < 1 || c.WebhookPort > 65535 {
+		errs = append(errs, fmt.Errorf("invalid WEBHOOK_PORT %d, expected a port between 1 and 65535", c.WebhookPort))
+	}
+	if c.HealthProbeBindAddress != "0" {
+		if _, _, err := net.SplitHostPort(c.HealthProbeBindAddress); err != nil {
+			errs = append(errs, fmt.Errorf("invalid HEALTH_PROBE_BIND_ADDRESS %q: %w", c.HealthProbeBindAddress, err))
+		}
+	}
+	if c.PprofBindAddress != "" && c.PprofBindAddress != "0" {
+		if _, _, err := net.SplitHostPort(c.PprofBindAddress); err != nil {
+			errs = append(errs, fmt.Errorf("invalid PPROF_BIND_ADDRESS %q: %w", c.PprofBindAddress, err))
+		}
+	}
+	if c.MetricsBindAddress != "0" {
+		if _, _, err := net.SplitHostPort(c.MetricsBindAddress); err != nil {
+			errs = append(errs, fmt.Errorf("invalid METRICS_BIND_ADDRESS %q: %w", c.MetricsBindAddress, err))
+		}
+	}
+
+	if c.LeaderElection {
+		if c.LeaderElectionID == "" {
+			errs = append(errs, errors.New("LEADER_ELECTION_ID must be set when LEADER_ELECTION is enabled"))
+		}
+		if c.LeaderElectionRetryPeriod <= 0 || c.LeaderElectionRenewDeadline <= c.LeaderElectionRetryPeriod ||
+			c.LeaderElectionLeaseDuration <= c.LeaderElectionRenewDeadline {
+			errs = append(errs, fmt.Errorf("invalid LEADER_ELECTION_LEASE_DURATION %s, LEADER_ELECTION_RENEW_DEADLINE %s and "+
+				"LEADER_ELECTION_RETRY_PERIOD %s, expected positive durations in decreasing order",
+				c.LeaderElectionLeaseDuration, c.LeaderElectionRenewDeadline, c.LeaderElectionRetryPeriod))
+		}
+	}
+
+	if c.ShardCount < 1 || c.ShardIndex < 0 || c.ShardIndex >= c.ShardCount {
+		errs = append(errs, fmt.Errorf("invalid SHARD_COUNT %d and SHARD_INDEX %d, expected an index lower than the count",
+			c.ShardCount, c.ShardIndex))
+	}
+
+	if c.MaxConcurrentReconciles < 1 {
+		errs = append(errs, fmt.Errorf("invalid MAX_CONCURRENT_RECONCILES %d, expected at least 1", c.MaxConcurrentReconciles))
+	}
+	if c.SyncPeriod <= 0 {
+		errs = append(errs, fmt.Errorf("invalid SYNC_PERIOD %s, expected a positive duration", c.SyncPeriod))
+	}
+	if c.DegradedRetryInterval <= 0 || c.DegradedRetryTimeout < 0 {
+		errs = append(errs, fmt.Errorf("invalid DEGRADED_RETRY_INTERVAL %s and DEGRADED_RETRY_TIMEOUT %s, "+
+			"expected a positive interval and a non-negative timeout", c.DegradedRetryInterval, c.DegradedRetryTimeout))
+	}
+	if c.ImageRolloutBatchSize < 0 || (c.ImageRolloutBatchSize > 0 && c.ImageRolloutBatchInterval <= 0) {
+		errs = append(errs, fmt.Errorf("invalid IMAGE_ROLLOUT_BATCH_SIZE %d and IMAGE_ROLLOUT_BATCH_INTERVAL %s, "+
+			"expected a non-negative size and a positive interval", c.ImageRolloutBatchSize, c.ImageRolloutBatchInterval))
+	}
+	if c.ReconcileRetryBaseDelay <= 0 || c.ReconcileRetryMaxDelay < c.ReconcileRetryBaseDelay {
+		errs = append(errs, fmt.Errorf("invalid RECONCILE_RETRY_BASE_DELAY %s and RECONCILE_RETRY_MAX_DELAY %s, "+
+			"expected a positive base delay lower than the max delay", c.ReconcileRetryBaseDelay, c.ReconcileRetryMaxDelay))
+	}
+	if c.ReconcileQPS <= 0 || c.ReconcileBurst < 1 {
+		errs = append(errs, fmt.Errorf("invalid RECONCILE_QPS %v and RECONCILE_BURST %d, expected positive values",
+			c.ReconcileQPS, c.ReconcileBurst))
+	}
+	if c.PreflightInterval <= 0 {
+		errs = append(errs, fmt.Errorf("invalid PREFLIGHT_INTERVAL %s, expected a positive duration", c.PreflightInterval))
+	}
+	if c.AutoTune && (c.AutoTuneInterval <= 0 || c.AutoTuneCooldown < 0) {
+		errs = append(errs, fmt.Errorf("invalid AUTO_TUNE_INTERVAL %s and AUTO_TUNE_COOLDOWN %s, "+
+			"expected a positive interval and a non-negative cooldown", c.AutoTuneInterval, c.AutoTuneCooldown))
+	}
+	if c.CircuitBreakerThreshold > 0 && c.CircuitBreakerRetryInterval <= 0 {
+		errs = append(errs, fmt.Errorf("invalid CIRCUIT_BREAKER_RETRY_INTERVAL %s, expected a positive duration",
+			c.CircuitBreakerRetryInterval))
+	}
+	if c.RolloutLimit > 0 && c.RolloutLimitPeriod <= 0 {
+		errs = append(errs, fmt.Errorf("invalid ROLLOUT_LIMIT_PERIOD %s, expected a positive duration", c.RolloutLimitPeriod))
+	}
+	if c.NotifyRateLimit > 0 && c.NotifyRateLimitPeriod <= 0 {
+		errs = append(errs, fmt.Errorf("invalid NOTIFY_RATE_LIMIT_PERIOD %s, expected a positive duration", c.NotifyRateLimitPeriod))
+	}
+	if c.VerifyRouting && (c.VerifyRoutingTimeout <= 0 || c.VerifyRoutingInterval <= 0) {
+		errs = append(errs, fmt.Errorf("invalid VERIFY_ROUTING_TIMEOUT %s and VERIFY_ROUTING_INTERVAL %s, expected positive durations",
+			c.VerifyRoutingTimeout, c.VerifyRoutingInterval))
+	}
+	// 0 disables these.
+	for _, d := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"CONFIG_RELOAD_INTERVAL", c.ConfigReloadInterval},
+		{"TLS_SECRET_SYNC_INTERVAL", c.TLSSecretSyncInterval},
+		{"GC_INTERVAL", c.GarbageCollectionInterval},
+	} {
+		if d.value < 0 {
+			errs = append(errs, fmt.Errorf("invalid %s %s, expected a non-negative duration", d.name, d.value))
+		}
+	}
+
+	d := c.IngressDefaults
+	if d.Mode != ModeEnforce && d.Mode != ModeShadow {
+		errs = append(errs, fmt.Errorf("invalid DEFAULT_MODE %q, expected one of %q or %q", d.Mode, ModeEnforce, ModeShadow))
+	}
+	if d.MetricsPort < 1 || d.MetricsPort > 65535 {
+		errs = append(errs, fmt.Errorf("invalid DEFAULT_METRICS_PORT %d, expected a port between 1 and 65535", d.MetricsPort))
+	}
+	if d.Port < 1 || d.Port > 65535 || d.Port == d.MetricsPort {
+		errs = append(errs, fmt.Errorf("invalid DEFAULT_PORT %d, expected a port between 1 and 65535 other than DEFAULT_METRICS_PORT",
+			d.Port))
+	}
+	if d.TerminationGracePeriod < 0 || d.RevisionHistoryLimit < 0 || d.MinReadySeconds < 0 {
+		errs = append(errs, errors.New("DEFAULT_TERMINATION_GRACE_PERIOD, DEFAULT_REVISION_HISTORY_LIMIT and "+
+			"DEFAULT_MIN_READY_SECONDS must not be negative"))
+	}
+
+	for _, fn := range []func() error{
+		func() error { _, err := c.GetIngressLabelSelector(); return err },
+		func() error { _, err := c.GetIngressClassDefaults(); return err },
+		func() error { _, err := c.GetVolumes(); return err },
+		func() error { _, err := c.GetVolumeMounts(); return err },
+		func() error { _, err := c.GetSidecars(); return err },
+		func() error { _, err := c.GetAnubisResources(); return err },
+		func() error { _, err := c.GetAnubisTolerations(); return err },
+		func() error { _, err := c.GetAnubisAffinity(); return err },
+		func() error { _, err := c.GetAnubisTopologySpreadConstraints(); return err },
+	} {
+		if err := fn(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// load returns a configuration object from the provided file values,
+// the environment and the provided overrides, in increasing order of
+// precedence.
+func load(file, overrides map[string]string) (*Config, error) {
+	environ := maps.Clone(file)
+	if environ == nil {
+		environ = make(map[string]string)
+	}
+	maps.Copy(environ, env.ToMap(os.Environ()))
+	maps.Copy(environ, overrides)
+
+	cfg := Config{overrides: overrides}
+	if err := env.ParseWithOptions(&cfg, env.Options{Environment: environ}); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,401 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestLoadFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(`
+RESOURCE_PREFIX: file-
+ROLLOUT_LIMIT: 1000000
+ROLLOUT_LIMIT_PERIOD: 1h
+INGRESS_CLASS_NAME: [anubis, anubis-strict]
+ANNOTATIONS:
+  prometheus.io/scrape: true
+VOLUMES:
+  - name: policy
+    configMap:
+      name: anubis-policy
+`), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	// The environment takes precedence over the file, and overrides over
+	// both.
+	t.Setenv("ROLLOUT_LIMIT_PERIOD", "2h")
+	t.Setenv("RESOURCE_PREFIX", "env-")
+
+	cfg, err := LoadFromFile(path, map[string]string{"RESOURCE_PREFIX": "flag-"})
+	if err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+
+	if cfg.ResourcePrefix != "flag-" {
+		t.Errorf("ResourcePrefix = %q, want %q", cfg.ResourcePrefix, "flag-")
+	}
+	if cfg.RolloutLimit != 1000000 {
+		t.Errorf("RolloutLimit = %d, want %d", cfg.RolloutLimit, 1000000)
+	}
+	if cfg.RolloutLimitPeriod != 2*time.Hour {
+		t.Errorf("RolloutLimitPeriod = %s, want %s", cfg.RolloutLimitPeriod, 2*time.Hour)
+	}
+	if diff := cmp.Diff([]string{"anubis", "anubis-strict"}, cfg.IngressClassNames); diff != "" {
+		t.Errorf("IngressClassNames mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"prometheus.io/scrape": "true"}, cfg.Annotations); diff != "" {
+		t.Errorf("Annotations mismatch (-want +got):\n%s", diff)
+	}
+	if want := `[{"configMap":{"name":"anubis-policy"},"name":"policy"}]`; cfg.Volumes != want {
+		t.Errorf("Volumes = %s, want %s", cfg.Volumes, want)
+	}
+
+	// Unset values still use their defaults.
+	if cfg.Namespace != "ingress-anubis" {
+		t.Errorf("Namespace = %q, want default %q", cfg.Namespace, "ingress-anubis")
+	}
+	if diff := cmp.Diff([]string{"kubectl.kubernetes.io/last-applied-configuration", "argocd.argoproj.io/", "cert-manager.io/"},
+		cfg.StripAnnotationPrefixes); diff != "" {
+		t.Errorf("StripAnnotationPrefixes mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoadAnubisResources(t *testing.T) {
+	cfg, err := Load(map[string]string{"ANUBIS_RESOURCES": `{"limits":{"memory":"128Mi"}}`})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	r, err := cfg.GetAnubisResources()
+	if err != nil {
+		t.Fatalf("GetAnubisResources() error = %v", err)
+	}
+	if got := r.Limits.Memory().String(); got != "128Mi" {
+		t.Errorf("memory limit = %s, want %s", got, "128Mi")
+	}
+
+	if _, err := Load(map[string]string{"ANUBIS_RESOURCES": "128Mi"}); err == nil {
+		t.Error("Load() expected error for invalid ANUBIS_RESOURCES")
+	}
+}
+
+func TestLoadAnubisScheduling(t *testing.T) {
+	cfg, err := Load(map[string]string{
+		"ANUBIS_NODE_SELECTOR": "pool:edge",
+		"ANUBIS_TOLERATIONS":   `[{"key":"pool","operator":"Equal","value":"edge","effect":"NoSchedule"}]`,
+	})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if diff := cmp.Diff(map[string]string{"pool": "edge"}, cfg.AnubisNodeSelector); diff != "" {
+		t.Errorf("AnubisNodeSelector mismatch (-want +got):\n%s", diff)
+	}
+
+	tolerations, err := cfg.GetAnubisTolerations()
+	if err != nil {
+		t.Fatalf("GetAnubisTolerations() error = %v", err)
+	}
+	if diff := cmp.Diff([]corev1.Toleration{{
+		Key: "pool", Operator: corev1.TolerationOpEqual, Value: "edge", Effect: corev1.TaintEffectNoSchedule,
+	}}, tolerations); diff != "" {
+		t.Errorf("tolerations mismatch (-want +got):\n%s", diff)
+	}
+
+	affinity, err := cfg.GetAnubisAffinity()
+	if err != nil {
+		t.Fatalf("GetAnubisAffinity() error = %v", err)
+	}
+	if affinity != nil {
+		t.Errorf("GetAnubisAffinity() = %v, want nil", affinity)
+	}
+
+	if _, err := Load(map[string]string{"ANUBIS_AFFINITY": "[]"}); err == nil {
+		t.Error("Load() expected error for invalid ANUBIS_AFFINITY")
+	}
+}
+
+func TestLoadSidecars(t *testing.T) {
+	cfg, err := Load(map[string]string{"SIDECARS": `[{"name":"logger","image":"fluent/fluent-bit"}]`})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	sidecars, err := cfg.GetSidecars()
+	if err != nil {
+		t.Fatalf("GetSidecars() error = %v", err)
+	}
+	if diff := cmp.Diff([]corev1.Container{{Name: "logger", Image: "fluent/fluent-bit"}}, sidecars); diff != "" {
+		t.Errorf("sidecars mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := Load(map[string]string{"SIDECARS": `{"name":"logger"}`}); err == nil {
+		t.Error("Load() expected error for invalid SIDECARS")
+	}
+}
+
+func TestLoadServiceIPFamilies(t *testing.T) {
+	cfg, err := Load(map[string]string{"SERVICE_IP_FAMILY_POLICY": "PreferDualStack", "SERVICE_IP_FAMILIES": "IPv6,IPv4"})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.ServiceIPFamilyPolicy != corev1.IPFamilyPolicyPreferDualStack {
+		t.Errorf("ServiceIPFamilyPolicy = %q, want %q", cfg.ServiceIPFamilyPolicy, corev1.IPFamilyPolicyPreferDualStack)
+	}
+	if diff := cmp.Diff([]corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol}, cfg.ServiceIPFamilies); diff != "" {
+		t.Errorf("ServiceIPFamilies mismatch (-want +got):\n%s", diff)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	for _, policy := range []corev1.IPFamilyPolicy{"", corev1.IPFamilyPolicySingleStack} {
+		cfg.ServiceIPFamilyPolicy = policy
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Validate() expected error for multiple SERVICE_IP_FAMILIES with policy %q", policy)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected default configuration to be valid, got %v", err)
+	}
+
+	cfg.Volumes = `{"name":"policy"}`
+	cfg.AnubisVersion = "v1.26.0:latest"
+	cfg.CanaryAnubisVersion = "v1.27.0:latest"
+	cfg.WebhookPort = 0
+	cfg.MaxConcurrentReconciles = 0
+	cfg.MetricsBindAddress = "8080"
+	cfg.ControllerName = "ingress-anubis"
+	cfg.DegradedRetryInterval = 0
+	cfg.ImageRolloutBatchSize = -1
+	cfg.IngressDefaults.Port = cfg.IngressDefaults.MetricsPort
+	cfg.ServiceIPFamilyPolicy = "DualStack"
+	cfg.ResourcePrefix = ""
+	cfg.ServiceIPFamilies = []corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv6Protocol}
+	cfg.LeaderElectionRenewDeadline = cfg.LeaderElectionLeaseDuration
+	err = cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate() expected error")
+	}
+	for _, key := range []string{"VOLUMES", "ANUBIS_VERSION", "WEBHOOK_PORT", "MAX_CONCURRENT_RECONCILES", "LEADER_ELECTION_RENEW_DEADLINE",
+		"CANARY_ANUBIS_VERSION", "METRICS_BIND_ADDRESS", "CONTROLLER_NAME", "DEGRADED_RETRY_INTERVAL", "IMAGE_ROLLOUT_BATCH_SIZE",
+		"DEFAULT_PORT", "SERVICE_IP_FAMILY_POLICY", "SERVICE_IP_FAMILIES",
+		"RESOURCE_PREFIX"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("expected error to report %s, got %v", key, err)
+		}
+	}
+}
+
+func TestValidateDurations(t *testing.T) {
+	tests := []struct {
+		key    string
+		modify func(c *Config)
+	}{
+		{"PREFLIGHT_INTERVAL", func(c *Config) { c.PreflightInterval = 0 }},
+		{"AUTO_TUNE_INTERVAL", func(c *Config) { c.AutoTune, c.AutoTuneInterval = true, 0 }},
+		{"AUTO_TUNE_COOLDOWN", func(c *Config) { c.AutoTune, c.AutoTuneCooldown = true, -time.Minute }},
+		{"CIRCUIT_BREAKER_RETRY_INTERVAL", func(c *Config) { c.CircuitBreakerRetryInterval = 0 }},
+		{"ROLLOUT_LIMIT_PERIOD", func(c *Config) { c.RolloutLimit, c.RolloutLimitPeriod = 1, -time.Minute }},
+		{"NOTIFY_RATE_LIMIT_PERIOD", func(c *Config) { c.NotifyRateLimitPeriod = 0 }},
+		{"VERIFY_ROUTING_TIMEOUT", func(c *Config) { c.VerifyRouting, c.VerifyRoutingTimeout = true, 0 }},
+		{"VERIFY_ROUTING_INTERVAL", func(c *Config) { c.VerifyRouting, c.VerifyRoutingInterval = true, -time.Minute }},
+		{"CONFIG_RELOAD_INTERVAL", func(c *Config) { c.ConfigReloadInterval = -time.Second }},
+		{"TLS_SECRET_SYNC_INTERVAL", func(c *Config) { c.TLSSecretSyncInterval = -time.Second }},
+		{"GC_INTERVAL", func(c *Config) { c.GarbageCollectionInterval = -time.Second }},
+		{"CANARY_SOAK_TIME", func(c *Config) { c.CanarySoakTime = -time.Minute }},
+		{"IMAGE_ROLLOUT_BATCH_INTERVAL", func(c *Config) { c.ImageRolloutBatchSize, c.ImageRolloutBatchInterval = 1, 0 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			cfg, err := Load(nil)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			tt.modify(cfg)
+			err = cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.key) {
+				t.Errorf("expected error to report %s, got %v", tt.key, err)
+			}
+		})
+	}
+
+	// Durations only used by disabled features, or where 0 disables
+	// them, aren't validated.
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	cfg.AutoTuneInterval, cfg.RolloutLimitPeriod, cfg.VerifyRoutingTimeout = 0, 0, 0
+	cfg.CircuitBreakerThreshold, cfg.CircuitBreakerRetryInterval = 0, 0
+	cfg.ConfigReloadInterval, cfg.TLSSecretSyncInterval, cfg.GarbageCollectionInterval = 0, 0, 0
+	cfg.ImageRolloutBatchInterval = 0
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestValidateResourcePrefix(t *testing.T) {
+	tests := []struct {
+		prefix  string
+		wantErr bool
+	}{
+		{prefix: "ia-"},
+		{prefix: "anubis"},
+		{prefix: "abcdefghijklmnopqrstuvwxyz-ab-"},
+		{prefix: "", wantErr: true},
+		{prefix: "-ia", wantErr: true},
+		{prefix: "1a-", wantErr: true},
+		{prefix: "IA-", wantErr: true},
+		{prefix: "ia.", wantErr: true},
+		{prefix: "abcdefghijklmnopqrstuvwxyz-abc-", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			cfg, err := Load(nil)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			cfg.ResourcePrefix = tt.prefix
+			err = cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "RESOURCE_PREFIX") {
+				t.Errorf("expected error to report RESOURCE_PREFIX, got %v", err)
+			}
+		})
+	}
+}
+
+func TestWatchesNamespace(t *testing.T) {
+	cfg := &Config{}
+	if !cfg.WatchesNamespace("default") {
+		t.Error("expected all namespaces to be watched by default")
+	}
+
+	cfg = &Config{WatchNamespaces: []string{"web", "blog"}, IgnoreNamespaces: []string{"blog"}}
+	for ns, want := range map[string]bool{"web": true, "blog": false, "default": false} {
+		if got := cfg.WatchesNamespace(ns); got != want {
+			t.Errorf("WatchesNamespace(%q) = %v, want %v", ns, got, want)
+		}
+	}
+}
+
+func TestInShard(t *testing.T) {
+	if !(&Config{}).InShard("default", "web") {
+		t.Error("expected all ingresses to be in the shard when sharding is disabled")
+	}
+
+	for _, name := range []string{"web", "blog", "api", "docs"} {
+		shards := 0
+		for i := range 3 {
+			if (&Config{ShardCount: 3, ShardIndex: i}).InShard("default", name) {
+				shards++
+			}
+		}
+		if shards != 1 {
+			t.Errorf("expected ingress %q to be in exactly one shard, got %d", name, shards)
+		}
+	}
+
+	if _, err := Load(map[string]string{"SHARD_COUNT": "2", "SHARD_INDEX": "2"}); err == nil {
+		t.Error("Load() expected error for out of range SHARD_INDEX")
+	}
+}
+
+func TestGetIngressLabelSelector(t *testing.T) {
+	sel, err := (&Config{}).GetIngressLabelSelector()
+	if err != nil {
+		t.Fatalf("GetIngressLabelSelector() error = %v", err)
+	}
+	if !sel.Empty() {
+		t.Errorf("expected an unset selector to match everything, got %q", sel)
+	}
+
+	sel, err = (&Config{IngressLabelSelector: "anubis=true"}).GetIngressLabelSelector()
+	if err != nil {
+		t.Fatalf("GetIngressLabelSelector() error = %v", err)
+	}
+	if sel.Matches(labels.Set{"anubis": "false"}) || !sel.Matches(labels.Set{"anubis": "true"}) {
+		t.Errorf("selector %q matched unexpectedly", sel)
+	}
+
+	if _, err := Load(map[string]string{"INGRESS_LABEL_SELECTOR": "anubis in ("}); err == nil {
+		t.Error("Load() expected error for invalid INGRESS_LABEL_SELECTOR")
+	}
+}
+
+func TestImageReference(t *testing.T) {
+	const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		name    string
+		image   string
+		version string
+		want    string
+	}{
+		{"tag", "ghcr.io/techarohq/anubis", "v1.26.0", "ghcr.io/techarohq/anubis:v1.26.0"},
+		{"digest", "ghcr.io/techarohq/anubis", digest, "ghcr.io/techarohq/anubis@" + digest},
+		{"digest with @", "ghcr.io/techarohq/anubis", "@" + digest, "ghcr.io/techarohq/anubis@" + digest},
+		{"tag and digest", "ghcr.io/techarohq/anubis", "v1.26.0@" + digest, "ghcr.io/techarohq/anubis:v1.26.0@" + digest},
+		{"pinned image", "registry.internal/anubis@" + digest, "v1.26.0", "registry.internal/anubis@" + digest},
+		{"registry with port", "registry.internal:5000/anubis", "v1.26.0", "registry.internal:5000/anubis:v1.26.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ImageReference(tt.image, tt.version); got != tt.want {
+				t.Errorf("ImageReference() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags, err := NewFlags(fs)
+	if err != nil {
+		t.Fatalf("NewFlags() error = %v", err)
+	}
+
+	if err := fs.Parse([]string{"--resource-prefix=flag-", "--context", "dev", "--default-difficulty=6"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := map[string]string{"RESOURCE_PREFIX": "flag-", "KUBE_CONTEXT": "dev", "DEFAULT_DIFFICULTY": "6"}
+	if diff := cmp.Diff(want, flags.Environment()); diff != "" {
+		t.Errorf("Environment() mismatch (-want +got):\n%s", diff)
+	}
+}
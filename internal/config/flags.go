@@ -0,0 +1,77 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package config
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/caarlos0/env/v11"
+)
+
+// flagAliases are additional flag names for environment variables.
+var flagAliases = map[string][]string{
+	"KUBE_CONTEXT": {"context"},
+}
+
+// Flags are command-line flags mirroring every environment variable of
+// [Config] (e.g., --resource-prefix for RESOURCE_PREFIX), taking
+// precedence over them.
+type Flags struct {
+	env map[string]string
+}
+
+// NewFlags registers a flag for every environment variable of [Config]
+// on the provided flag set.
+func NewFlags(fs *flag.FlagSet) (*Flags, error) {
+	params, err := env.GetFieldParams(&Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config fields: %w", err)
+	}
+
+	f := &Flags{env: make(map[string]string)}
+	for _, p := range params {
+		if p.Key == "" {
+			continue
+		}
+
+		usage := "overrides $" + p.Key
+		if p.HasDefaultValue && p.DefaultValue != "" {
+			usage += fmt.Sprintf(" (default %q)", p.DefaultValue)
+		}
+
+		names := append([]string{strings.ToLower(strings.ReplaceAll(p.Key, "_", "-"))}, flagAliases[p.Key]...)
+		for _, name := range names {
+			fs.Func(name, usage, func(v string) error {
+				f.env[p.Key] = v
+				return nil
+			})
+		}
+	}
+
+	return f, nil
+}
+
+// Environment returns the environment variables set through flags.
+func (f *Flags) Environment() map[string]string {
+	if f == nil {
+		return nil
+	}
+	return f.env
+}
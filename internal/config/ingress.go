@@ -18,10 +18,20 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
+	"maps"
+	"net/mail"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
+	"golang.org/x/net/publicsuffix"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
 )
 
@@ -41,6 +51,152 @@ const (
 	// AnnotationKeyDifficulty is used by [IngressConfig.Difficulty].
 	AnnotationKeyDifficulty AnnotationKey = AnnotationKeyBase + "difficulty"
 
+	// AnnotationKeyDifficultyMin is used by
+	// [IngressConfig.DifficultyMin].
+	AnnotationKeyDifficultyMin AnnotationKey = AnnotationKeyBase + "difficulty-min"
+
+	// AnnotationKeyDifficultyMax is used by
+	// [IngressConfig.DifficultyMax].
+	AnnotationKeyDifficultyMax AnnotationKey = AnnotationKeyBase + "difficulty-max"
+
+	// AnnotationKeyAnubisVersion is used by
+	// [IngressConfig.AnubisVersion].
+	AnnotationKeyAnubisVersion AnnotationKey = AnnotationKeyBase + "anubis-version"
+
+	// AnnotationKeyAnubisImage is used by [IngressConfig.AnubisImage].
+	AnnotationKeyAnubisImage AnnotationKey = AnnotationKeyBase + "anubis-image"
+
+	// AnnotationKeyResources is used by [IngressConfig.Resources].
+	AnnotationKeyResources AnnotationKey = AnnotationKeyBase + "resources"
+
+	// AnnotationKeyProbes is used by [IngressConfig.Probes].
+	AnnotationKeyProbes AnnotationKey = AnnotationKeyBase + "probes"
+
+	// AnnotationKeyChildAnnotations is used by
+	// [IngressConfig.ChildAnnotations].
+	AnnotationKeyChildAnnotations AnnotationKey = AnnotationKeyBase + "child-annotations"
+
+	// AnnotationKeyStripAnnotations is used by
+	// [IngressConfig.StripAnnotations].
+	AnnotationKeyStripAnnotations AnnotationKey = AnnotationKeyBase + "strip-annotations"
+
+	// AnnotationKeyTargetScheme is used by [IngressConfig.TargetScheme].
+	AnnotationKeyTargetScheme AnnotationKey = AnnotationKeyBase + "target-scheme"
+
+	// AnnotationKeyTargetInsecureSkipVerify is used by
+	// [IngressConfig.TargetInsecureSkipVerify].
+	AnnotationKeyTargetInsecureSkipVerify AnnotationKey = AnnotationKeyBase + "target-insecure-skip-verify"
+
+	// AnnotationKeyTargetHost is used by [IngressConfig.TargetHost].
+	AnnotationKeyTargetHost AnnotationKey = AnnotationKeyBase + "target-host"
+
+	// AnnotationKeyBasePrefix is used by [IngressConfig.BasePrefix].
+	AnnotationKeyBasePrefix AnnotationKey = AnnotationKeyBase + "base-prefix"
+
+	// AnnotationKeyRedirectDomains is used by
+	// [IngressConfig.RedirectDomains].
+	AnnotationKeyRedirectDomains AnnotationKey = AnnotationKeyBase + "redirect-domains"
+
+	// AnnotationKeyNodeSelector is used by [IngressConfig.NodeSelector].
+	AnnotationKeyNodeSelector AnnotationKey = AnnotationKeyBase + "node-selector"
+
+	// AnnotationKeyTolerations is used by [IngressConfig.Tolerations].
+	AnnotationKeyTolerations AnnotationKey = AnnotationKeyBase + "tolerations"
+
+	// AnnotationKeyAffinity is used by [IngressConfig.Affinity].
+	AnnotationKeyAffinity AnnotationKey = AnnotationKeyBase + "affinity"
+
+	// AnnotationKeyTopologySpreadConstraints is used by
+	// [IngressConfig.TopologySpreadConstraints].
+	AnnotationKeyTopologySpreadConstraints AnnotationKey = AnnotationKeyBase + "topology-spread-constraints"
+
+	// AnnotationKeyPriorityClassName is used by
+	// [IngressConfig.PriorityClassName].
+	AnnotationKeyPriorityClassName AnnotationKey = AnnotationKeyBase + "priority-class-name"
+
+	// AnnotationKeyChallengeMethod is used by
+	// [IngressConfig.ChallengeMethod].
+	AnnotationKeyChallengeMethod AnnotationKey = AnnotationKeyBase + "challenge-method"
+
+	// AnnotationKeySigningKeySecret is used by
+	// [IngressConfig.SigningKeySecret].
+	AnnotationKeySigningKeySecret AnnotationKey = AnnotationKeyBase + "signing-key-secret"
+
+	// AnnotationKeyOGExpiryTime is used by [IngressConfig.OGExpiryTime].
+	AnnotationKeyOGExpiryTime AnnotationKey = AnnotationKeyBase + "og-expiry-time"
+
+	// AnnotationKeyCookieSameSite is used by [IngressConfig.CookieSameSite].
+	AnnotationKeyCookieSameSite AnnotationKey = AnnotationKeyBase + "cookie-same-site"
+
+	// AnnotationKeyCookiePartitioned is used by
+	// [IngressConfig.CookiePartitioned].
+	AnnotationKeyCookiePartitioned AnnotationKey = AnnotationKeyBase + "cookie-partitioned"
+
+	// AnnotationKeyCookiePrefix is used by [IngressConfig.CookiePrefix].
+	AnnotationKeyCookiePrefix AnnotationKey = AnnotationKeyBase + "cookie-prefix"
+
+	// AnnotationKeyMetricsEnabled is used by
+	// [IngressConfig.MetricsEnabled].
+	AnnotationKeyMetricsEnabled AnnotationKey = AnnotationKeyBase + "metrics-enabled"
+
+	// AnnotationKeyPort is used by [IngressConfig.Port].
+	AnnotationKeyPort AnnotationKey = AnnotationKeyBase + "port"
+
+	// AnnotationKeyStrategy is used by [IngressConfig.Strategy].
+	AnnotationKeyStrategy AnnotationKey = AnnotationKeyBase + "strategy"
+
+	// AnnotationKeyPDBMinAvailable is used by
+	// [IngressConfig.PDBMinAvailable].
+	AnnotationKeyPDBMinAvailable AnnotationKey = AnnotationKeyBase + "pdb-min-available"
+
+	// AnnotationKeyPDBMaxUnavailable is used by
+	// [IngressConfig.PDBMaxUnavailable].
+	AnnotationKeyPDBMaxUnavailable AnnotationKey = AnnotationKeyBase + "pdb-max-unavailable"
+
+	// AnnotationKeyTerminationGracePeriod is used by
+	// [IngressConfig.TerminationGracePeriod].
+	AnnotationKeyTerminationGracePeriod AnnotationKey = AnnotationKeyBase + "termination-grace-period"
+
+	// AnnotationKeyRevisionHistoryLimit is used by
+	// [IngressConfig.RevisionHistoryLimit].
+	AnnotationKeyRevisionHistoryLimit AnnotationKey = AnnotationKeyBase + "revision-history-limit"
+
+	// AnnotationKeyMinReadySeconds is used by
+	// [IngressConfig.MinReadySeconds].
+	AnnotationKeyMinReadySeconds AnnotationKey = AnnotationKeyBase + "min-ready-seconds"
+
+	// AnnotationKeyResourceBackends is used by
+	// [IngressConfig.ResourceBackends].
+	AnnotationKeyResourceBackends AnnotationKey = AnnotationKeyBase + "resource-backends"
+
+	// AnnotationKeyVersionChannel is used by
+	// [IngressConfig.VersionChannel].
+	AnnotationKeyVersionChannel AnnotationKey = AnnotationKeyBase + "version-channel"
+
+	// AnnotationKeyEnv is used by [IngressConfig.Env].
+	AnnotationKeyEnv AnnotationKey = AnnotationKeyBase + "env"
+
+	// AnnotationKeyCookieDomain is used by [IngressConfig.CookieDomain].
+	AnnotationKeyCookieDomain AnnotationKey = AnnotationKeyBase + "cookie-domain"
+
+	// AnnotationKeyCookieExpirationTime is used by
+	// [IngressConfig.CookieExpirationTime].
+	AnnotationKeyCookieExpirationTime AnnotationKey = AnnotationKeyBase + "cookie-expiration-time"
+
+	// AnnotationKeyWebmasterEmail is used by
+	// [IngressConfig.WebmasterEmail].
+	AnnotationKeyWebmasterEmail AnnotationKey = AnnotationKeyBase + "webmaster-email"
+
+	// AnnotationKeyPolicyConfigMap is used by
+	// [IngressConfig.PolicyConfigMap].
+	AnnotationKeyPolicyConfigMap AnnotationKey = AnnotationKeyBase + "policy-configmap"
+
+	// AnnotationKeyBypass is used by [IngressConfig.Bypass].
+	AnnotationKeyBypass AnnotationKey = AnnotationKeyBase + "bypass"
+
+	// AnnotationKeySuspend is used by [IngressConfig.Suspend].
+	AnnotationKeySuspend AnnotationKey = AnnotationKeyBase + "suspend"
+
 	// AnnotationKeyServeRobotsTxt is used by
 	// [IngressConfig.ServeRobotsTxt].
 	AnnotationKeyServeRobotsTxt AnnotationKey = AnnotationKeyBase + "serve-robots-txt"
@@ -59,6 +215,118 @@ const (
 
 	// AnnotationKeyEnvFromSec is used by [IngressConfig.EnvFromSec]
 	AnnotationKeyEnvFromSec AnnotationKey = AnnotationKeyBase + "env-from-sec"
+
+	// AnnotationKeyProtect, when set to "true", opts an ingress into
+	// being wrapped by the controller without changing its
+	// ingressClassName. Since the original ingress is still served, it
+	// requires either [InterpositionInPlace] or [AnnotationKeyIngressClass]
+	// set to another ingress class than the original one.
+	AnnotationKeyProtect AnnotationKey = AnnotationKeyBase + "protect"
+
+	// AnnotationKeyMode is used by [IngressConfig.Mode]
+	AnnotationKeyMode AnnotationKey = AnnotationKeyBase + "mode"
+
+	// AnnotationKeyInterposition is used by [IngressConfig.Interposition]
+	AnnotationKeyInterposition AnnotationKey = AnnotationKeyBase + "interposition"
+)
+
+// Interposition is how Anubis is placed in front of an ingress'
+// backends.
+type Interposition string
+
+// Contains valid [Interposition] values.
+const (
+	// InterpositionChild creates a child ingress, using the wrapped
+	// ingress class, that points at Anubis. This is the default.
+	InterpositionChild Interposition = "child"
+
+	// InterpositionInPlace rewrites the backends of the original ingress
+	// to point at Anubis instead of creating a child ingress. The
+	// original backends are stashed in an annotation so that this can be
+	// reverted. Only supported for ingresses opted in through
+	// [AnnotationKeyProtect], since the original ingress class is what
+	// serves the ingress.
+	InterpositionInPlace Interposition = "in-place"
+)
+
+// ResourceBackends is how resource backends (backend.resource), which
+// Anubis can't proxy to, are handled.
+type ResourceBackends string
+
+// Contains valid [ResourceBackends] values.
+const (
+	// ResourceBackendsPassthrough passes resource backends through
+	// unprotected, while service backends are still protected. This is
+	// the default.
+	ResourceBackendsPassthrough ResourceBackends = "passthrough"
+
+	// ResourceBackendsReject rejects ingresses with resource backends, so
+	// that they're never left unprotected by accident.
+	ResourceBackendsReject ResourceBackends = "reject"
+)
+
+// VersionChannel is the channel an ingress gets new versions of Anubis
+// from, see [Config.CanaryAnubisVersion].
+type VersionChannel string
+
+// Contains valid [VersionChannel] values.
+const (
+	// VersionChannelStable gets [Config.CanaryAnubisVersion] once it has
+	// been promoted, [Config.AnubisVersion] until then. This is the
+	// default.
+	VersionChannelStable VersionChannel = "stable"
+
+	// VersionChannelCanary gets [Config.CanaryAnubisVersion] right away.
+	VersionChannelCanary VersionChannel = "canary"
+)
+
+// TargetScheme is the scheme used to connect to an ingress' backend.
+type TargetScheme string
+
+// Contains valid [TargetScheme] values.
+const (
+	// TargetSchemeHTTP connects to the backend over plain HTTP. This is
+	// the default.
+	TargetSchemeHTTP TargetScheme = "http"
+
+	// TargetSchemeHTTPS connects to the backend over TLS, for backends
+	// that only serve HTTPS.
+	TargetSchemeHTTPS TargetScheme = "https"
+)
+
+// ChallengeMethod is a challenge algorithm supported by Anubis.
+type ChallengeMethod string
+
+// Contains valid [ChallengeMethod] values.
+const (
+	// ChallengeMethodFast is the default proof-of-work challenge.
+	ChallengeMethodFast ChallengeMethod = "fast"
+
+	// ChallengeMethodSlow is a slower proof-of-work challenge, intended
+	// for testing.
+	ChallengeMethodSlow ChallengeMethod = "slow"
+
+	// ChallengeMethodMetaRefresh is a lightweight challenge that only
+	// requires the client to follow a meta refresh, without running any
+	// JavaScript.
+	ChallengeMethodMetaRefresh ChallengeMethod = "metarefresh"
+)
+
+// Mode is the protection mode used for an ingress.
+type Mode string
+
+// Contains valid [Mode] values.
+const (
+	// ModeEnforce routes all traffic through Anubis. This is the
+	// default.
+	ModeEnforce Mode = "enforce"
+
+	// ModeShadow routes traffic directly to the backend while Anubis
+	// receives a mirrored copy of every request. This allows evaluating
+	// the impact of Anubis (through its metrics) before enforcing it.
+	// Requires the wrapped ingress controller to support request
+	// mirroring (currently only ingress-nginx).
+	ModeShadow Mode = "shadow"
 )
 
 // AnnotationKeys contains all valid [AnnotationKey] values.
@@ -70,8 +338,55 @@ var AnnotationKeys = [...]AnnotationKey{
 	AnnotationKeyMetricsPort,
 	AnnotationKeyEnvFromCM,
 	AnnotationKeyEnvFromSec,
+	AnnotationKeyMode,
+	AnnotationKeyInterposition,
+	AnnotationKeyDifficultyMin,
+	AnnotationKeyDifficultyMax,
+	AnnotationKeyAnubisVersion,
+	AnnotationKeyAnubisImage,
+	AnnotationKeyResources,
+	AnnotationKeyEnv,
+	AnnotationKeyCookieDomain,
+	AnnotationKeyCookieExpirationTime,
+	AnnotationKeyWebmasterEmail,
+	AnnotationKeyPolicyConfigMap,
+	AnnotationKeyBypass,
+	AnnotationKeySuspend,
+	AnnotationKeyProbes,
+	AnnotationKeyChildAnnotations,
+	AnnotationKeyStripAnnotations,
+	AnnotationKeyTargetScheme,
+	AnnotationKeyTargetInsecureSkipVerify,
+	AnnotationKeyTargetHost,
+	AnnotationKeyBasePrefix,
+	AnnotationKeyRedirectDomains,
+	AnnotationKeyNodeSelector,
+	AnnotationKeyTolerations,
+	AnnotationKeyAffinity,
+	AnnotationKeyTopologySpreadConstraints,
+	AnnotationKeyPriorityClassName,
+	AnnotationKeyChallengeMethod,
+	AnnotationKeySigningKeySecret,
+	AnnotationKeyOGExpiryTime,
+	AnnotationKeyCookieSameSite,
+	AnnotationKeyCookiePartitioned,
+	AnnotationKeyCookiePrefix,
+	AnnotationKeyMetricsEnabled,
+	AnnotationKeyPort,
+	AnnotationKeyStrategy,
+	AnnotationKeyPDBMinAvailable,
+	AnnotationKeyPDBMaxUnavailable,
+	AnnotationKeyTerminationGracePeriod,
+	AnnotationKeyRevisionHistoryLimit,
+	AnnotationKeyMinReadySeconds,
+	AnnotationKeyResourceBackends,
+	AnnotationKeyVersionChannel,
 }
 
+// CookieDomainAuto is the [AnnotationKeyCookieDomain] value that derives
+// the cookie domain from the hosts of the ingress.
+const CookieDomainAuto = "auto"
+
 // IngressConfig contains configuration from an ingress object.
 type IngressConfig struct {
 	// Difficulty is the difficulty parameter to pass to anubis.
@@ -91,10 +406,26 @@ type IngressConfig struct {
 	// default.
 	OGPassthrough *bool
 
+	// OGExpiryTime is how long Anubis caches OpenGraph tags when
+	// [IngressConfig.OGPassthrough] is enabled. Uses Anubis' default
+	// when unset.
+	OGExpiryTime *time.Duration
+
 	// MetricsPort is the port for Prometheus metrics to be exposed on.
 	// Defaults to 9090.
 	MetricsPort *uint32
 
+	// MetricsEnabled exposes Anubis' Prometheus metrics on
+	// [IngressConfig.MetricsPort]. When disabled, metrics are only
+	// served on the pod's loopback interface. Enabled by default.
+	MetricsEnabled *bool
+
+	// Port is the port anubis listens on and is exposed on by its
+	// service, which the wrapped ingress points to. Defaults to 8080,
+	// change it when it collides with a sidecar's port (e.g., a service
+	// mesh proxy's).
+	Port *uint32
+
 	// EnvFromCM is the name of a configmap in the same namespace as the
 	// controller to mount to the created anubis pods as environment
 	// variables. This is functionally the same as setting `EnvFrom` on
@@ -103,33 +434,370 @@ type IngressConfig struct {
 
 	// EnvFromSec is the same as [EnvFromCM], but with a secret instead.
 	EnvFromSec *string
+
+	// Mode is the protection mode to use. Defaults to [ModeEnforce].
+	Mode *Mode
+
+	// Interposition is how Anubis is placed in front of the ingress'
+	// backends. Defaults to [InterpositionChild].
+	Interposition *Interposition
+
+	// DifficultyMin and DifficultyMax enable automatically tuning the
+	// difficulty, based on traffic, within these bounds. Both must be set
+	// to enable auto-tuning (see [Config.AutoTune]), in which case
+	// [Difficulty] is the starting point.
+	DifficultyMin *int
+	DifficultyMax *int
+
+	// AnubisVersion overrides [Config.AnubisVersion] for this ingress,
+	// e.g., to canary a new Anubis release.
+	AnubisVersion *string
+
+	// AnubisImage overrides [Config.AnubisImage] for this ingress.
+	AnubisImage *string
+
+	// Resources are the compute resources of the Anubis container,
+	// provided as JSON. None are set by default.
+	Resources *corev1.ResourceRequirements
+
+	// Env contains extra environment variables to set on the Anubis
+	// container, provided as a JSON object. These are applied after
+	// [Config.EnvironmentVariables], but can't override the variables
+	// controlled by other annotations (e.g., DIFFICULTY).
+	Env map[string]string
+
+	// CookieDomain is the domain Anubis sets its cookie on, allowing a
+	// solved challenge to be shared across subdomains. When set to
+	// [CookieDomainAuto], this is the registrable domain (e.g.,
+	// example.co.uk) of the ingress' hosts.
+	CookieDomain *string
+
+	// CookieExpirationTime is how long a solved challenge is valid for.
+	// Uses the Anubis default when not set.
+	CookieExpirationTime *time.Duration
+
+	// CookieSameSite is the SameSite attribute of Anubis' cookies, one
+	// of "Lax", "Strict" or "None". Uses Anubis' default when unset.
+	CookieSameSite *string
+
+	// CookiePartitioned sets the Partitioned attribute (CHIPS) on
+	// Anubis' cookies. Uses Anubis' default when unset.
+	CookiePartitioned *bool
+
+	// CookiePrefix is the prefix of the names of Anubis' cookies, e.g.,
+	// "__Host-anubis". Uses Anubis' default when unset.
+	CookiePrefix *string
+
+	// WebmasterEmail is the contact email shown on the Anubis error page.
+	WebmasterEmail *string
+
+	// PolicyConfigMap is the name of a configmap, in the same namespace
+	// as the ingress, containing an Anubis bot policy file under the
+	// botPolicies.yaml key.
+	// See: https://anubis.techaro.lol/docs/admin/policies
+	PolicyConfigMap *string
+
+	// Bypass routes traffic directly to the original backend, removing
+	// Anubis entirely. Intended as an escape hatch during incidents.
+	// Disabled by default.
+	Bypass *bool
+
+	// Suspend stops the controller from modifying (or deleting) any of
+	// the resources it manages for the ingress, until unset. Resources
+	// are still removed when the ingress itself is deleted. Disabled by
+	// default.
+	Suspend *bool
+
+	// Probes overrides the probes of the Anubis container. Set through
+	// a JSON object, e.g., {"readiness":{"httpGet":{"path":"/","port":"http"}}}.
+	Probes *Probes
+
+	// ChildAnnotations are additional annotations set on the child
+	// ingress only, taking precedence over those copied from the parent.
+	// Set through a JSON object of strings. Ignored when using
+	// [InterpositionInPlace], since there is no child ingress.
+	ChildAnnotations map[string]string
+
+	// StripAnnotations is a comma-separated list of annotations that
+	// are not copied to the child ingress, in addition to
+	// [Config.StripAnnotations]. Entries ending in "*" match all
+	// annotations with that prefix.
+	StripAnnotations []string
+
+	// TargetScheme is the scheme Anubis uses to connect to the backend.
+	// Defaults to [TargetSchemeHTTP].
+	TargetScheme *TargetScheme
+
+	// TargetInsecureSkipVerify disables verification of the backend's
+	// TLS certificate when using [TargetSchemeHTTPS]. Disabled by
+	// default.
+	TargetInsecureSkipVerify *bool
+
+	// TargetHost overrides the Host header of requests proxied to the
+	// backend, and the SNI hostname when using [TargetSchemeHTTPS]. By
+	// default, the cluster-internal service name is used.
+	TargetHost *string
+
+	// BasePrefix is the path prefix Anubis is served under. By default,
+	// it is derived from the ingress when all of its paths share the
+	// same prefix (e.g., /app). Setting it to "/" disables this.
+	BasePrefix *string
+
+	// RedirectDomains are the domains Anubis allows redirecting to after
+	// a challenge is passed, as a comma-separated list. Defaults to the
+	// hosts of the ingress.
+	RedirectDomains []string
+
+	// NodeSelector is the node selector of the Anubis pod. Set through
+	// a JSON object of strings.
+	NodeSelector map[string]string
+
+	// Tolerations are the tolerations of the Anubis pod. Set through a
+	// JSON array of Kubernetes tolerations.
+	Tolerations []corev1.Toleration
+
+	// Affinity is the affinity of the Anubis pod. Set through a JSON
+	// Kubernetes affinity object.
+	Affinity *corev1.Affinity
+
+	// TopologySpreadConstraints are the topology spread constraints of
+	// the Anubis pod. Set through a JSON array of Kubernetes topology
+	// spread constraints. Constraints without a label selector select
+	// the pods of the ingress.
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint
+
+	// PriorityClassName is the name of the priority class of the Anubis
+	// pod.
+	PriorityClassName *string
+
+	// Strategy is the deployment strategy of the Anubis deployment. Set
+	// through a JSON Kubernetes deployment strategy. Defaults to
+	// Recreate, since cookies are signed with a key generated on startup.
+	// Using RollingUpdate requires [IngressConfig.SigningKeySecret] so
+	// that the old and new pods accept each other's cookies.
+	Strategy *appsv1.DeploymentStrategy
+
+	// PDBMinAvailable is the minAvailable of the PodDisruptionBudget of
+	// the Anubis deployment, as a number or percentage. Only used when
+	// [Config.PodDisruptionBudgetEnabled] is set. If neither it nor
+	// [IngressConfig.PDBMaxUnavailable] are set, defaults to 1.
+	PDBMinAvailable *intstr.IntOrString
+
+	// PDBMaxUnavailable is the maxUnavailable of the PodDisruptionBudget
+	// of the Anubis deployment, as a number or percentage. Can't be
+	// combined with [IngressConfig.PDBMinAvailable].
+	PDBMaxUnavailable *intstr.IntOrString
+
+	// TerminationGracePeriod is how long the Anubis pod is given to shut
+	// down, rounded down to seconds. Defaults to 30s.
+	TerminationGracePeriod *time.Duration
+
+	// RevisionHistoryLimit is the number of old ReplicaSets kept for the
+	// Anubis deployment. Defaults to 10.
+	RevisionHistoryLimit *int32
+
+	// MinReadySeconds is how long the Anubis pod has to be ready before
+	// it's considered available. Defaults to 0.
+	MinReadySeconds *int32
+
+	// ResourceBackends is how resource backends are handled. Defaults to
+	// [ResourceBackendsPassthrough].
+	ResourceBackends *ResourceBackends
+
+	// VersionChannel is the channel the ingress gets new versions of
+	// Anubis from, unless [IngressConfig.AnubisVersion] is set. Defaults
+	// to [VersionChannelStable].
+	VersionChannel *VersionChannel
+
+	// ChallengeMethod is the challenge presented to browsers. When set,
+	// a bot policy based on Anubis' default policy is generated, so it
+	// can't be combined with [IngressConfig.PolicyConfigMap].
+	ChallengeMethod *ChallengeMethod
+
+	// SigningKeySecret is the name of a secret, in the controller
+	// namespace, containing the hex-encoded ED25519 private key Anubis
+	// signs challenge cookies with under the "key" key. By default,
+	// Anubis generates a new key on every start, invalidating all
+	// existing cookies.
+	SigningKeySecret *string
+}
+
+// Probes contains the probes of the Anubis container. Probes that are
+// not set use the defaults (only a readiness probe against the metrics
+// endpoint).
+type Probes struct {
+	// Readiness replaces the default readiness probe.
+	Readiness *corev1.Probe `json:"readiness,omitempty"`
+
+	// Liveness is the liveness probe. Not set by default.
+	Liveness *corev1.Probe `json:"liveness,omitempty"`
+
+	// Startup is the startup probe. Not set by default.
+	Startup *corev1.Probe `json:"startup,omitempty"`
 }
 
-// applyDefaults applies defaults to the provided [IngressConfig].
-func applyDefaults(ic *IngressConfig) {
+// applyDefaults applies the provided defaults to the provided
+// [IngressConfig].
+func applyDefaults(ic *IngressConfig, defaults IngressDefaults) {
 	if ic.Difficulty == nil {
-		ic.Difficulty = ptr.To(4)
+		ic.Difficulty = ptr.To(defaults.Difficulty)
 	}
 
 	if ic.ServeRobotsTxt == nil {
-		ic.ServeRobotsTxt = ptr.To(true)
+		ic.ServeRobotsTxt = ptr.To(defaults.ServeRobotsTxt)
+	}
+
+	if ic.Bypass == nil {
+		ic.Bypass = ptr.To(false)
+	}
+
+	if ic.Suspend == nil {
+		ic.Suspend = ptr.To(false)
 	}
 
 	if ic.OGPassthrough == nil {
-		ic.OGPassthrough = ptr.To(true)
+		ic.OGPassthrough = ptr.To(defaults.OGPassthrough)
 	}
 
 	if ic.MetricsPort == nil {
-		ic.MetricsPort = ptr.To(uint32(9090))
+		ic.MetricsPort = ptr.To(defaults.MetricsPort)
+	}
+
+	if ic.MetricsEnabled == nil {
+		ic.MetricsEnabled = ptr.To(true)
+	}
+
+	if ic.Port == nil {
+		ic.Port = ptr.To(defaults.Port)
+	}
+
+	if ic.Mode == nil {
+		ic.Mode = ptr.To(defaults.Mode)
+	}
+
+	if ic.Interposition == nil {
+		ic.Interposition = ptr.To(InterpositionChild)
+	}
+
+	if ic.TargetScheme == nil {
+		ic.TargetScheme = ptr.To(TargetSchemeHTTP)
+	}
+
+	if ic.TargetInsecureSkipVerify == nil {
+		ic.TargetInsecureSkipVerify = ptr.To(false)
+	}
+
+	if ic.TerminationGracePeriod == nil {
+		ic.TerminationGracePeriod = ptr.To(defaults.TerminationGracePeriod)
+	}
+
+	if ic.RevisionHistoryLimit == nil {
+		ic.RevisionHistoryLimit = ptr.To(defaults.RevisionHistoryLimit)
+	}
+
+	if ic.MinReadySeconds == nil {
+		ic.MinReadySeconds = ptr.To(defaults.MinReadySeconds)
+	}
+
+	if ic.ResourceBackends == nil {
+		ic.ResourceBackends = ptr.To(ResourceBackendsPassthrough)
+	}
+
+	if ic.VersionChannel == nil {
+		ic.VersionChannel = ptr.To(VersionChannelStable)
 	}
 }
 
+// deriveCookieDomain returns the registrable domain shared by all hosts
+// of the provided ingress.
+func deriveCookieDomain(ing *networkingv1.Ingress) (string, error) {
+	var domain string
+	for _, r := range ing.Spec.Rules {
+		if r.Host == "" {
+			continue
+		}
+
+		d, err := publicsuffix.EffectiveTLDPlusOne(strings.TrimPrefix(r.Host, "*."))
+		if err != nil {
+			return "", fmt.Errorf("failed to determine registrable domain of host %q: %w", r.Host, err)
+		}
+
+		if domain != "" && d != domain {
+			return "", fmt.Errorf("hosts have different registrable domains (%q and %q)", domain, d)
+		}
+		domain = d
+	}
+
+	if domain == "" {
+		return "", fmt.Errorf("ingress has no hosts")
+	}
+
+	return domain, nil
+}
+
+// deriveBasePrefix returns the path prefix shared by all paths of the
+// provided ingress, or an empty string if it routes the root path.
+func deriveBasePrefix(ing *networkingv1.Ingress) string {
+	if ing.Spec.DefaultBackend != nil {
+		return ""
+	}
+
+	var prefix string
+	for _, r := range ing.Spec.Rules {
+		if r.HTTP == nil {
+			continue
+		}
+
+		for _, p := range r.HTTP.Paths {
+			if p.PathType != nil && *p.PathType == networkingv1.PathTypeExact {
+				return ""
+			}
+
+			path := strings.TrimSuffix(p.Path, "/")
+			if path == "" || (prefix != "" && path != prefix) {
+				return ""
+			}
+			prefix = path
+		}
+	}
+
+	return prefix
+}
+
+// deriveRedirectDomains returns the sorted, unique hosts of the provided
+// ingress.
+func deriveRedirectDomains(ing *networkingv1.Ingress) []string {
+	var hosts []string
+	for _, r := range ing.Spec.Rules {
+		if r.Host != "" {
+			hosts = append(hosts, r.Host)
+		}
+	}
+	slices.Sort(hosts)
+	return slices.Compact(hosts)
+}
+
+// parseIntOrPercent parses the provided value as a non-negative number
+// or percentage, e.g., "1" or "50%".
+func parseIntOrPercent(v string) (intstr.IntOrString, error) {
+	n := intstr.Parse(v)
+	if n.Type == intstr.String {
+		p, ok := strings.CutSuffix(v, "%")
+		if i, err := strconv.Atoi(p); !ok || err != nil || i < 0 || i > 100 {
+			return n, fmt.Errorf("expected a number or a percentage between 0%% and 100%%")
+		}
+	} else if n.IntVal < 0 {
+		return n, fmt.Errorf("expected a non-negative number")
+	}
+	return n, nil
+}
+
 // GetIngressConfigFromIngress returns an [IngressConfig] from the
-// provided [networkingv1.Ingress]. If no options are found, the default
-// configuration is returned. An error is only returned if the provided
-// ingress contains invalid configuration data (e.g., int expected, but
-// got non-int)
-func GetIngressConfigFromIngress(ing *networkingv1.Ingress) (*IngressConfig, error) {
+// provided [networkingv1.Ingress]. Values not set through annotations
+// use the provided defaults (see [Config.IngressDefaults]). An error is
+// only returned if the provided ingress contains invalid configuration
+// data (e.g., int expected, but got non-int)
+func GetIngressConfigFromIngress(ing *networkingv1.Ingress, defaults IngressDefaults) (*IngressConfig, error) {
 	cfg := IngressConfig{}
 
 	// Capture values from the annotations, if present.
@@ -172,13 +840,339 @@ func GetIngressConfigFromIngress(ing *networkingv1.Ingress) (*IngressConfig, err
 				cfg.EnvFromCM = &v
 			case AnnotationKeyEnvFromSec:
 				cfg.EnvFromSec = &v
+			case AnnotationKeyMode:
+				m := Mode(v)
+				if m != ModeEnforce && m != ModeShadow {
+					return nil, fmt.Errorf("invalid annotation %s value %q, expected one of %q or %q",
+						AnnotationKeyMode, v, ModeEnforce, ModeShadow)
+				}
+				cfg.Mode = &m
+			case AnnotationKeyInterposition:
+				i := Interposition(v)
+				if i != InterpositionChild && i != InterpositionInPlace {
+					return nil, fmt.Errorf("invalid annotation %s value %q, expected one of %q or %q",
+						AnnotationKeyInterposition, v, InterpositionChild, InterpositionInPlace)
+				}
+				cfg.Interposition = &i
+			case AnnotationKeyTargetScheme:
+				ts := TargetScheme(v)
+				if ts != TargetSchemeHTTP && ts != TargetSchemeHTTPS {
+					return nil, fmt.Errorf("invalid annotation %s value %q, expected one of %q or %q",
+						AnnotationKeyTargetScheme, v, TargetSchemeHTTP, TargetSchemeHTTPS)
+				}
+				cfg.TargetScheme = &ts
+			case AnnotationKeyTargetHost:
+				cfg.TargetHost = &v
+			case AnnotationKeyTargetInsecureSkipVerify:
+				b, err := strconv.ParseBool(v)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse annotation %s value %q as bool", AnnotationKeyTargetInsecureSkipVerify, v)
+				}
+				cfg.TargetInsecureSkipVerify = &b
+			case AnnotationKeyDifficultyMin, AnnotationKeyDifficultyMax:
+				d, err := strconv.Atoi(v)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse annotation %s value %q as int", k, v)
+				}
+				if k == AnnotationKeyDifficultyMin {
+					cfg.DifficultyMin = &d
+				} else {
+					cfg.DifficultyMax = &d
+				}
+			case AnnotationKeyAnubisVersion:
+				cfg.AnubisVersion = &v
+			case AnnotationKeyAnubisImage:
+				cfg.AnubisImage = &v
+			case AnnotationKeyResources:
+				var r corev1.ResourceRequirements
+				if err := json.Unmarshal([]byte(v), &r); err != nil {
+					return nil, fmt.Errorf("failed to parse annotation %s value %q as resource requirements: %w", AnnotationKeyResources, v, err)
+				}
+				cfg.Resources = &r
+			case AnnotationKeyProbes:
+				var p Probes
+				if err := json.Unmarshal([]byte(v), &p); err != nil {
+					return nil, fmt.Errorf("failed to parse annotation %s value %q as probes: %w", AnnotationKeyProbes, v, err)
+				}
+				cfg.Probes = &p
+			case AnnotationKeyChildAnnotations:
+				var ann map[string]string
+				if err := json.Unmarshal([]byte(v), &ann); err != nil {
+					return nil, fmt.Errorf("failed to parse annotation %s value %q as JSON object of strings: %w", AnnotationKeyChildAnnotations, v, err)
+				}
+				cfg.ChildAnnotations = ann
+			case AnnotationKeyStripAnnotations:
+				for a := range strings.SplitSeq(v, ",") {
+					if a = strings.TrimSpace(a); a != "" {
+						cfg.StripAnnotations = append(cfg.StripAnnotations, a)
+					}
+				}
+			case AnnotationKeyRedirectDomains:
+				for d := range strings.SplitSeq(v, ",") {
+					if d = strings.TrimSpace(d); d != "" {
+						cfg.RedirectDomains = append(cfg.RedirectDomains, d)
+					}
+				}
+			case AnnotationKeyNodeSelector:
+				var ns map[string]string
+				if err := json.Unmarshal([]byte(v), &ns); err != nil {
+					return nil, fmt.Errorf("failed to parse annotation %s value %q as JSON object of strings: %w", AnnotationKeyNodeSelector, v, err)
+				}
+				cfg.NodeSelector = ns
+			case AnnotationKeyTolerations:
+				var t []corev1.Toleration
+				if err := json.Unmarshal([]byte(v), &t); err != nil {
+					return nil, fmt.Errorf("failed to parse annotation %s value %q as tolerations: %w", AnnotationKeyTolerations, v, err)
+				}
+				cfg.Tolerations = t
+			case AnnotationKeyAffinity:
+				var a corev1.Affinity
+				if err := json.Unmarshal([]byte(v), &a); err != nil {
+					return nil, fmt.Errorf("failed to parse annotation %s value %q as affinity: %w", AnnotationKeyAffinity, v, err)
+				}
+				cfg.Affinity = &a
+			case AnnotationKeyTopologySpreadConstraints:
+				var c []corev1.TopologySpreadConstraint
+				if err := json.Unmarshal([]byte(v), &c); err != nil {
+					return nil, fmt.Errorf("failed to parse annotation %s value %q as topology spread constraints: %w",
+						AnnotationKeyTopologySpreadConstraints, v, err)
+				}
+				cfg.TopologySpreadConstraints = c
+			case AnnotationKeyPriorityClassName:
+				cfg.PriorityClassName = &v
+			case AnnotationKeyChallengeMethod:
+				m := ChallengeMethod(v)
+				if m != ChallengeMethodFast && m != ChallengeMethodSlow && m != ChallengeMethodMetaRefresh {
+					return nil, fmt.Errorf("invalid annotation %s value %q, expected one of %q, %q or %q",
+						AnnotationKeyChallengeMethod, v, ChallengeMethodFast, ChallengeMethodSlow, ChallengeMethodMetaRefresh)
+				}
+				cfg.ChallengeMethod = &m
+			case AnnotationKeySigningKeySecret:
+				cfg.SigningKeySecret = &v
+			case AnnotationKeyOGExpiryTime:
+				d, err := time.ParseDuration(v)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse annotation %s value %q as duration", AnnotationKeyOGExpiryTime, v)
+				}
+				cfg.OGExpiryTime = &d
+			case AnnotationKeyCookieSameSite:
+				if !slices.Contains([]string{"Lax", "Strict", "None"}, v) {
+					return nil, fmt.Errorf("invalid annotation %s value %q, expected one of %q, %q or %q",
+						AnnotationKeyCookieSameSite, v, "Lax", "Strict", "None")
+				}
+				cfg.CookieSameSite = &v
+			case AnnotationKeyCookiePartitioned:
+				b, err := strconv.ParseBool(v)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse annotation %s value %q as bool", AnnotationKeyCookiePartitioned, v)
+				}
+				cfg.CookiePartitioned = &b
+			case AnnotationKeyCookiePrefix:
+				cfg.CookiePrefix = &v
+			case AnnotationKeyMetricsEnabled:
+				b, err := strconv.ParseBool(v)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse annotation %s value %q as bool", AnnotationKeyMetricsEnabled, v)
+				}
+				cfg.MetricsEnabled = &b
+			case AnnotationKeyPort:
+				port, err := strconv.ParseUint(v, 10, 16)
+				if err != nil || port == 0 {
+					return nil, fmt.Errorf("failed to parse annotation %s value %q as a port", AnnotationKeyPort, v)
+				}
+				cfg.Port = ptr.To(uint32(port))
+			case AnnotationKeyStrategy:
+				var st appsv1.DeploymentStrategy
+				if err := json.Unmarshal([]byte(v), &st); err != nil {
+					return nil, fmt.Errorf("failed to parse annotation %s value %q as deployment strategy: %w", AnnotationKeyStrategy, v, err)
+				}
+				if st.Type != appsv1.RecreateDeploymentStrategyType && st.Type != appsv1.RollingUpdateDeploymentStrategyType {
+					return nil, fmt.Errorf("invalid annotation %s type %q, expected one of %q or %q", AnnotationKeyStrategy,
+						st.Type, appsv1.RecreateDeploymentStrategyType, appsv1.RollingUpdateDeploymentStrategyType)
+				}
+				cfg.Strategy = &st
+			case AnnotationKeyPDBMinAvailable:
+				n, err := parseIntOrPercent(v)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse annotation %s value %q as number or percentage: %w", AnnotationKeyPDBMinAvailable, v, err)
+				}
+				cfg.PDBMinAvailable = &n
+			case AnnotationKeyPDBMaxUnavailable:
+				n, err := parseIntOrPercent(v)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse annotation %s value %q as number or percentage: %w", AnnotationKeyPDBMaxUnavailable, v, err)
+				}
+				cfg.PDBMaxUnavailable = &n
+			case AnnotationKeyTerminationGracePeriod:
+				d, err := time.ParseDuration(v)
+				if err != nil || d < 0 {
+					return nil, fmt.Errorf("failed to parse annotation %s value %q as non-negative duration", AnnotationKeyTerminationGracePeriod, v)
+				}
+				cfg.TerminationGracePeriod = &d
+			case AnnotationKeyRevisionHistoryLimit:
+				n, err := strconv.ParseInt(v, 10, 32)
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("failed to parse annotation %s value %q as non-negative int", AnnotationKeyRevisionHistoryLimit, v)
+				}
+				cfg.RevisionHistoryLimit = ptr.To(int32(n))
+			case AnnotationKeyMinReadySeconds:
+				n, err := strconv.ParseInt(v, 10, 32)
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("failed to parse annotation %s value %q as non-negative int", AnnotationKeyMinReadySeconds, v)
+				}
+				cfg.MinReadySeconds = ptr.To(int32(n))
+			case AnnotationKeyResourceBackends:
+				rb := ResourceBackends(v)
+				if rb != ResourceBackendsPassthrough && rb != ResourceBackendsReject {
+					return nil, fmt.Errorf("invalid annotation %s value %q, expected one of %q or %q",
+						AnnotationKeyResourceBackends, v, ResourceBackendsPassthrough, ResourceBackendsReject)
+				}
+				cfg.ResourceBackends = &rb
+			case AnnotationKeyVersionChannel:
+				vc := VersionChannel(v)
+				if vc != VersionChannelStable && vc != VersionChannelCanary {
+					return nil, fmt.Errorf("invalid annotation %s value %q, expected one of %q or %q",
+						AnnotationKeyVersionChannel, v, VersionChannelStable, VersionChannelCanary)
+				}
+				cfg.VersionChannel = &vc
+			case AnnotationKeyEnv:
+				var env map[string]string
+				if err := json.Unmarshal([]byte(v), &env); err != nil {
+					return nil, fmt.Errorf("failed to parse annotation %s value %q as JSON object of strings: %w", AnnotationKeyEnv, v, err)
+				}
+				cfg.Env = env
+			case AnnotationKeyCookieDomain:
+				if v == CookieDomainAuto {
+					d, err := deriveCookieDomain(ing)
+					if err != nil {
+						return nil, fmt.Errorf("failed to derive cookie domain for annotation %s: %w", AnnotationKeyCookieDomain, err)
+					}
+					v = d
+				}
+				cfg.CookieDomain = &v
+			case AnnotationKeyBasePrefix:
+				if !strings.HasPrefix(v, "/") {
+					return nil, fmt.Errorf("invalid annotation %s value %q, expected a path starting with /", AnnotationKeyBasePrefix, v)
+				}
+				v = strings.TrimSuffix(v, "/")
+				cfg.BasePrefix = &v
+			case AnnotationKeyCookieExpirationTime:
+				d, err := time.ParseDuration(v)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse annotation %s value %q as duration", AnnotationKeyCookieExpirationTime, v)
+				}
+				cfg.CookieExpirationTime = &d
+			case AnnotationKeyWebmasterEmail:
+				if _, err := mail.ParseAddress(v); err != nil {
+					return nil, fmt.Errorf("failed to parse annotation %s value %q as email address", AnnotationKeyWebmasterEmail, v)
+				}
+				cfg.WebmasterEmail = &v
+			case AnnotationKeyBypass:
+				b, err := strconv.ParseBool(v)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse annotation %s value %q as bool", AnnotationKeyBypass, v)
+				}
+				cfg.Bypass = &b
+			case AnnotationKeySuspend:
+				b, err := strconv.ParseBool(v)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse annotation %s value %q as bool", AnnotationKeySuspend, v)
+				}
+				cfg.Suspend = &b
+			case AnnotationKeyPolicyConfigMap:
+				cfg.PolicyConfigMap = &v
 			default:
 				panic(fmt.Errorf("unknown annotation key %q", string(k)))
 			}
 		}
 	}
 
-	applyDefaults(&cfg)
+	if (cfg.DifficultyMin == nil) != (cfg.DifficultyMax == nil) {
+		return nil, fmt.Errorf("annotations %s and %s must be set together", AnnotationKeyDifficultyMin, AnnotationKeyDifficultyMax)
+	}
+	if cfg.DifficultyMin != nil && *cfg.DifficultyMin > *cfg.DifficultyMax {
+		return nil, fmt.Errorf("annotation %s must not be greater than %s", AnnotationKeyDifficultyMin, AnnotationKeyDifficultyMax)
+	}
+	// Auto-tuning is based on the challenges reported through metrics.
+	if cfg.MetricsEnabled != nil && !*cfg.MetricsEnabled && cfg.DifficultyMin != nil {
+		return nil, fmt.Errorf("annotations %s and %s require metrics to be enabled through %s",
+			AnnotationKeyDifficultyMin, AnnotationKeyDifficultyMax, AnnotationKeyMetricsEnabled)
+	}
+
+	if cfg.Strategy != nil && cfg.Strategy.Type == appsv1.RollingUpdateDeploymentStrategyType && cfg.SigningKeySecret == nil {
+		return nil, fmt.Errorf("annotation %s requires %s when using a %s strategy",
+			AnnotationKeyStrategy, AnnotationKeySigningKeySecret, appsv1.RollingUpdateDeploymentStrategyType)
+	}
+
+	if cfg.PDBMinAvailable != nil && cfg.PDBMaxUnavailable != nil {
+		return nil, fmt.Errorf("annotations %s and %s can't be set together", AnnotationKeyPDBMinAvailable, AnnotationKeyPDBMaxUnavailable)
+	}
+
+	// Browsers reject __Host- cookies that set a domain.
+	if cfg.CookiePrefix != nil && strings.HasPrefix(*cfg.CookiePrefix, "__Host-") && cfg.CookieDomain != nil {
+		return nil, fmt.Errorf("annotation %s can't be set when using a __Host- prefix through %s",
+			AnnotationKeyCookieDomain, AnnotationKeyCookiePrefix)
+	}
+	if cfg.ChallengeMethod != nil && cfg.PolicyConfigMap != nil {
+		return nil, fmt.Errorf("annotations %s and %s can't be set together", AnnotationKeyChallengeMethod, AnnotationKeyPolicyConfigMap)
+	}
+
+	if cfg.RedirectDomains == nil && ing != nil {
+		cfg.RedirectDomains = deriveRedirectDomains(ing)
+	}
+
+	if cfg.BasePrefix == nil && ing != nil {
+		if p := deriveBasePrefix(ing); p != "" {
+			cfg.BasePrefix = &p
+		}
+	}
+
+	applyDefaults(&cfg, defaults)
+
+	if *cfg.Port == *cfg.MetricsPort {
+		return nil, fmt.Errorf("port %d is used by both %s and %s", *cfg.Port, AnnotationKeyPort, AnnotationKeyMetricsPort)
+	}
 
 	return &cfg, nil
 }
+
+// GetIngressConfig returns the [IngressConfig] of the provided ingress,
+// like [GetIngressConfigFromIngress]. Annotations not set on the ingress
+// default to classParams, the annotations (without [AnnotationKeyBase])
+// set by the parameters of its ingress class, then to the ones of its
+// ingress class (see [Config.IngressClassDefaults]) and finally
+// [Config.IngressDefaults].
+func (c *Config) GetIngressConfig(ing *networkingv1.Ingress, classParams map[string]string) (*IngressConfig, error) {
+	classDefaults, err := c.GetIngressClassDefaults()
+	if err != nil {
+		return nil, err
+	}
+	for k := range classParams {
+		if !slices.Contains(AnnotationKeys[:], AnnotationKeyBase+AnnotationKey(k)) {
+			return nil, fmt.Errorf("invalid ingress class parameters: unknown annotation %q", k)
+		}
+	}
+
+	var defaults map[string]string
+	if ing.Spec.IngressClassName != nil {
+		defaults = maps.Clone(classDefaults[*ing.Spec.IngressClassName])
+	}
+	if len(classParams) > 0 {
+		if defaults == nil {
+			defaults = make(map[string]string, len(classParams))
+		}
+		maps.Copy(defaults, classParams)
+	}
+	if len(defaults) > 0 {
+		annotations := make(map[string]string, len(defaults)+len(ing.Annotations))
+		for k, v := range defaults {
+			annotations[AnnotationKeyBase+k] = v
+		}
+		maps.Copy(annotations, ing.Annotations)
+
+		ing = ing.DeepCopy()
+		ing.Annotations = annotations
+	}
+
+	return GetIngressConfigFromIngress(ing, c.IngressDefaults)
+}
@@ -19,16 +19,33 @@ package config
 
 import (
 	"testing"
+	"time"
 
+	"github.com/caarlos0/env/v11"
 	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
 )
 
-func getDefaults() *IngressConfig {
+// testDefaults returns the built-in [IngressDefaults].
+func testDefaults(t *testing.T) IngressDefaults {
+	t.Helper()
+
+	defaults, err := env.ParseAsWithOptions[IngressDefaults](env.Options{Environment: map[string]string{}})
+	if err != nil {
+		t.Fatalf("failed to parse defaults: %v", err)
+	}
+	return defaults
+}
+
+func getDefaults(t *testing.T) *IngressConfig {
 	var cfg IngressConfig
-	applyDefaults(&cfg)
+	applyDefaults(&cfg, testDefaults(t))
 	return &cfg
 }
 
@@ -46,7 +63,7 @@ func TestGetIngressConfigFromIngress(t *testing.T) {
 	}
 
 	defplus := func(overrides IngressConfig) *IngressConfig {
-		resp := getDefaults()
+		resp := getDefaults(t)
 		if overrides.Difficulty != nil {
 			resp.Difficulty = overrides.Difficulty
 		}
@@ -62,12 +79,141 @@ func TestGetIngressConfigFromIngress(t *testing.T) {
 		if overrides.MetricsPort != nil {
 			resp.MetricsPort = overrides.MetricsPort
 		}
+		if overrides.Port != nil {
+			resp.Port = overrides.Port
+		}
 		if overrides.EnvFromCM != nil {
 			resp.EnvFromCM = overrides.EnvFromCM
 		}
 		if overrides.EnvFromSec != nil {
 			resp.EnvFromSec = overrides.EnvFromSec
 		}
+		if overrides.Mode != nil {
+			resp.Mode = overrides.Mode
+		}
+		if overrides.Interposition != nil {
+			resp.Interposition = overrides.Interposition
+		}
+		if overrides.DifficultyMin != nil {
+			resp.DifficultyMin = overrides.DifficultyMin
+		}
+		if overrides.DifficultyMax != nil {
+			resp.DifficultyMax = overrides.DifficultyMax
+		}
+		if overrides.AnubisVersion != nil {
+			resp.AnubisVersion = overrides.AnubisVersion
+		}
+		if overrides.AnubisImage != nil {
+			resp.AnubisImage = overrides.AnubisImage
+		}
+		if overrides.Resources != nil {
+			resp.Resources = overrides.Resources
+		}
+		if overrides.Env != nil {
+			resp.Env = overrides.Env
+		}
+		if overrides.CookieDomain != nil {
+			resp.CookieDomain = overrides.CookieDomain
+		}
+		if overrides.CookieExpirationTime != nil {
+			resp.CookieExpirationTime = overrides.CookieExpirationTime
+		}
+		if overrides.WebmasterEmail != nil {
+			resp.WebmasterEmail = overrides.WebmasterEmail
+		}
+		if overrides.PolicyConfigMap != nil {
+			resp.PolicyConfigMap = overrides.PolicyConfigMap
+		}
+		if overrides.Bypass != nil {
+			resp.Bypass = overrides.Bypass
+		}
+		if overrides.Suspend != nil {
+			resp.Suspend = overrides.Suspend
+		}
+		if overrides.Probes != nil {
+			resp.Probes = overrides.Probes
+		}
+		if overrides.ChildAnnotations != nil {
+			resp.ChildAnnotations = overrides.ChildAnnotations
+		}
+		if overrides.StripAnnotations != nil {
+			resp.StripAnnotations = overrides.StripAnnotations
+		}
+		if overrides.TargetScheme != nil {
+			resp.TargetScheme = overrides.TargetScheme
+		}
+		if overrides.TargetInsecureSkipVerify != nil {
+			resp.TargetInsecureSkipVerify = overrides.TargetInsecureSkipVerify
+		}
+		if overrides.TargetHost != nil {
+			resp.TargetHost = overrides.TargetHost
+		}
+		if overrides.BasePrefix != nil {
+			resp.BasePrefix = overrides.BasePrefix
+		}
+		if overrides.RedirectDomains != nil {
+			resp.RedirectDomains = overrides.RedirectDomains
+		}
+		if overrides.NodeSelector != nil {
+			resp.NodeSelector = overrides.NodeSelector
+		}
+		if overrides.Tolerations != nil {
+			resp.Tolerations = overrides.Tolerations
+		}
+		if overrides.Affinity != nil {
+			resp.Affinity = overrides.Affinity
+		}
+		if overrides.TopologySpreadConstraints != nil {
+			resp.TopologySpreadConstraints = overrides.TopologySpreadConstraints
+		}
+		if overrides.PDBMinAvailable != nil {
+			resp.PDBMinAvailable = overrides.PDBMinAvailable
+		}
+		if overrides.PDBMaxUnavailable != nil {
+			resp.PDBMaxUnavailable = overrides.PDBMaxUnavailable
+		}
+		if overrides.TerminationGracePeriod != nil {
+			resp.TerminationGracePeriod = overrides.TerminationGracePeriod
+		}
+		if overrides.RevisionHistoryLimit != nil {
+			resp.RevisionHistoryLimit = overrides.RevisionHistoryLimit
+		}
+		if overrides.MinReadySeconds != nil {
+			resp.MinReadySeconds = overrides.MinReadySeconds
+		}
+		if overrides.ResourceBackends != nil {
+			resp.ResourceBackends = overrides.ResourceBackends
+		}
+		if overrides.VersionChannel != nil {
+			resp.VersionChannel = overrides.VersionChannel
+		}
+		if overrides.PriorityClassName != nil {
+			resp.PriorityClassName = overrides.PriorityClassName
+		}
+		if overrides.ChallengeMethod != nil {
+			resp.ChallengeMethod = overrides.ChallengeMethod
+		}
+		if overrides.SigningKeySecret != nil {
+			resp.SigningKeySecret = overrides.SigningKeySecret
+		}
+		if overrides.OGExpiryTime != nil {
+			resp.OGExpiryTime = overrides.OGExpiryTime
+		}
+		if overrides.CookieSameSite != nil {
+			resp.CookieSameSite = overrides.CookieSameSite
+		}
+		if overrides.CookiePartitioned != nil {
+			resp.CookiePartitioned = overrides.CookiePartitioned
+		}
+		if overrides.CookiePrefix != nil {
+			resp.CookiePrefix = overrides.CookiePrefix
+		}
+		if overrides.MetricsEnabled != nil {
+			resp.MetricsEnabled = overrides.MetricsEnabled
+		}
+		if overrides.Strategy != nil {
+			resp.Strategy = overrides.Strategy
+		}
 		return resp
 	}
 
@@ -80,7 +226,7 @@ func TestGetIngressConfigFromIngress(t *testing.T) {
 		{
 			name: "should support no annotations",
 			args: args{},
-			want: getDefaults(),
+			want: getDefaults(t),
 		},
 		{
 			name: "should support setting Difficulty",
@@ -110,6 +256,13 @@ func TestGetIngressConfigFromIngress(t *testing.T) {
 			})},
 			want: defplus(IngressConfig{OGPassthrough: ptr.To(false)}),
 		},
+		{
+			name: "should support setting OGExpiryTime",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyOGExpiryTime: "1h",
+			})},
+			want: defplus(IngressConfig{OGExpiryTime: ptr.To(time.Hour)}),
+		},
 		{
 			name: "should support setting MetricsPort",
 			args: args{ing(map[AnnotationKey]string{
@@ -117,6 +270,43 @@ func TestGetIngressConfigFromIngress(t *testing.T) {
 			})},
 			want: defplus(IngressConfig{MetricsPort: ptr.To(uint32(9091))}),
 		},
+		{
+			name: "should support disabling metrics",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyMetricsEnabled: "false",
+			})},
+			want: defplus(IngressConfig{MetricsEnabled: ptr.To(false)}),
+		},
+		{
+			name: "should support setting Port",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyPort: "8090",
+			})},
+			want: defplus(IngressConfig{Port: ptr.To(uint32(8090))}),
+		},
+		{
+			name: "should fail when Port isn't a valid port",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyPort: "70000",
+			})},
+			wantErr: true,
+		},
+		{
+			name: "should fail when Port collides with MetricsPort",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyPort: "9090",
+			})},
+			wantErr: true,
+		},
+		{
+			name: "should fail when difficulty bounds are set with metrics disabled",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyMetricsEnabled: "false",
+				AnnotationKeyDifficultyMin:  "2",
+				AnnotationKeyDifficultyMax:  "6",
+			})},
+			wantErr: true,
+		},
 		{
 			name: "should support setting EnvFromCM",
 			args: args{ing(map[AnnotationKey]string{
@@ -135,6 +325,375 @@ func TestGetIngressConfigFromIngress(t *testing.T) {
 				EnvFromSec: ptr.To("hello-world"),
 			}),
 		},
+		{
+			name: "should support setting Mode",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyMode: "shadow",
+			})},
+			want: defplus(IngressConfig{Mode: ptr.To(ModeShadow)}),
+		},
+		{
+			name: "should fail when an unknown Mode is set",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyMode: "report",
+			})},
+			wantErr: true,
+		},
+		{
+			name: "should support setting Interposition",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyInterposition: "in-place",
+			})},
+			want: defplus(IngressConfig{Interposition: ptr.To(InterpositionInPlace)}),
+		},
+		{
+			name: "should support rejecting resource backends",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyResourceBackends: "reject",
+			})},
+			want: defplus(IngressConfig{ResourceBackends: ptr.To(ResourceBackendsReject)}),
+		},
+		{
+			name: "should fail when an unknown ResourceBackends is set",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyResourceBackends: "drop",
+			})},
+			wantErr: true,
+		},
+		{
+			name: "should support the canary version channel",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyVersionChannel: "canary",
+			})},
+			want: defplus(IngressConfig{VersionChannel: ptr.To(VersionChannelCanary)}),
+		},
+		{
+			name: "should fail when an unknown VersionChannel is set",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyVersionChannel: "beta",
+			})},
+			wantErr: true,
+		},
+		{
+			name: "should support setting difficulty bounds",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyDifficultyMin: "2",
+				AnnotationKeyDifficultyMax: "6",
+			})},
+			want: defplus(IngressConfig{DifficultyMin: ptr.To(2), DifficultyMax: ptr.To(6)}),
+		},
+		{
+			name: "should support overriding the Anubis image and version",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyAnubisVersion: "v1.27.0",
+				AnnotationKeyAnubisImage:   "registry.example.com/anubis",
+			})},
+			want: defplus(IngressConfig{
+				AnubisVersion: ptr.To("v1.27.0"),
+				AnubisImage:   ptr.To("registry.example.com/anubis"),
+			}),
+		},
+		{
+			name: "should support setting Resources",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyResources: `{"requests":{"cpu":"100m","memory":"64Mi"}}`,
+			})},
+			want: defplus(IngressConfig{Resources: &corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100m"),
+					corev1.ResourceMemory: resource.MustParse("64Mi"),
+				},
+			}}),
+		},
+		{
+			name: "should support setting scheduling constraints",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyNodeSelector: `{"pool":"anubis"}`,
+				AnnotationKeyTolerations:  `[{"key":"dedicated","operator":"Equal","value":"anubis","effect":"NoSchedule"}]`,
+				AnnotationKeyAffinity: `{"nodeAffinity":{"requiredDuringSchedulingIgnoredDuringExecution":{"nodeSelectorTerms":` +
+					`[{"matchExpressions":[{"key":"pool","operator":"In","values":["anubis"]}]}]}}}`,
+			})},
+			want: defplus(IngressConfig{
+				NodeSelector: map[string]string{"pool": "anubis"},
+				Tolerations: []corev1.Toleration{{
+					Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "anubis", Effect: corev1.TaintEffectNoSchedule,
+				}},
+				Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+						NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{{
+							Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"anubis"},
+						}}}},
+					},
+				}},
+			}),
+		},
+		{
+			name: "should support setting TopologySpreadConstraints",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyTopologySpreadConstraints: `[{"maxSkew":1,"topologyKey":"topology.kubernetes.io/zone",` +
+					`"whenUnsatisfiable":"ScheduleAnyway"}]`,
+			})},
+			want: defplus(IngressConfig{TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+				MaxSkew:           1,
+				TopologyKey:       "topology.kubernetes.io/zone",
+				WhenUnsatisfiable: corev1.ScheduleAnyway,
+			}}}),
+		},
+		{
+			name: "should fail on invalid TopologySpreadConstraints",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyTopologySpreadConstraints: `{"maxSkew":1}`,
+			})},
+			wantErr: true,
+		},
+		{
+			name: "should support setting PDBMinAvailable",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyPDBMinAvailable: "50%",
+			})},
+			want: defplus(IngressConfig{PDBMinAvailable: ptr.To(intstr.FromString("50%"))}),
+		},
+		{
+			name: "should support setting PDBMaxUnavailable",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyPDBMaxUnavailable: "1",
+			})},
+			want: defplus(IngressConfig{PDBMaxUnavailable: ptr.To(intstr.FromInt32(1))}),
+		},
+		{
+			name: "should fail on invalid PDBMinAvailable",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyPDBMinAvailable: "half",
+			})},
+			wantErr: true,
+		},
+		{
+			name: "should fail when PDBMinAvailable and PDBMaxUnavailable are both set",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyPDBMinAvailable:   "1",
+				AnnotationKeyPDBMaxUnavailable: "1",
+			})},
+			wantErr: true,
+		},
+		{
+			name: "should support setting pod lifecycle options",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyTerminationGracePeriod: "10s",
+				AnnotationKeyRevisionHistoryLimit:   "2",
+				AnnotationKeyMinReadySeconds:        "5",
+			})},
+			want: defplus(IngressConfig{
+				TerminationGracePeriod: ptr.To(10 * time.Second),
+				RevisionHistoryLimit:   ptr.To(int32(2)),
+				MinReadySeconds:        ptr.To(int32(5)),
+			}),
+		},
+		{
+			name: "should fail on negative RevisionHistoryLimit",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyRevisionHistoryLimit: "-1",
+			})},
+			wantErr: true,
+		},
+		{
+			name: "should support setting PriorityClassName",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyPriorityClassName: "high-priority",
+			})},
+			want: defplus(IngressConfig{PriorityClassName: ptr.To("high-priority")}),
+		},
+		{
+			name: "should support setting ChallengeMethod",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyChallengeMethod: "metarefresh",
+			})},
+			want: defplus(IngressConfig{ChallengeMethod: ptr.To(ChallengeMethodMetaRefresh)}),
+		},
+		{
+			name: "should fail when ChallengeMethod is set with PolicyConfigMap",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyChallengeMethod: "metarefresh",
+				AnnotationKeyPolicyConfigMap: "policy",
+			})},
+			wantErr: true,
+		},
+		{
+			name: "should support setting a RollingUpdate Strategy",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyStrategy:         `{"type":"RollingUpdate","rollingUpdate":{"maxSurge":1,"maxUnavailable":0}}`,
+				AnnotationKeySigningKeySecret: "anubis-key",
+			})},
+			want: defplus(IngressConfig{
+				Strategy: &appsv1.DeploymentStrategy{
+					Type: appsv1.RollingUpdateDeploymentStrategyType,
+					RollingUpdate: &appsv1.RollingUpdateDeployment{
+						MaxSurge:       ptr.To(intstr.FromInt32(1)),
+						MaxUnavailable: ptr.To(intstr.FromInt32(0)),
+					},
+				},
+				SigningKeySecret: ptr.To("anubis-key"),
+			}),
+		},
+		{
+			name: "should fail when a RollingUpdate Strategy is set without a signing key",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyStrategy: `{"type":"RollingUpdate"}`,
+			})},
+			wantErr: true,
+		},
+		{
+			name: "should support setting Env",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyEnv: `{"COOKIE_EXPIRATION_TIME":"24h"}`,
+			})},
+			want: defplus(IngressConfig{Env: map[string]string{"COOKIE_EXPIRATION_TIME": "24h"}}),
+		},
+		{
+			name: "should derive the cookie domain from hosts",
+			args: args{func() *networkingv1.Ingress {
+				i := ing(map[AnnotationKey]string{AnnotationKeyCookieDomain: CookieDomainAuto})
+				i.Spec.Rules = []networkingv1.IngressRule{{Host: "www.example.co.uk"}, {Host: "*.example.co.uk"}}
+				return i
+			}()},
+			want: defplus(IngressConfig{
+				CookieDomain:    ptr.To("example.co.uk"),
+				RedirectDomains: []string{"*.example.co.uk", "www.example.co.uk"},
+			}),
+		},
+		{
+			name: "should derive the base prefix from paths",
+			args: args{func() *networkingv1.Ingress {
+				i := ing(nil)
+				i.Spec.Rules = []networkingv1.IngressRule{
+					{Host: "a.example.com", IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{Path: "/app/"}},
+					}}},
+					{Host: "b.example.com", IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{Path: "/app"}},
+					}}},
+				}
+				return i
+			}()},
+			want: defplus(IngressConfig{
+				BasePrefix:      ptr.To("/app"),
+				RedirectDomains: []string{"a.example.com", "b.example.com"},
+			}),
+		},
+		{
+			name: "should support setting BasePrefix",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyBasePrefix: "/",
+			})},
+			want: defplus(IngressConfig{BasePrefix: ptr.To("")}),
+		},
+		{
+			name: "should support setting cookie security options",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyCookieSameSite:    "Strict",
+				AnnotationKeyCookiePartitioned: "true",
+				AnnotationKeyCookiePrefix:      "__Host-anubis",
+			})},
+			want: defplus(IngressConfig{
+				CookieSameSite:    ptr.To("Strict"),
+				CookiePartitioned: ptr.To(true),
+				CookiePrefix:      ptr.To("__Host-anubis"),
+			}),
+		},
+		{
+			name: "should fail when a __Host- cookie prefix is set with a cookie domain",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyCookiePrefix: "__Host-anubis",
+				AnnotationKeyCookieDomain: "example.com",
+			})},
+			wantErr: true,
+		},
+		{
+			name: "should support setting RedirectDomains",
+			args: args{func() *networkingv1.Ingress {
+				i := ing(map[AnnotationKey]string{AnnotationKeyRedirectDomains: "example.com, www.example.com"})
+				i.Spec.Rules = []networkingv1.IngressRule{{Host: "app.example.com"}}
+				return i
+			}()},
+			want: defplus(IngressConfig{RedirectDomains: []string{"example.com", "www.example.com"}}),
+		},
+		{
+			name: "should support setting CookieExpirationTime",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyCookieExpirationTime: "24h",
+			})},
+			want: defplus(IngressConfig{CookieExpirationTime: ptr.To(24 * time.Hour)}),
+		},
+		{
+			name: "should support setting Probes",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyProbes: `{"liveness":{"httpGet":{"path":"/","port":"http"},"failureThreshold":5}}`,
+			})},
+			want: defplus(IngressConfig{Probes: &Probes{
+				Liveness: &corev1.Probe{
+					ProbeHandler: corev1.ProbeHandler{
+						HTTPGet: &corev1.HTTPGetAction{Path: "/", Port: intstr.FromString("http")},
+					},
+					FailureThreshold: 5,
+				},
+			}}),
+		},
+		{
+			name: "should support setting ChildAnnotations",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyChildAnnotations: `{"nginx.ingress.kubernetes.io/proxy-read-timeout":"120"}`,
+			})},
+			want: defplus(IngressConfig{ChildAnnotations: map[string]string{
+				"nginx.ingress.kubernetes.io/proxy-read-timeout": "120",
+			}}),
+		},
+		{
+			name: "should support setting StripAnnotations",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyStripAnnotations: "cert-manager.io/*, argocd.argoproj.io/tracking-id",
+			})},
+			want: defplus(IngressConfig{StripAnnotations: []string{
+				"cert-manager.io/*", "argocd.argoproj.io/tracking-id",
+			}}),
+		},
+		{
+			name: "should support setting an HTTPS target",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyTargetScheme:             "https",
+				AnnotationKeyTargetInsecureSkipVerify: "true",
+			})},
+			want: defplus(IngressConfig{
+				TargetScheme:             ptr.To(TargetSchemeHTTPS),
+				TargetInsecureSkipVerify: ptr.To(true),
+			}),
+		},
+		{
+			name: "should support setting TargetHost",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyTargetHost: "app.example.com",
+			})},
+			want: defplus(IngressConfig{TargetHost: ptr.To("app.example.com")}),
+		},
+		{
+			name: "should fail when an unknown TargetScheme is set",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyTargetScheme: "ftp",
+			})},
+			wantErr: true,
+		},
+		{
+			name: "should support setting Bypass",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyBypass: "true",
+			})},
+			want: defplus(IngressConfig{Bypass: ptr.To(true)}),
+		},
+		{
+			name: "should fail when only one difficulty bound is set",
+			args: args{ing(map[AnnotationKey]string{
+				AnnotationKeyDifficultyMin: "2",
+			})},
+			wantErr: true,
+		},
 		{
 			name: "should fail when invalid value is set for key",
 			args: args{ing(map[AnnotationKey]string{
@@ -145,7 +704,7 @@ func TestGetIngressConfigFromIngress(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := GetIngressConfigFromIngress(tt.args.ing)
+			got, err := GetIngressConfigFromIngress(tt.args.ing, testDefaults(t))
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetIngressConfigFromIngress() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -157,3 +716,88 @@ func TestGetIngressConfigFromIngress(t *testing.T) {
 		})
 	}
 }
+
+func TestGetIngressConfigFromIngressDefaults(t *testing.T) {
+	defaults := testDefaults(t)
+	defaults.Difficulty = 6
+	defaults.ServeRobotsTxt = false
+	defaults.Mode = ModeShadow
+	defaults.RevisionHistoryLimit = 3
+
+	got, err := GetIngressConfigFromIngress(&networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{
+		Annotations: map[string]string{AnnotationKeyDifficulty.String(): "2"},
+	}}, defaults)
+	if err != nil {
+		t.Fatalf("GetIngressConfigFromIngress() error = %v", err)
+	}
+
+	if *got.Difficulty != 2 {
+		t.Errorf("expected annotation to override default difficulty, got %d", *got.Difficulty)
+	}
+	if *got.ServeRobotsTxt {
+		t.Errorf("expected default ServeRobotsTxt to be used")
+	}
+	if *got.Mode != ModeShadow {
+		t.Errorf("expected default Mode to be used, got %q", *got.Mode)
+	}
+	if *got.RevisionHistoryLimit != 3 {
+		t.Errorf("expected default RevisionHistoryLimit to be used, got %d", *got.RevisionHistoryLimit)
+	}
+}
+
+func TestGetIngressConfigClassDefaults(t *testing.T) {
+	cfg := &Config{
+		IngressDefaults:      testDefaults(t),
+		IngressClassDefaults: `{"anubis-strict":{"difficulty":"6","challenge-method":"slow"}}`,
+	}
+
+	got, err := cfg.GetIngressConfig(&networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{AnnotationKeyDifficulty.String(): "5"}},
+		Spec:       networkingv1.IngressSpec{IngressClassName: ptr.To("anubis-strict")},
+	}, nil)
+	if err != nil {
+		t.Fatalf("GetIngressConfig() error = %v", err)
+	}
+	if *got.Difficulty != 5 {
+		t.Errorf("expected annotation to override class default difficulty, got %d", *got.Difficulty)
+	}
+	if got.ChallengeMethod == nil || *got.ChallengeMethod != ChallengeMethodSlow {
+		t.Errorf("expected class default ChallengeMethod to be used, got %v", got.ChallengeMethod)
+	}
+
+	got, err = cfg.GetIngressConfig(&networkingv1.Ingress{Spec: networkingv1.IngressSpec{IngressClassName: ptr.To("anubis")}}, nil)
+	if err != nil {
+		t.Fatalf("GetIngressConfig() error = %v", err)
+	}
+	if *got.Difficulty != 4 || got.ChallengeMethod != nil {
+		t.Errorf("expected other classes to use the global defaults, got difficulty %d", *got.Difficulty)
+	}
+
+	cfg.IngressClassDefaults = `{"anubis-strict":{"dificulty":"6"}}`
+	if _, err := cfg.GetIngressClassDefaults(); err == nil {
+		t.Error("GetIngressClassDefaults() expected error for unknown annotation")
+	}
+}
+
+func TestGetIngressConfigClassParams(t *testing.T) {
+	cfg := &Config{
+		IngressDefaults:      testDefaults(t),
+		IngressClassDefaults: `{"anubis-strict":{"difficulty":"6","challenge-method":"slow"}}`,
+	}
+	ing := &networkingv1.Ingress{Spec: networkingv1.IngressSpec{IngressClassName: ptr.To("anubis-strict")}}
+
+	got, err := cfg.GetIngressConfig(ing, map[string]string{"difficulty": "8"})
+	if err != nil {
+		t.Fatalf("GetIngressConfig() error = %v", err)
+	}
+	if *got.Difficulty != 8 {
+		t.Errorf("expected class parameters to override class default difficulty, got %d", *got.Difficulty)
+	}
+	if got.ChallengeMethod == nil || *got.ChallengeMethod != ChallengeMethodSlow {
+		t.Errorf("expected class default ChallengeMethod to be used, got %v", got.ChallengeMethod)
+	}
+
+	if _, err := cfg.GetIngressConfig(ing, map[string]string{"dificulty": "8"}); err == nil {
+		t.Error("GetIngressConfig() expected error for unknown class parameters annotation")
+	}
+}
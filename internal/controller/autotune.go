@@ -0,0 +1,301 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"go.rgst.io/jaredallard/slogext/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/client-go/tools/events"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TunedDifficultyAnnotation is the annotation on a managed deployment
+// containing the difficulty chosen by the [difficultyTuner].
+const TunedDifficultyAnnotation = "ingress-anubis.jaredallard.github.com/tuned-difficulty"
+
+// challengesIssuedMetric is the Anubis metric counting issued
+// challenges.
+const challengesIssuedMetric = "anubis_challenges_issued"
+
+// tunerSample is the last observation of an Anubis instance made by the
+// [difficultyTuner].
+type tunerSample struct {
+	// issued is the number of challenges issued.
+	issued float64
+
+	// at is when the sample was taken.
+	at time.Time
+
+	// lastChange is when the difficulty was last changed.
+	lastChange time.Time
+}
+
+// difficultyTuner periodically scrapes the metrics of each Anubis
+// instance whose ingress has difficulty bounds set (see
+// [config.IngressConfig.DifficultyMin]) and adjusts its difficulty based
+// on the rate of challenges being issued: raising it during surges and
+// lowering it once traffic normalizes. Changes are limited to one step
+// per [config.Config.AutoTuneCooldown] to avoid flapping.
+//
+// difficultyTuner implements [manager.Runnable].
+type difficultyTuner struct {
+	log      slogext.Logger
+	cfg      *config.Config
+	client   crclient.Client
+	reader   crclient.Reader
+	recorder events.EventRecorder
+	rollouts *rolloutLimiter
+	http     *http.Client
+
+	mu      sync.Mutex
+	samples map[string]*tunerSample
+}
+
+// newDifficultyTuner creates a new [difficultyTuner]. Pods are listed
+// through reader to avoid caching every pod in the cluster.
+func newDifficultyTuner(log slogext.Logger, cfg *config.Config, client crclient.Client, reader crclient.Reader,
+	recorder events.EventRecorder, rollouts *rolloutLimiter) *difficultyTuner {
+	return &difficultyTuner{
+		log:      log,
+		cfg:      cfg,
+		client:   client,
+		reader:   reader,
+		recorder: recorder,
+		rollouts: rollouts,
+		http:     &http.Client{Timeout: 10 * time.Second},
+		samples:  make(map[string]*tunerSample),
+	}
+}
+
+// getDifficulty returns the difficulty to use for the provided
+// deployment, clamped to the ingress' bounds if set. When auto-tuning is
+// enabled (see [config.Config.AutoTune]), the tuned difficulty stored on
+// the deployment is used.
+func getDifficulty(cfg *config.Config, dep *appsv1.Deployment, icfg *config.IngressConfig) int {
+	d := *icfg.Difficulty
+	if icfg.DifficultyMin == nil || icfg.DifficultyMax == nil {
+		return d
+	}
+
+	if v, err := strconv.Atoi(dep.Annotations[TunedDifficultyAnnotation]); err == nil && cfg.AutoTune {
+		d = v
+	}
+	return min(max(d, *icfg.DifficultyMin), *icfg.DifficultyMax)
+}
+
+// scrape returns the number of challenges issued by the Anubis
+// instances in the provided pods.
+func (dt *difficultyTuner) scrape(ctx context.Context, pods []corev1.Pod, port uint32) (float64, error) {
+	var total float64
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.PodIP == "" || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+
+		url := fmt.Sprintf("http://%s:%d/metrics", pod.Status.PodIP, port)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		resp, err := dt.http.Do(req)
+		if err != nil {
+			return 0, fmt.Errorf("failed to scrape %s: %w", url, err)
+		}
+
+		parser := expfmt.NewTextParser(model.UTF8Validation)
+		mfs, err := parser.TextToMetricFamilies(io.LimitReader(resp.Body, 10<<20))
+		resp.Body.Close()
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse metrics from %s: %w", url, err)
+		}
+
+		for _, name := range []string{challengesIssuedMetric, challengesIssuedMetric + "_total"} {
+			mf, ok := mfs[name]
+			if !ok {
+				continue
+			}
+			for _, m := range mf.GetMetric() {
+				total += m.GetCounter().GetValue()
+			}
+		}
+	}
+
+	return total, nil
+}
+
+// tune adjusts the difficulty of the provided managed deployment, if
+// required.
+func (dt *difficultyTuner) tune(ctx context.Context, dep *appsv1.Deployment) error {
+	owner, ok := getOwner(dep.Labels)
+	if !ok {
+		return nil
+	}
+
+	ing := &networkingv1.Ingress{}
+	if err := dt.client.Get(ctx, owner, ing); err != nil {
+		return crclient.IgnoreNotFound(err)
+	}
+
+	classParams, err := getClassParams(ctx, dt.client, dt.cfg, ing)
+	if err != nil {
+		return nil
+	}
+
+	icfg, err := dt.cfg.GetIngressConfig(ing, classParams)
+	if err != nil || icfg.DifficultyMin == nil || icfg.DifficultyMax == nil {
+		// Invalid configuration is reported by the reconciler.
+		return nil
+	}
+	if *icfg.Suspend {
+		return nil
+	}
+
+	var pods corev1.PodList
+	if err := dt.reader.List(ctx, &pods, crclient.InNamespace(dep.Namespace),
+		crclient.MatchingLabels(dep.Spec.Selector.MatchLabels)); err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	issued, err := dt.scrape(ctx, pods.Items, *icfg.MetricsPort)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	dt.mu.Lock()
+	s, ok := dt.samples[dep.Name]
+	if !ok {
+		s = &tunerSample{}
+		dt.samples[dep.Name] = s
+	}
+	prev, prevAt := s.issued, s.at
+	s.issued, s.at = issued, now
+	cooldown := now.Sub(s.lastChange) < dt.cfg.AutoTuneCooldown
+	dt.mu.Unlock()
+
+	// Need two samples to calculate a rate. A lower count means the pod
+	// restarted (or was replaced), so start over.
+	if prevAt.IsZero() || issued < prev || cooldown {
+		return nil
+	}
+	rate := (issued - prev) / now.Sub(prevAt).Seconds()
+
+	cur := getDifficulty(dt.cfg, dep, icfg)
+	next := cur
+	switch {
+	case rate >= dt.cfg.AutoTuneRaiseRate && cur < *icfg.DifficultyMax:
+		next = cur + 1
+	case rate <= dt.cfg.AutoTuneLowerRate && cur > *icfg.DifficultyMin:
+		next = cur - 1
+	}
+	if next == cur {
+		return nil
+	}
+
+	// Changing the difficulty rolls the deployment.
+	if delay := dt.rollouts.reserve(); delay > 0 {
+		return nil
+	}
+
+	patch := crclient.MergeFrom(dep.DeepCopy())
+	if dep.Annotations == nil {
+		dep.Annotations = make(map[string]string)
+	}
+	dep.Annotations[TunedDifficultyAnnotation] = strconv.Itoa(next)
+	for i := range dep.Spec.Template.Spec.Containers {
+		c := &dep.Spec.Template.Spec.Containers[i]
+		for j := range c.Env {
+			if c.Env[j].Name == "DIFFICULTY" {
+				c.Env[j].Value = strconv.Itoa(next)
+			}
+		}
+	}
+	if err := dt.client.Patch(ctx, dep, patch); err != nil {
+		return fmt.Errorf("failed to update difficulty: %w", err)
+	}
+
+	dt.mu.Lock()
+	s.lastChange = now
+	dt.mu.Unlock()
+
+	dt.log.Info("adjusted difficulty", slog.String("name", owner.Name), slog.String("namespace", owner.Namespace),
+		slog.Int("from", cur), slog.Int("to", next), slog.Float64("challenge_rate", rate))
+	dt.recorder.Eventf(ing, nil, corev1.EventTypeNormal, "DifficultyAdjusted", "AutoTune",
+		"Adjusted difficulty from %d to %d (%.2f challenges/s)", cur, next, rate)
+
+	return nil
+}
+
+// run tunes all managed deployments once.
+func (dt *difficultyTuner) run(ctx context.Context) {
+	var deps appsv1.DeploymentList
+	if err := dt.client.List(ctx, &deps, crclient.InNamespace(dt.cfg.Namespace),
+		crclient.MatchingLabels{ManagedLabel: "true"}); err != nil {
+		dt.log.Error("failed to list deployments", slog.String("err", err.Error()))
+		return
+	}
+
+	seen := make(map[string]struct{}, len(deps.Items))
+	for i := range deps.Items {
+		dep := &deps.Items[i]
+		seen[dep.Name] = struct{}{}
+		if err := dt.tune(ctx, dep); err != nil {
+			dt.log.Warn("failed to tune difficulty", slog.String("deployment", dep.Name), slog.String("err", err.Error()))
+		}
+	}
+
+	// Forget deployments that no longer exist.
+	dt.mu.Lock()
+	for name := range dt.samples {
+		if _, ok := seen[name]; !ok {
+			delete(dt.samples, name)
+		}
+	}
+	dt.mu.Unlock()
+}
+
+// Start implements [manager.Runnable].
+func (dt *difficultyTuner) Start(ctx context.Context) error {
+	t := time.NewTicker(dt.cfg.AutoTuneInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+			dt.run(ctx)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestGetDifficulty(t *testing.T) {
+	tests := []struct {
+		name     string
+		autoTune bool
+		tuned    string
+		min, max *int
+		want     int
+	}{
+		{name: "no bounds", autoTune: true, tuned: "7", want: 4},
+		{name: "not tuned yet", autoTune: true, min: ptr.To(2), max: ptr.To(6), want: 4},
+		{name: "tuned", autoTune: true, tuned: "5", min: ptr.To(2), max: ptr.To(6), want: 5},
+		{name: "tuned above the bounds", autoTune: true, tuned: "9", min: ptr.To(2), max: ptr.To(6), want: 6},
+		{name: "invalid tuned difficulty", autoTune: true, tuned: "hard", min: ptr.To(2), max: ptr.To(6), want: 4},
+		{name: "auto-tuning disabled", tuned: "5", min: ptr.To(2), max: ptr.To(6), want: 4},
+		{name: "auto-tuning disabled below the bounds", min: ptr.To(5), max: ptr.To(6), want: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep := &appsv1.Deployment{}
+			if tt.tuned != "" {
+				dep.ObjectMeta = metav1.ObjectMeta{Annotations: map[string]string{TunedDifficultyAnnotation: tt.tuned}}
+			}
+			icfg := &config.IngressConfig{Difficulty: ptr.To(4), DifficultyMin: tt.min, DifficultyMax: tt.max}
+			if got := getDifficulty(&config.Config{AutoTune: tt.autoTune}, dep, icfg); got != tt.want {
+				t.Errorf("getDifficulty() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// BackendLabel is the label selecting the anubis pods of a backend
+// other than the first one of an ingress, see
+// [anubisBackend.selectorLabels].
+const BackendLabel = "ingress-anubis.jaredallard.github.com/backend"
+
+// anubisBackend is a distinct service backend of an ingress, protected
+// by its own instance of anubis.
+type anubisBackend struct {
+	// service is the original backend.
+	service networkingv1.IngressServiceBackend
+
+	// name is the name of the resources created for the backend.
+	name string
+
+	// target is the URL anubis proxies requests to.
+	target string
+
+	// primary is true for the first backend of the ingress, whose
+	// resources are named and labeled as they were before ingresses
+	// could have more than one backend.
+	primary bool
+}
+
+// selectorLabels returns the labels selecting the anubis pods of the
+// backend. The pods of the first backend are selected through the
+// labels of the ingress owning them, the others through [BackendLabel]
+// (and don't have the owner labels) so that they don't overlap.
+func (b *anubisBackend) selectorLabels(req reconcile.Request) map[string]string {
+	if b.primary {
+		labels := ownerLabels(req)
+		labels[ManagedLabel] = "true"
+		return labels
+	}
+	return map[string]string{ManagedLabel: "true", BackendLabel: b.name}
+}
+
+// appLabels returns the labels selecting the anubis pods of the backend
+// from its deployment and service, see [anubisBackend.selectorLabels].
+func (b *anubisBackend) appLabels(req reconcile.Request) map[string]string {
+	labels := map[string]string{
+		"app.kubernetes.io/instance": "anubis",
+		"app.kubernetes.io/name":     "anubis",
+	}
+	maps.Copy(labels, b.selectorLabels(req))
+	return labels
+}
+
+// backendKey returns a string identifying the service and port of the
+// provided backend.
+func backendKey(isb *networkingv1.IngressServiceBackend) string {
+	if isb.Port.Name != "" {
+		return isb.Name + ":" + isb.Port.Name
+	}
+	return isb.Name + ":" + strconv.Itoa(int(isb.Port.Number))
+}
+
+// findBackend returns the backend in backends for the provided service
+// backend, or nil if there's none.
+func findBackend(backends []*anubisBackend, isb *networkingv1.IngressServiceBackend) *anubisBackend {
+	i := slices.IndexFunc(backends, func(b *anubisBackend) bool { return backendKey(&b.service) == backendKey(isb) })
+	if i == -1 {
+		return nil
+	}
+	return backends[i]
+}
+
+// getTargetBackends returns the distinct service backends anubis should
+// be pointed at, starting with the default backend, which is protected
+// separately from the backends of the rules when they differ. Resource
+// backends can't be targeted (anubis only supports proxying to a URL),
+// so they're skipped and passed through unwrapped by
+// [setServiceBackends].
+func getTargetBackends(spec *networkingv1.IngressSpec) ([]networkingv1.IngressServiceBackend, error) {
+	if spec.DefaultBackend == nil && len(spec.Rules) == 0 {
+		return nil, degraded("NoBackends", fmt.Errorf("no rules or default backend in ingress"))
+	}
+
+	var backends []networkingv1.IngressServiceBackend
+	add := func(b *networkingv1.IngressBackend) {
+		if b.Service == nil {
+			return
+		}
+		if !slices.ContainsFunc(backends, func(o networkingv1.IngressServiceBackend) bool {
+			return backendKey(&o) == backendKey(b.Service)
+		}) {
+			backends = append(backends, *b.Service)
+		}
+	}
+
+	if spec.DefaultBackend != nil {
+		add(spec.DefaultBackend)
+	}
+	for _, r := range spec.Rules {
+		if r.HTTP == nil {
+			continue
+		}
+
+		for i := range r.HTTP.Paths {
+			add(&r.HTTP.Paths[i].Backend)
+		}
+	}
+
+	if len(backends) == 0 {
+		return nil, degraded("NoBackends", fmt.Errorf("ingress has no service backends, resource backends are not supported"))
+	}
+	return backends, nil
+}
+
+// getResourceBackends returns the distinct resource backends of the
+// provided spec, as "Kind/name" (prefixed by the API group, if any).
+func getResourceBackends(spec *networkingv1.IngressSpec) []string {
+	var resources []string
+	add := func(b *networkingv1.IngressBackend) {
+		if b.Resource == nil {
+			return
+		}
+
+		r := b.Resource.Kind + "/" + b.Resource.Name
+		if g := ptr.Deref(b.Resource.APIGroup, ""); g != "" {
+			r = g + "/" + r
+		}
+		if !slices.Contains(resources, r) {
+			resources = append(resources, r)
+		}
+	}
+
+	if spec.DefaultBackend != nil {
+		add(spec.DefaultBackend)
+	}
+	for _, r := range spec.Rules {
+		if r.HTTP == nil {
+			continue
+		}
+
+		for i := range r.HTTP.Paths {
+			add(&r.HTTP.Paths[i].Backend)
+		}
+	}
+
+	return resources
+}
+
+// getBackends returns the backends for the provided service backends
+// of the ingress in req, resolving their targets with the provided
+// scheme.
+func (ir *IngressReconciler) getBackends(ctx context.Context, services []networkingv1.IngressServiceBackend,
+	scheme config.TargetScheme, req reconcile.Request) ([]*anubisBackend, error) {
+	backends := make([]*anubisBackend, 0, len(services))
+	for i := range services {
+		target, err := ir.getTargetFromService(ctx, req.Namespace, &services[i], scheme)
+		if err != nil {
+			return nil, err
+		}
+
+		b := &anubisBackend{service: services[i], target: target, primary: i == 0}
+		b.name = ir.resourceName(req.NamespacedName)
+		if !b.primary {
+			b.name = ir.hashedResourceName(req.Name, req.String()+"/"+backendKey(&services[i]))
+		}
+		backends = append(backends, b)
+	}
+
+	return backends, nil
+}
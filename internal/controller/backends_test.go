@@ -0,0 +1,157 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestGetTargetBackends(t *testing.T) {
+	def, api, web := serviceBackend("default-web"), serviceBackend("api"), serviceBackend("web")
+	webPort := networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+		Name: "web", Port: networkingv1.ServiceBackendPort{Number: 8080},
+	}}
+	bucket := networkingv1.IngressBackend{Resource: &corev1.TypedLocalObjectReference{
+		APIGroup: ptr.To("k8s.example.com"), Kind: "StorageBucket", Name: "static",
+	}}
+
+	// The default backend is protected by its own instance, alongside one
+	// for each distinct service of the rules.
+	spec := specWithBackends(&def, api, bucket, web, api)
+	spec.Rules = append(spec.Rules, specWithBackends(nil, def, webPort).Rules[0])
+
+	got, err := getTargetBackends(spec)
+	if err != nil {
+		t.Fatalf("getTargetBackends() error = %v", err)
+	}
+	want := []networkingv1.IngressServiceBackend{*def.Service, *api.Service, *web.Service, *webPort.Service}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("getTargetBackends() mismatch (-want +got):\n%s", diff)
+	}
+
+	backends := make([]*anubisBackend, 0, len(got))
+	for i := range got {
+		backends = append(backends, &anubisBackend{service: got[i], name: backendKey(&got[i])})
+	}
+	for _, b := range []networkingv1.IngressBackend{def, api, web, webPort} {
+		if found := findBackend(backends, b.Service); found == nil || found.name != backendKey(b.Service) {
+			t.Errorf("findBackend(%s) = %v, want the backend of the service", backendKey(b.Service), found)
+		}
+	}
+	if found := findBackend(backends, serviceBackend("other").Service); found != nil {
+		t.Errorf("findBackend(other) = %v, want nil", found)
+	}
+
+	if _, err := getTargetBackends(specWithBackends(&bucket, bucket)); err == nil {
+		t.Error("getTargetBackends() expected error for an ingress with only resource backends")
+	}
+}
+
+func TestReconcileBackends(t *testing.T) {
+	services := []crclient.Object{}
+	for _, name := range []string{"web", "api"} {
+		services = append(services, &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 80}}},
+		})
+	}
+	web, api := serviceBackend("web"), serviceBackend("api")
+
+	tests := []struct {
+		name string
+		spec *networkingv1.IngressSpec
+
+		// wantServices are the services expected to be protected by their
+		// own instance of anubis, the first one being the primary.
+		wantServices []string
+	}{
+		{
+			name:         "should protect a single backend",
+			spec:         specWithBackends(&web, web),
+			wantServices: []string{"web"},
+		},
+		{
+			name:         "should protect each backend with its own instance",
+			spec:         specWithBackends(&web, api, web),
+			wantServices: []string{"web", "api"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, map[string]string{"NAMESPACE": "ingress-anubis"})
+			ing := testIngress(cfg, nil)
+			ing.Spec.Rules, ing.Spec.DefaultBackend = tt.spec.Rules, tt.spec.DefaultBackend
+			ir := newTestReconciler(t, cfg, append(slices.Clone(services), ing)...)
+			req := reconcile.Request{NamespacedName: crclient.ObjectKeyFromObject(ing)}
+			reconcileTestIngress(t, ir, req.NamespacedName)
+
+			var deps appsv1.DeploymentList
+			if err := ir.client.List(t.Context(), &deps, crclient.InNamespace("ingress-anubis")); err != nil {
+				t.Fatalf("failed to list deployments: %v", err)
+			}
+			if len(deps.Items) != len(tt.wantServices) {
+				t.Fatalf("got %d deployments, want %d", len(deps.Items), len(tt.wantServices))
+			}
+
+			// names contains the name of the instance protecting each
+			// service.
+			names := make(map[string]string)
+			for i, svc := range tt.wantServices {
+				name := ir.resourceName(req.NamespacedName)
+				if i > 0 {
+					name = ir.hashedResourceName(req.Name, req.String()+"/"+svc+":http")
+				}
+				names[svc] = name
+
+				want := "http://" + svc + ".default.svc.cluster.local:80"
+				if got := testDeploymentEnv(t, ir, name)["TARGET"]; got != want {
+					t.Errorf("TARGET of %s = %q, want %q", name, got, want)
+				}
+				if err := ir.client.Get(t.Context(), crclient.ObjectKey{Namespace: "ingress-anubis", Name: name}, &corev1.Service{}); err != nil {
+					t.Errorf("failed to get service %s: %v", name, err)
+				}
+			}
+
+			// Each path of the child ingress points at the instance of its
+			// service.
+			var child networkingv1.Ingress
+			if err := ir.client.Get(t.Context(), crclient.ObjectKey{Namespace: "ingress-anubis", Name: names["web"]}, &child); err != nil {
+				t.Fatalf("failed to get child ingress: %v", err)
+			}
+			if got := child.Spec.DefaultBackend.Service.Name; got != names["web"] {
+				t.Errorf("default backend = %q, want %q", got, names["web"])
+			}
+			for i, p := range child.Spec.Rules[0].HTTP.Paths {
+				want := names[tt.spec.Rules[0].HTTP.Paths[i].Backend.Service.Name]
+				if got := p.Backend.Service.Name; got != want {
+					t.Errorf("backend of path %d = %q, want %q", i, got, want)
+				}
+			}
+		})
+	}
+}
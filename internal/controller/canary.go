@@ -0,0 +1,115 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// VersionChannelLabel is the label containing the version channel
+	// (see [config.VersionChannel]) of the ingress an anubis deployment
+	// was created for.
+	VersionChannelLabel = "ingress-anubis.jaredallard.github.com/version-channel"
+
+	// AnubisVersionAnnotation is the annotation containing the version of
+	// anubis a deployment was last applied with.
+	AnubisVersionAnnotation = "ingress-anubis.jaredallard.github.com/anubis-version"
+)
+
+// anubisVersion returns the version of anubis to use for an ingress with
+// the provided configuration. Ingresses in the stable channel only get
+// [config.Config.CanaryAnubisVersion] once it has been promoted (see
+// [IngressReconciler.canaryPromotion]), until then the time after which
+// to check again is returned too.
+func (ir *IngressReconciler) anubisVersion(ctx context.Context, icfg *config.IngressConfig) (string, time.Duration, error) {
+	switch {
+	case icfg.AnubisVersion != nil:
+		return *icfg.AnubisVersion, 0, nil
+	case ir.cfg.CanaryAnubisVersion == "":
+		return ir.cfg.AnubisVersion, 0, nil
+	case *icfg.VersionChannel == config.VersionChannelCanary:
+		return ir.cfg.CanaryAnubisVersion, 0, nil
+	}
+
+	wait, err := ir.canaryPromotion(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+	if wait > 0 {
+		return ir.cfg.AnubisVersion, wait, nil
+	}
+	return ir.cfg.CanaryAnubisVersion, 0, nil
+}
+
+// canaryPromotion returns how long until [config.Config.CanaryAnubisVersion]
+// is promoted to the stable channel, or 0 if it has been. It's promoted
+// once all of the deployments of the canary channel have been available
+// with it for [config.Config.CanarySoakTime]. Without any deployments in
+// the canary channel, it's never promoted.
+func (ir *IngressReconciler) canaryPromotion(ctx context.Context) (time.Duration, error) {
+	var deps appsv1.DeploymentList
+	if err := ir.client.List(ctx, &deps, crclient.InNamespace(ir.cfg.Namespace), crclient.MatchingLabels{
+		ManagedLabel:        "true",
+		VersionChannelLabel: string(config.VersionChannelCanary),
+	}); err != nil {
+		return 0, fmt.Errorf("failed to list canary deployments: %w", err)
+	}
+	if len(deps.Items) == 0 {
+		return ir.cfg.CanarySoakTime, nil
+	}
+
+	var wait time.Duration
+	for i := range deps.Items {
+		dep := &deps.Items[i]
+		if dep.Annotations[AnubisVersionAnnotation] != ir.cfg.CanaryAnubisVersion || !deploymentAvailable(dep) {
+			return ir.cfg.CanarySoakTime, nil
+		}
+		wait = max(wait, ir.cfg.CanarySoakTime-time.Since(availableSince(dep)))
+	}
+	return wait, nil
+}
+
+// availableSince returns when the provided deployment, which is
+// available, last became available or finished rolling out.
+func availableSince(dep *appsv1.Deployment) time.Time {
+	var since time.Time
+	for _, c := range dep.Status.Conditions {
+		t := c.LastTransitionTime.Time
+		switch {
+		case c.Type == appsv1.DeploymentAvailable && c.Status == corev1.ConditionTrue:
+		case c.Type == appsv1.DeploymentProgressing && c.Reason == "NewReplicaSetAvailable":
+			// Deployments stay available while rolling out, unless they use
+			// the Recreate strategy.
+			t = c.LastUpdateTime.Time
+		default:
+			continue
+		}
+		if t.After(since) {
+			since = t
+		}
+	}
+	return since
+}
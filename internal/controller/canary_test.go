@@ -0,0 +1,145 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestAnubisVersion(t *testing.T) {
+	// canary returns a deployment of the canary channel running version,
+	// available for the provided amount of time (if any).
+	canary := func(name, version string, availableFor time.Duration) *appsv1.Deployment {
+		dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "ingress-anubis",
+			Labels:      map[string]string{ManagedLabel: "true", VersionChannelLabel: string(config.VersionChannelCanary)},
+			Annotations: map[string]string{AnubisVersionAnnotation: version},
+		}}
+		if availableFor > 0 {
+			dep.Status = appsv1.DeploymentStatus{
+				UpdatedReplicas:   1,
+				AvailableReplicas: 1,
+				Conditions: []appsv1.DeploymentCondition{{
+					Type:               appsv1.DeploymentAvailable,
+					Status:             corev1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(time.Now().Add(-availableFor)),
+				}},
+			}
+		}
+		return dep
+	}
+
+	tests := []struct {
+		name     string
+		canary   string
+		channel  config.VersionChannel
+		override *string
+		objs     []crclient.Object
+		want     string
+		wantWait bool
+	}{
+		{
+			name:    "should use the stable version without a canary",
+			channel: config.VersionChannelCanary,
+			want:    "v1.26.0",
+		},
+		{
+			name:    "should use the canary version in the canary channel",
+			canary:  "v1.27.0",
+			channel: config.VersionChannelCanary,
+			want:    "v1.27.0",
+		},
+		{
+			name:     "should use the version of the ingress",
+			canary:   "v1.27.0",
+			channel:  config.VersionChannelCanary,
+			override: ptr.To("v1.25.0"),
+			want:     "v1.25.0",
+		},
+		{
+			name:     "should not promote the canary without canary deployments",
+			canary:   "v1.27.0",
+			channel:  config.VersionChannelStable,
+			want:     "v1.26.0",
+			wantWait: true,
+		},
+		{
+			name:     "should not promote the canary while it soaks",
+			canary:   "v1.27.0",
+			channel:  config.VersionChannelStable,
+			objs:     []crclient.Object{canary("a", "v1.27.0", 2*time.Hour), canary("b", "v1.27.0", time.Minute)},
+			want:     "v1.26.0",
+			wantWait: true,
+		},
+		{
+			name:     "should not promote the canary while deployments run another version",
+			canary:   "v1.27.0",
+			channel:  config.VersionChannelStable,
+			objs:     []crclient.Object{canary("a", "v1.26.0", 2*time.Hour)},
+			want:     "v1.26.0",
+			wantWait: true,
+		},
+		{
+			name:     "should not promote the canary while deployments are unavailable",
+			canary:   "v1.27.0",
+			channel:  config.VersionChannelStable,
+			objs:     []crclient.Object{canary("a", "v1.27.0", 0)},
+			want:     "v1.26.0",
+			wantWait: true,
+		},
+		{
+			name:    "should promote the canary once it soaked",
+			canary:  "v1.27.0",
+			channel: config.VersionChannelStable,
+			objs:    []crclient.Object{canary("a", "v1.27.0", 2*time.Hour)},
+			want:    "v1.27.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, map[string]string{
+				"NAMESPACE":             "ingress-anubis",
+				"ANUBIS_VERSION":        "v1.26.0",
+				"CANARY_ANUBIS_VERSION": tt.canary,
+				"CANARY_SOAK_TIME":      "1h",
+			})
+			ir := newTestReconciler(t, cfg, tt.objs...)
+
+			icfg := &config.IngressConfig{VersionChannel: &tt.channel, AnubisVersion: tt.override}
+			got, wait, err := ir.anubisVersion(t.Context(), icfg)
+			if err != nil {
+				t.Fatalf("anubisVersion() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("anubisVersion() = %q, want %q", got, tt.want)
+			}
+			if (wait > 0) != tt.wantWait {
+				t.Errorf("anubisVersion() wait = %s, wantWait %v", wait, tt.wantWait)
+			}
+		})
+	}
+}
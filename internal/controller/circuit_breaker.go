@@ -0,0 +1,112 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// circuitBreaker tracks consecutive reconcile failures per ingress.
+// Once an ingress has failed [config.Config.CircuitBreakerThreshold]
+// times in a row its circuit is "open" and it is only retried every
+// [config.Config.CircuitBreakerRetryInterval], so that a handful of
+// broken ingresses don't dominate the workqueue. A nil circuitBreaker
+// never opens.
+type circuitBreaker struct {
+	threshold     int
+	retryInterval time.Duration
+
+	mu       sync.Mutex
+	failures map[types.NamespacedName]int
+}
+
+// newCircuitBreaker creates a [circuitBreaker] from the provided
+// configuration. If disabled, nil is returned.
+func newCircuitBreaker(cfg *config.Config) *circuitBreaker {
+	if cfg.CircuitBreakerThreshold <= 0 {
+		return nil
+	}
+
+	return &circuitBreaker{
+		threshold:     cfg.CircuitBreakerThreshold,
+		retryInterval: cfg.CircuitBreakerRetryInterval,
+		failures:      make(map[types.NamespacedName]int),
+	}
+}
+
+// failure records a failed reconcile of the provided ingress. The
+// number of consecutive failures is returned, as well as if this
+// failure caused the circuit to open.
+func (cb *circuitBreaker) failure(key types.NamespacedName) (failures int, opened bool) {
+	if cb == nil {
+		return 0, false
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.failures[key]++
+	failures = cb.failures[key]
+	if failures == cb.threshold {
+		circuitBreakerOpen.Inc()
+		return failures, true
+	}
+
+	return failures, false
+}
+
+// success records a successful reconcile of the provided ingress,
+// resetting its failure count. Returns true if the circuit was open.
+func (cb *circuitBreaker) success(key types.NamespacedName) bool {
+	if cb == nil {
+		return false
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	failures, ok := cb.failures[key]
+	if !ok {
+		return false
+	}
+	delete(cb.failures, key)
+
+	if failures >= cb.threshold {
+		circuitBreakerOpen.Dec()
+		return true
+	}
+
+	return false
+}
+
+// forget drops the failures recorded for the provided ingress, e.g.,
+// once it has been deleted or is no longer managed, closing its circuit
+// if it was open.
+func (cb *circuitBreaker) forget(key types.NamespacedName) {
+	cb.success(key)
+}
+
+// isOpen returns true if the provided number of consecutive failures
+// opens the circuit.
+func (cb *circuitBreaker) isOpen(failures int) bool {
+	return cb != nil && failures >= cb.threshold
+}
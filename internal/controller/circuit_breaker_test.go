@@ -0,0 +1,86 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	cb := newCircuitBreaker(&config.Config{CircuitBreakerThreshold: 3, CircuitBreakerRetryInterval: time.Minute})
+	key := types.NamespacedName{Namespace: "default", Name: "web"}
+	open := testutil.ToFloat64(circuitBreakerOpen)
+
+	// fail records n failures, returning the result of the last one.
+	fail := func(n int) (failures int, opened bool) {
+		for range n {
+			failures, opened = cb.failure(key)
+		}
+		return failures, opened
+	}
+
+	if failures, opened := fail(2); failures != 2 || opened || cb.isOpen(failures) {
+		t.Fatalf("failure() = %d, %v, want the circuit to stay closed", failures, opened)
+	}
+	if failures, opened := fail(1); failures != 3 || !opened || !cb.isOpen(failures) {
+		t.Fatalf("failure() = %d, %v, want the circuit to open", failures, opened)
+	}
+	if failures, opened := fail(1); failures != 4 || opened || !cb.isOpen(failures) {
+		t.Fatalf("failure() = %d, %v, want the circuit to stay open", failures, opened)
+	}
+	if got := testutil.ToFloat64(circuitBreakerOpen); got != open+1 {
+		t.Errorf("circuitBreakerOpen = %v, want %v", got, open+1)
+	}
+
+	if !cb.success(key) {
+		t.Error("success() = false, want the open circuit to close")
+	}
+	if cb.success(key) {
+		t.Error("success() = true, want nothing to reset")
+	}
+	if got := testutil.ToFloat64(circuitBreakerOpen); got != open {
+		t.Errorf("circuitBreakerOpen = %v, want %v", got, open)
+	}
+	if failures, _ := cb.failure(key); failures != 1 {
+		t.Errorf("failure() = %d after a success, want 1", failures)
+	}
+
+	fail(3)
+	cb.forget(key)
+	if got := testutil.ToFloat64(circuitBreakerOpen); got != open {
+		t.Errorf("circuitBreakerOpen = %v after forget(), want %v", got, open)
+	}
+	if failures, _ := cb.failure(key); failures != 1 {
+		t.Errorf("failure() = %d after forget(), want 1", failures)
+	}
+
+	// A disabled circuit breaker never opens.
+	var disabled *circuitBreaker
+	if newCircuitBreaker(&config.Config{}) != disabled {
+		t.Error("newCircuitBreaker() expected nil without a threshold")
+	}
+	if failures, opened := disabled.failure(key); opened || disabled.isOpen(failures) {
+		t.Error("expected a disabled circuit breaker to never open")
+	}
+	disabled.forget(key)
+}
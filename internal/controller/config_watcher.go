@@ -0,0 +1,118 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	"go.rgst.io/jaredallard/slogext/v2"
+)
+
+// ErrConfigChanged is returned by [KubernetesService.Run] when the
+// configuration file, or the kubeconfig of a remote cluster, changed,
+// signaling that the controller should be
+// started again with the new configuration. Every managed ingress is
+// reconciled when the controller starts, applying the new
+// configuration to all of them.
+var ErrConfigChanged = errors.New("configuration file changed")
+
+// configWatcher watches [config.Config.ConfigFile] for changes,
+// stopping the manager with [ErrConfigChanged] when it changes to a
+// valid configuration that differs from the current one.
+//
+// configWatcher implements [manager.Runnable] and runs on all replicas.
+type configWatcher struct {
+	log slogext.Logger
+	cfg *config.Config
+
+	// contents is the last seen contents of the configuration file.
+	contents []byte
+}
+
+// newConfigWatcher creates a new [configWatcher] for the configuration
+// file in cfg. If no configuration file is used, nil is returned.
+func newConfigWatcher(log slogext.Logger, cfg *config.Config) (*configWatcher, error) {
+	if cfg.ConfigFile == "" || cfg.ConfigReloadInterval == 0 {
+		return nil, nil
+	}
+
+	b, err := os.ReadFile(cfg.ConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	return &configWatcher{log: log, cfg: cfg, contents: b}, nil
+}
+
+// changed returns true if the configuration file changed to a valid
+// configuration that differs from the current one.
+func (w *configWatcher) changed() (bool, error) {
+	b, err := os.ReadFile(w.cfg.ConfigFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if bytes.Equal(b, w.contents) {
+		return false, nil
+	}
+	w.contents = b
+
+	newCfg, err := w.cfg.Reload()
+	if err != nil {
+		return false, fmt.Errorf("ignoring invalid configuration: %w", err)
+	}
+
+	return !reflect.DeepEqual(newCfg, w.cfg), nil
+}
+
+// Start implements [manager.Runnable].
+func (w *configWatcher) Start(ctx context.Context) error {
+	t := time.NewTicker(w.cfg.ConfigReloadInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+
+		changed, err := w.changed()
+		if err != nil {
+			w.log.Error("failed to check for configuration changes", slog.String("err", err.Error()))
+			continue
+		}
+		if changed {
+			w.log.Info("configuration file changed, restarting")
+			return ErrConfigChanged
+		}
+	}
+}
+
+// NeedLeaderElection implements [manager.LeaderElectionRunnable]. All
+// replicas need to pick up configuration changes.
+func (w *configWatcher) NeedLeaderElection() bool {
+	return false
+}
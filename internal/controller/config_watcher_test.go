@@ -0,0 +1,84 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	"go.rgst.io/jaredallard/slogext/v2"
+)
+
+func TestConfigWatcherChanged(t *testing.T) {
+	const contents = "RESOURCE_PREFIX: ia-\nCONFIG_RELOAD_INTERVAL: 1m\n"
+
+	tests := []struct {
+		name     string
+		contents string
+		want     bool
+		wantErr  bool
+	}{
+		{
+			name:     "should ignore unchanged files",
+			contents: contents,
+		},
+		{
+			name:     "should ignore changes not affecting the configuration",
+			contents: "# Reformatted.\nRESOURCE_PREFIX: \"ia-\"\nCONFIG_RELOAD_INTERVAL: 60s\n",
+		},
+		{
+			name:     "should detect configuration changes",
+			contents: "RESOURCE_PREFIX: anubis-\nCONFIG_RELOAD_INTERVAL: 1m\n",
+			want:     true,
+		},
+		{
+			name:     "should ignore invalid configurations",
+			contents: "RESOURCE_PREFIX: Not_Valid\nCONFIG_RELOAD_INTERVAL: 1m\n",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+				t.Fatalf("failed to write config file: %v", err)
+			}
+			cfg, err := config.Load(map[string]string{"CONFIG_FILE": path})
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			w, err := newConfigWatcher(slogext.New(), cfg)
+			if err != nil {
+				t.Fatalf("newConfigWatcher() error = %v", err)
+			}
+
+			if err := os.WriteFile(path, []byte(tt.contents), 0o600); err != nil {
+				t.Fatalf("failed to write config file: %v", err)
+			}
+			got, err := w.changed()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("changed() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("changed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -19,18 +19,55 @@
 package controller
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/jaredallard/ingress-anubis/internal/api/v1alpha1"
 	"github.com/jaredallard/ingress-anubis/internal/config"
 	"go.rgst.io/jaredallard/slogext/v2"
+	"golang.org/x/time/rate"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
+// EventRecorderName is the name events are recorded by.
+const EventRecorderName = "ingress-anubis"
+
+// LocalClusterName is the name used to refer to the cluster the
+// controller is running in, e.g., in metrics.
+const LocalClusterName = "local"
+
 // KubernetesService contains all of the setup and logic for the
 // Kubernetes controller(s).
 type KubernetesService struct {
@@ -43,30 +80,395 @@ func NewKubernetesService(cfg *config.Config, log slogext.Logger) *KubernetesSer
 	return &KubernetesService{log, cfg}
 }
 
+// getRESTConfig returns the configuration used to talk to the cluster
+// the controller is running against. See [config.Config.Kubeconfig] and
+// [config.Config.KubeContext].
+func (s *KubernetesService) getRESTConfig() (*rest.Config, error) {
+	if s.cfg.Kubeconfig == "" && s.cfg.KubeContext == "" {
+		return ctrl.GetConfig()
+	}
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if s.cfg.Kubeconfig != "" {
+		// Support lists of files, like kubectl does.
+		rules.Precedence = filepath.SplitList(s.cfg.Kubeconfig)
+	}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules,
+		&clientcmd.ConfigOverrides{CurrentContext: s.cfg.KubeContext},
+	).ClientConfig()
+}
+
+// clientOptions returns the options used for all clients created by
+// the controller. With [config.Config.DryRun], they only send dry run
+// requests.
+func clientOptions(cfg *config.Config) crclient.Options {
+	return crclient.Options{
+		DryRun: ptr.To(cfg.DryRun),
+		Cache: &crclient.CacheOptions{
+			// We only ever read a handful of ConfigMaps (e.g., bot
+			// policies), Secrets (e.g., TLS certificates),
+			// PodDisruptionBudgets and NetworkPolicies, don't cache every
+			// one in the cluster.
+			DisableFor: []crclient.Object{
+				&corev1.ConfigMap{}, &corev1.Secret{}, &policyv1.PodDisruptionBudget{}, &networkingv1.NetworkPolicy{},
+			},
+		},
+	}
+}
+
+// cacheOptions returns the options of the caches used by the
+// controller, only watching [config.Config.WatchNamespaces] (and the
+// controller namespace, where the managed resources live) when set.
+func cacheOptions(cfg *config.Config) cache.Options {
+	// Only the metadata of the Secrets referenced by ingresses, which
+	// live in the controller namespace, is watched.
+	opts := cache.Options{
+		ByObject: map[crclient.Object]cache.ByObject{
+			&corev1.Secret{}: {Namespaces: map[string]cache.Config{cfg.Namespace: {}}},
+		},
+	}
+	opts.SyncPeriod = &cfg.SyncPeriod
+	if len(cfg.WatchNamespaces) == 0 {
+		return opts
+	}
+
+	namespaces := map[string]cache.Config{cfg.Namespace: {}}
+	for _, ns := range cfg.WatchNamespaces {
+		namespaces[ns] = cache.Config{}
+	}
+	opts.DefaultNamespaces = namespaces
+	return opts
+}
+
+// controllerOptions returns the options of the ingress controllers,
+// see [config.Config.MaxConcurrentReconciles].
+func controllerOptions(cfg *config.Config) controller.Options {
+	return controller.Options{
+		MaxConcurrentReconciles: cfg.MaxConcurrentReconciles,
+		RateLimiter: workqueue.NewTypedMaxOfRateLimiter(
+			workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](
+				cfg.ReconcileRetryBaseDelay, cfg.ReconcileRetryMaxDelay),
+			&workqueue.TypedBucketRateLimiter[reconcile.Request]{
+				Limiter: rate.NewLimiter(rate.Limit(cfg.ReconcileQPS), cfg.ReconcileBurst),
+			},
+		),
+	}
+}
+
+// controllerReadyCheck returns a [healthz.Checker] reporting the
+// readiness of the controllers using the provided cache. Replicas that
+// aren't the leader are always ready, since they're only standing by
+// (and serving webhooks). Otherwise, the cache has to have synced.
+func controllerReadyCheck(mgr ctrl.Manager, c cache.Cache) healthz.Checker {
+	return func(req *http.Request) error {
+		select {
+		case <-mgr.Elected():
+		default:
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(req.Context(), time.Second)
+		defer cancel()
+
+		if !c.WaitForCacheSync(ctx) {
+			return errors.New("caches have not synced yet")
+		}
+
+		return nil
+	}
+}
+
+// apiServerCheck returns a [healthz.Checker] reporting whether the API
+// server of the cluster described by restCfg is reachable.
+func apiServerCheck(restCfg *rest.Config) (healthz.Checker, error) {
+	dc, err := discovery.NewDiscoveryClientForConfig(restCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), time.Second)
+		defer cancel()
+
+		if err := dc.RESTClient().Get().AbsPath("/version").Do(ctx).Error(); err != nil {
+			return fmt.Errorf("failed to reach API server: %w", err)
+		}
+
+		return nil
+	}, nil
+}
+
+// addCluster sets up an [IngressReconciler], and everything supporting
+// it, that watches and creates resources in the provided cluster. name
+// is either [LocalClusterName], in which case cl is mgr, or the name of
+// the kubeconfig secret of a remote cluster.
+func (s *KubernetesService) addCluster(ctx context.Context, mgr ctrl.Manager, name string, cl cluster.Cluster,
+	rollouts *rolloutLimiter, notif *notifier) error {
+	log := s.log
+	// Keep the names of the local checks and controllers stable.
+	suffix := ""
+	if name != LocalClusterName {
+		log = log.With(slog.String("cluster", name))
+		suffix = "-" + name
+	}
+
+	pf := newPreflight(log, s.cfg, name, cl.GetAPIReader(), cl.GetClient(), cl.GetEventRecorder(EventRecorderName))
+	if err := mgr.Add(pf); err != nil {
+		return fmt.Errorf("failed to add preflight checks: %w", err)
+	}
+	if err := mgr.AddReadyzCheck("preflight"+suffix, pf.Check); err != nil {
+		return fmt.Errorf("failed to add readiness check: %w", err)
+	}
+	if err := mgr.AddReadyzCheck("controller"+suffix, controllerReadyCheck(mgr, cl.GetCache())); err != nil {
+		return fmt.Errorf("failed to add readiness check: %w", err)
+	}
+
+	// The local API server is always checked by [KubernetesService.Run],
+	// even when only remote clusters are managed.
+	if name != LocalClusterName {
+		apiCheck, err := apiServerCheck(cl.GetConfig())
+		if err != nil {
+			return fmt.Errorf("failed to create API server check: %w", err)
+		}
+		if err := mgr.AddReadyzCheck("apiserver"+suffix, apiCheck); err != nil {
+			return fmt.Errorf("failed to add readiness check: %w", err)
+		}
+	}
+
+	if s.cfg.GarbageCollectionInterval > 0 {
+		if err := mgr.Add(newGarbageCollector(log, s.cfg, cl.GetAPIReader(), cl.GetClient())); err != nil {
+			return fmt.Errorf("failed to add garbage collector: %w", err)
+		}
+	}
+
+	if s.cfg.ManageIngressClasses {
+		if err := mgr.Add(newIngressClassManager(log, s.cfg, cl.GetClient())); err != nil {
+			return fmt.Errorf("failed to add ingress class manager: %w", err)
+		}
+	}
+
+	if s.cfg.TLSSecretSyncInterval > 0 {
+		if err := mgr.Add(newSecretReflector(log, s.cfg, cl.GetClient())); err != nil {
+			return fmt.Errorf("failed to add secret reflector: %w", err)
+		}
+	}
+
+	if s.cfg.AutoTune {
+		if err := mgr.Add(newDifficultyTuner(log, s.cfg, cl.GetClient(), cl.GetAPIReader(),
+			cl.GetEventRecorder(EventRecorderName), rollouts)); err != nil {
+			return fmt.Errorf("failed to add difficulty tuner: %w", err)
+		}
+	}
+
+	ir := &IngressReconciler{
+		cluster:  name,
+		log:      log,
+		cfg:      s.cfg,
+		client:   cl.GetClient(),
+		recorder: cl.GetEventRecorder(EventRecorderName),
+		rollouts: rollouts,
+		images:   newImageRollout(log, s.cfg, cl.GetClient()),
+		breaker:  newCircuitBreaker(s.cfg),
+		verifier: newRouteVerifier(s.cfg, name),
+		notifier: notif,
+		degraded: newDegradedTracker(s.cfg),
+		applied:  newAppliedVersions(),
+	}
+	managedIngresses.add(name, ir)
+	if err := indexReferences(ctx, s.cfg, cl.GetFieldIndexer()); err != nil {
+		return err
+	}
+	if err := indexOwners(ctx, cl.GetFieldIndexer()); err != nil {
+		return err
+	}
+
+	b := builder.
+		ControllerManagedBy(mgr).
+		Named("ingress" + suffix).
+		WatchesRawSource(source.Kind(cl.GetCache(), &networkingv1.Ingress{},
+			&handler.TypedEnqueueRequestForObject[*networkingv1.Ingress]{},
+			ingressSelectorPredicate[*networkingv1.Ingress](s.cfg), ingressChangedPredicate[*networkingv1.Ingress]())).
+		WatchesRawSource(source.Kind(cl.GetCache(), &appsv1.Deployment{},
+			handler.TypedEnqueueRequestsFromMapFunc(ownerRequests[*appsv1.Deployment](s.cfg)),
+			specChangedPredicate[*appsv1.Deployment]())).
+		WatchesRawSource(source.Kind(cl.GetCache(), &corev1.Service{},
+			handler.TypedEnqueueRequestsFromMapFunc(ownerRequests[*corev1.Service](s.cfg)))).
+		WatchesRawSource(source.Kind(cl.GetCache(), &networkingv1.Ingress{},
+			handler.TypedEnqueueRequestsFromMapFunc(ownerRequests[*networkingv1.Ingress](s.cfg)),
+			specChangedPredicate[*networkingv1.Ingress]())).
+		WatchesRawSource(source.Kind(cl.GetCache(), &networkingv1.IngressClass{},
+			handler.TypedEnqueueRequestsFromMapFunc(ingressClassRequests[*networkingv1.IngressClass](cl.GetClient())))).
+		WatchesRawSource(source.Kind(cl.GetCache(), metadataOf(corev1.SchemeGroupVersion.WithKind("ConfigMap")),
+			handler.TypedEnqueueRequestsFromMapFunc(referenceRequests[*metav1.PartialObjectMetadata](s.cfg, cl.GetClient(), false)))).
+		WatchesRawSource(source.Kind(cl.GetCache(), metadataOf(corev1.SchemeGroupVersion.WithKind("Secret")),
+			handler.TypedEnqueueRequestsFromMapFunc(referenceRequests[*metav1.PartialObjectMetadata](s.cfg, cl.GetClient(), true))))
+	if s.cfg.IngressClassParams {
+		b = b.WatchesRawSource(source.Kind(cl.GetCache(), &v1alpha1.AnubisIngressClassParams{},
+			handler.TypedEnqueueRequestsFromMapFunc(classParamsRequests[*v1alpha1.AnubisIngressClassParams](cl.GetClient()))))
+	}
+	if err := b.WithOptions(controllerOptions(s.cfg)).Complete(ir); err != nil {
+		return fmt.Errorf("failed to create controller: %w", err)
+	}
+
+	if err := builder.
+		ControllerManagedBy(mgr).
+		Named("ingress-status" + suffix).
+		WatchesRawSource(source.Kind(cl.GetCache(), &networkingv1.Ingress{},
+			handler.TypedEnqueueRequestsFromMapFunc(ownerRequests[*networkingv1.Ingress](s.cfg)),
+			statusChangedPredicate[*networkingv1.Ingress]())).
+		Complete(&statusReconciler{ir}); err != nil {
+		return fmt.Errorf("failed to create status controller: %w", err)
+	}
+
+	return nil
+}
+
 // Run starts the kubernetes controller(s)
 func (s *KubernetesService) Run(ctx context.Context) error {
 	crlog.SetLogger(logr.FromSlogHandler(s.log.GetHandler()))
 
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return fmt.Errorf("failed to create scheme: %w", err)
+	}
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		return fmt.Errorf("failed to create scheme: %w", err)
+	}
+
 	opts := ctrl.Options{
-		Logger: logr.FromSlogHandler(s.log.GetHandler()),
+		Scheme:                  scheme,
+		Logger:                  logr.FromSlogHandler(s.log.GetHandler()),
+		HealthProbeBindAddress:  s.cfg.HealthProbeBindAddress,
+		Metrics:                 metricsserver.Options{BindAddress: s.cfg.MetricsBindAddress},
+		PprofBindAddress:        s.cfg.PprofBindAddress,
+		GracefulShutdownTimeout: &s.cfg.GracefulShutdownTimeout,
+		Client:                  clientOptions(s.cfg),
+		Cache:                   cacheOptions(s.cfg),
 	}
 	if s.cfg.LeaderElection {
 		opts.LeaderElection = true
-		opts.LeaderElectionID = "ingress-anubis.jaredallard.github.io"
-		opts.LeaderElectionNamespace = s.cfg.Namespace
+		opts.LeaderElectionID = s.cfg.LeaderElectionID
+		opts.LeaderElectionNamespace = cmp.Or(s.cfg.LeaderElectionNamespace, s.cfg.Namespace)
+		opts.LeaseDuration = &s.cfg.LeaderElectionLeaseDuration
+		opts.RenewDeadline = &s.cfg.LeaderElectionRenewDeadline
+		opts.RetryPeriod = &s.cfg.LeaderElectionRetryPeriod
+
+		// We exit right after the manager stops, so hand off leadership
+		// right away instead of waiting for the lease to expire.
+		opts.LeaderElectionReleaseOnCancel = true
+	}
+	if s.cfg.WebhookEnabled {
+		opts.WebhookServer = webhook.NewServer(webhook.Options{
+			Port:    s.cfg.WebhookPort,
+			CertDir: s.cfg.WebhookCertDir,
+		})
+	}
+
+	restCfg, err := s.getRESTConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get kubernetes client configuration: %w", err)
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), opts)
+	mgr, err := ctrl.NewManager(restCfg, opts)
 	if err != nil {
 		return fmt.Errorf("failed to create manager: %w", err)
 	}
 
-	if err := builder.
-		ControllerManagedBy(mgr).
-		For(&networkingv1.Ingress{}).
-		Complete(&IngressReconciler{s.log, s.cfg, mgr.GetClient()}); err != nil {
-		return fmt.Errorf("failed to create controller: %w", err)
+	if err := registerMetrics(); err != nil {
+		return fmt.Errorf("failed to register metrics: %w", err)
+	}
+
+	rollouts := newRolloutLimiter(s.cfg)
+
+	notif, err := newNotifier(s.log, s.cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create notifier: %w", err)
+	}
+	if notif != nil {
+		if err := mgr.Add(notif); err != nil {
+			return fmt.Errorf("failed to add notifier: %w", err)
+		}
+	}
+
+	cw, err := newConfigWatcher(s.log, s.cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+	if cw != nil {
+		if err := mgr.Add(cw); err != nil {
+			return fmt.Errorf("failed to add config watcher: %w", err)
+		}
+	}
+
+	if err := mgr.AddHealthzCheck("ping", healthz.Ping); err != nil {
+		return fmt.Errorf("failed to add health check: %w", err)
+	}
+
+	apiCheck, err := apiServerCheck(restCfg)
+	if err != nil {
+		return fmt.Errorf("failed to create API server check: %w", err)
 	}
+	if err := mgr.AddReadyzCheck("apiserver", apiCheck); err != nil {
+		return fmt.Errorf("failed to add readiness check: %w", err)
+	}
+
+	// When remote clusters are configured, we only manage those and not
+	// the cluster we're running in.
+	if len(s.cfg.RemoteKubeconfigSecrets) == 0 {
+		if err := s.addCluster(ctx, mgr, LocalClusterName, mgr, rollouts, notif); err != nil {
+			return err
+		}
+	} else {
+		kw := newKubeconfigWatcher(s.log, s.cfg, mgr.GetAPIReader())
+		for _, secretName := range s.cfg.RemoteKubeconfigSecrets {
+			cl, err := s.newRemoteCluster(ctx, mgr, kw, secretName)
+			if err != nil {
+				return fmt.Errorf("failed to create remote cluster from secret %q: %w", secretName, err)
+			}
+			if err := s.addCluster(ctx, mgr, secretName, cl, rollouts, notif); err != nil {
+				return fmt.Errorf("failed to add remote cluster from secret %q: %w", secretName, err)
+			}
+		}
+
+		if s.cfg.ConfigReloadInterval > 0 {
+			if err := mgr.Add(kw); err != nil {
+				return fmt.Errorf("failed to add kubeconfig watcher: %w", err)
+			}
+		}
+	}
+
+	if s.cfg.GrafanaDashboard {
+		if err := mgr.Add(&dashboard{s.log, s.cfg, mgr.GetAPIReader(), mgr.GetClient()}); err != nil {
+			return fmt.Errorf("failed to add grafana dashboard: %w", err)
+		}
+	}
+
+	// Webhooks are served by all replicas, unlike the controllers which
+	// only run on the leader.
+	if s.cfg.WebhookEnabled {
+		mgr.GetWebhookServer().Register(ReferenceWebhookPath, &webhook.Admission{
+			Handler: &ReferenceProtector{s.cfg, mgr.GetClient()},
+		})
+		if err := mgr.AddReadyzCheck("webhook", mgr.GetWebhookServer().StartedChecker()); err != nil {
+			return fmt.Errorf("failed to add readiness check: %w", err)
+		}
+	}
+
+	if err := mgr.Start(ctx); err != nil {
+		if errors.Is(err, ErrConfigChanged) {
+			s.log.Info("shut down gracefully to apply configuration changes")
+		}
+		return err
+	}
+
+	s.log.Info("shut down gracefully")
+	return nil
+}
 
-	return mgr.Start(ctx)
+// metadataOf returns an empty [metav1.PartialObjectMetadata] of the
+// provided kind, used to only watch the metadata of resources.
+func metadataOf(gvk schema.GroupVersionKind) *metav1.PartialObjectMetadata {
+	obj := &metav1.PartialObjectMetadata{}
+	obj.SetGroupVersionKind(gvk)
+	return obj
 }
@@ -0,0 +1,81 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+)
+
+func TestGetRESTConfig(t *testing.T) {
+	dir := t.TempDir()
+	kubeconfig := func(name, server string) string {
+		path := filepath.Join(dir, name)
+		contents := `apiVersion: v1
+kind: Config
+current-context: ` + name + `
+clusters:
+- name: ` + name + `
+  cluster:
+    server: ` + server + `
+contexts:
+- name: ` + name + `
+  context:
+    cluster: ` + name + `
+    user: ` + name + `
+users:
+- name: ` + name + `
+  user:
+    token: secret
+`
+		if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+			t.Fatalf("failed to write kubeconfig: %v", err)
+		}
+		return path
+	}
+	a := kubeconfig("a", "https://a.example.com")
+	b := kubeconfig("b", "https://b.example.com")
+
+	tests := []struct {
+		name       string
+		kubeconfig string
+		context    string
+		want       string
+		wantErr    bool
+	}{
+		{name: "current context", kubeconfig: a, want: "https://a.example.com"},
+		{name: "first file wins", kubeconfig: a + string(filepath.ListSeparator) + b, want: "https://a.example.com"},
+		{name: "context of another file", kubeconfig: a + string(filepath.ListSeparator) + b, context: "b", want: "https://b.example.com"},
+		{name: "unknown context", kubeconfig: a, context: "c", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewKubernetesService(&config.Config{Kubeconfig: tt.kubeconfig, KubeContext: tt.context}, nil)
+			got, err := s.getRESTConfig()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getRESTConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Host != tt.want {
+				t.Errorf("getRESTConfig() host = %q, want %q", got.Host, tt.want)
+			}
+		})
+	}
+}
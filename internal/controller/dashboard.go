@@ -0,0 +1,120 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"log/slog"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	"go.rgst.io/jaredallard/slogext/v2"
+	corev1 "k8s.io/api/core/v1"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DashboardConfigMapKey is the key in the dashboard ConfigMap that
+// contains the dashboard JSON.
+const DashboardConfigMapKey = "ingress-anubis.json"
+
+// dashboardSyncInterval is how often the dashboard ConfigMap is
+// re-applied, reverting any outside changes.
+const dashboardSyncInterval = 10 * time.Minute
+
+// dashboardTemplate is the Grafana dashboard for the managed fleet.
+// __NAMESPACE__ and __PREFIX__ are replaced with the controller
+// namespace and resource prefix respectively.
+//
+//go:embed dashboard.json
+var dashboardTemplate string
+
+// dashboard maintains a ConfigMap containing a Grafana dashboard for
+// the managed fleet, labeled so that it is discovered by the Grafana
+// dashboard sidecar. See [config.Config.GrafanaDashboard].
+//
+// dashboard implements [manager.Runnable].
+type dashboard struct {
+	log    slogext.Logger
+	cfg    *config.Config
+	reader crclient.Reader
+	client crclient.Client
+}
+
+// render returns the dashboard JSON for the current configuration.
+func (d *dashboard) render() string {
+	return strings.NewReplacer(
+		"__NAMESPACE__", d.cfg.Namespace,
+		"__PREFIX__", d.cfg.ResourcePrefix,
+	).Replace(dashboardTemplate)
+}
+
+// reconcile ensures that the dashboard ConfigMap exists and is up to
+// date. The ConfigMap is read through reader to avoid caching every
+// ConfigMap in the cluster.
+func (d *dashboard) reconcile(ctx context.Context) error {
+	cm := &corev1.ConfigMap{}
+	key := crclient.ObjectKey{Namespace: d.cfg.Namespace, Name: d.cfg.ResourcePrefix + "grafana-dashboard"}
+
+	err := d.reader.Get(ctx, key, cm)
+	if err := crclient.IgnoreNotFound(err); err != nil {
+		return fmt.Errorf("failed to check existence of dashboard configmap: %w", err)
+	}
+	exists := err == nil
+
+	cm.Name = key.Name
+	cm.Namespace = key.Namespace
+	cm.Labels = maps.Clone(d.cfg.GrafanaDashboardLabels)
+	if cm.Labels == nil {
+		cm.Labels = make(map[string]string)
+	}
+	cm.Labels[ManagedLabel] = "true"
+	cm.Data = map[string]string{DashboardConfigMapKey: d.render()}
+
+	if exists {
+		err = d.client.Update(ctx, cm)
+	} else {
+		err = d.client.Create(ctx, cm)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reconcile dashboard configmap: %w", err)
+	}
+
+	return nil
+}
+
+// Start implements [manager.Runnable].
+func (d *dashboard) Start(ctx context.Context) error {
+	t := time.NewTicker(dashboardSyncInterval)
+	defer t.Stop()
+
+	for {
+		if err := d.reconcile(ctx); err != nil {
+			d.log.Error("failed to reconcile grafana dashboard", slog.String("err", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"encoding/json"
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDashboardReconcile(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        map[string]string
+		objs       []crclient.Object
+		wantName   string
+		wantLabels map[string]string
+	}{
+		{
+			name:       "should create the configmap",
+			wantName:   "ia-grafana-dashboard",
+			wantLabels: map[string]string{"grafana_dashboard": "1", ManagedLabel: "true"},
+		},
+		{
+			name:       "should use the resource prefix and labels",
+			env:        map[string]string{"RESOURCE_PREFIX": "anubis-", "GRAFANA_DASHBOARD_LABELS": "dashboards:anubis"},
+			wantName:   "anubis-grafana-dashboard",
+			wantLabels: map[string]string{"dashboards": "anubis", ManagedLabel: "true"},
+		},
+		{
+			name: "should update an outdated configmap",
+			objs: []crclient.Object{&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "ia-grafana-dashboard", Namespace: "ingress-anubis", Labels: map[string]string{"old": "true"}},
+				Data:       map[string]string{DashboardConfigMapKey: "{}"},
+			}},
+			wantName:   "ia-grafana-dashboard",
+			wantLabels: map[string]string{"grafana_dashboard": "1", ManagedLabel: "true"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := map[string]string{"NAMESPACE": "ingress-anubis", "GRAFANA_DASHBOARD": "true"}
+			maps.Copy(env, tt.env)
+			cfg := testConfig(t, env)
+
+			client := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(tt.objs...).Build()
+			d := &dashboard{cfg: cfg, reader: client, client: client}
+			if err := d.reconcile(t.Context()); err != nil {
+				t.Fatalf("reconcile() error = %v", err)
+			}
+
+			var cm corev1.ConfigMap
+			if err := client.Get(t.Context(), crclient.ObjectKey{Namespace: "ingress-anubis", Name: tt.wantName}, &cm); err != nil {
+				t.Fatalf("failed to get dashboard configmap: %v", err)
+			}
+			if diff := cmp.Diff(tt.wantLabels, cm.Labels); diff != "" {
+				t.Errorf("labels mismatch (-want +got):\n%s", diff)
+			}
+
+			data := cm.Data[DashboardConfigMapKey]
+			if !json.Valid([]byte(data)) {
+				t.Errorf("dashboard is not valid JSON")
+			}
+			if strings.Contains(data, "__NAMESPACE__") || strings.Contains(data, "__PREFIX__") {
+				t.Errorf("dashboard contains unreplaced placeholders")
+			}
+			if !strings.Contains(data, cfg.ResourcePrefix) {
+				t.Errorf("dashboard doesn't reference the resource prefix %q", cfg.ResourcePrefix)
+			}
+		})
+	}
+}
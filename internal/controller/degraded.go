@@ -0,0 +1,110 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// degradedError is an error caused by the state of an ingress, or of
+// the resources it references, that often resolves itself moments
+// later, e.g., an ingress without rules while its app is still being
+// deployed. These are retried every [config.Config.DegradedRetryInterval]
+// for up to [config.Config.DegradedRetryTimeout], then treated like a
+// [reconcile.TerminalError].
+type degradedError struct {
+	// reason is the reason of the events emitted for the error.
+	reason string
+	err    error
+}
+
+// Error implements [error].
+func (e *degradedError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *degradedError) Unwrap() error {
+	return e.err
+}
+
+// degraded wraps err in a [degradedError] with the provided event
+// reason.
+func degraded(reason string, err error) error {
+	return &degradedError{reason, err}
+}
+
+// asDegraded returns the [degradedError] in err's chain, if any.
+func asDegraded(err error) (*degradedError, bool) {
+	var de *degradedError
+	ok := errors.As(err, &de)
+	return de, ok
+}
+
+// degradedTracker tracks since when each ingress has been degraded (see
+// [degradedError]), to bound how long it's retried for.
+type degradedTracker struct {
+	timeout time.Duration
+
+	mu    sync.Mutex
+	since map[types.NamespacedName]degradedSince
+}
+
+// degradedSince is when an ingress, at a generation, became degraded.
+type degradedSince struct {
+	generation int64
+	time       time.Time
+}
+
+// newDegradedTracker creates a [degradedTracker] from the provided
+// configuration.
+func newDegradedTracker(cfg *config.Config) *degradedTracker {
+	return &degradedTracker{
+		timeout: cfg.DegradedRetryTimeout,
+		since:   make(map[types.NamespacedName]degradedSince),
+	}
+}
+
+// retry records that the provided ingress, at the provided generation,
+// is degraded and returns true if it should still be retried. Changing
+// the ingress restarts the timeout.
+func (dt *degradedTracker) retry(key types.NamespacedName, generation int64) bool {
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+
+	s, ok := dt.since[key]
+	if !ok || s.generation != generation {
+		s = degradedSince{generation, time.Now()}
+		dt.since[key] = s
+	}
+	return time.Since(s.time) < dt.timeout
+}
+
+// forget stops tracking the provided ingress, e.g., once it reconciled
+// successfully.
+func (dt *degradedTracker) forget(key types.NamespacedName) {
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+
+	delete(dt.since, key)
+}
@@ -0,0 +1,173 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"errors"
+	"maps"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/client-go/tools/events"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcileDegraded(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		annotations map[config.AnnotationKey]string
+		modify      func(*networkingv1.Ingress)
+		wantRequeue time.Duration
+		wantErr     bool
+		wantReason  string
+	}{
+		{
+			name:        "should retry ingresses without backends",
+			modify:      func(ing *networkingv1.Ingress) { ing.Spec.DefaultBackend = nil },
+			wantRequeue: 30 * time.Second,
+			wantReason:  "NoBackends",
+		},
+		{
+			name:        "should retry ingresses with a missing policy",
+			annotations: map[config.AnnotationKey]string{config.AnnotationKeyPolicyConfigMap: "policy"},
+			wantRequeue: 30 * time.Second,
+			wantReason:  "PolicyNotFound",
+		},
+		{
+			name:        "should use the configured retry interval",
+			env:         map[string]string{"DEGRADED_RETRY_INTERVAL": "5s"},
+			modify:      func(ing *networkingv1.Ingress) { ing.Spec.DefaultBackend = nil },
+			wantRequeue: 5 * time.Second,
+			wantReason:  "NoBackends",
+		},
+		{
+			name:       "should give up once the retry timeout expired",
+			env:        map[string]string{"DEGRADED_RETRY_TIMEOUT": "0s"},
+			modify:     func(ing *networkingv1.Ingress) { ing.Spec.DefaultBackend = nil },
+			wantErr:    true,
+			wantReason: "InvalidIngress",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := map[string]string{"NAMESPACE": "ingress-anubis"}
+			maps.Copy(env, tt.env)
+			cfg := testConfig(t, env)
+
+			annotations := make(map[string]string, len(tt.annotations))
+			for k, v := range tt.annotations {
+				annotations[k.String()] = v
+			}
+			ing := testIngress(cfg, annotations)
+			if tt.modify != nil {
+				tt.modify(ing)
+			}
+			ir := newTestReconciler(t, cfg, ing)
+			req := reconcile.Request{NamespacedName: crclient.ObjectKeyFromObject(ing)}
+
+			var res reconcile.Result
+			var err error
+			for {
+				res, err = ir.Reconcile(t.Context(), req)
+				//nolint:staticcheck // Why: Set by Reconcile after adding the finalizer.
+				if err != nil || !res.Requeue {
+					break
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Reconcile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, reconcile.TerminalError(nil)) {
+				t.Errorf("Reconcile() error = %v, want a terminal error", err)
+			}
+			if res.RequeueAfter != tt.wantRequeue {
+				t.Errorf("Reconcile() RequeueAfter = %s, want %s", res.RequeueAfter, tt.wantRequeue)
+			}
+
+			recorder := ir.recorder.(*events.FakeRecorder)
+			var reasons []string
+			for len(recorder.Events) > 0 {
+				e := <-recorder.Events
+				if strings.HasPrefix(e, "Warning ") {
+					reasons = append(reasons, strings.Fields(e)[1])
+				}
+			}
+			if len(reasons) != 1 || reasons[0] != tt.wantReason {
+				t.Errorf("warning event reasons = %v, want [%s]", reasons, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestDegradedTracker(t *testing.T) {
+	key := crclient.ObjectKey{Namespace: "default", Name: "web"}
+
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		steps   func(dt *degradedTracker)
+		want    bool
+	}{
+		{
+			name:    "should retry within the timeout",
+			timeout: time.Hour,
+			want:    true,
+		},
+		{
+			name:    "should not retry once the timeout expired",
+			timeout: time.Hour,
+			steps: func(dt *degradedTracker) {
+				dt.retry(key, 1)
+				dt.since[key] = degradedSince{1, time.Now().Add(-2 * time.Hour)}
+			},
+		},
+		{
+			name:    "should restart the timeout when the ingress changes",
+			timeout: time.Hour,
+			steps: func(dt *degradedTracker) {
+				dt.since[key] = degradedSince{0, time.Now().Add(-2 * time.Hour)}
+			},
+			want: true,
+		},
+		{
+			name:    "should restart the timeout once forgotten",
+			timeout: time.Hour,
+			steps: func(dt *degradedTracker) {
+				dt.since[key] = degradedSince{1, time.Now().Add(-2 * time.Hour)}
+				dt.forget(key)
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dt := newDegradedTracker(&config.Config{DegradedRetryTimeout: tt.timeout})
+			if tt.steps != nil {
+				tt.steps(dt)
+			}
+			if got := dt.retry(key, 1); got != tt.want {
+				t.Errorf("retry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
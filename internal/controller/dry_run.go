@@ -0,0 +1,157 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/pmezard/go-difflib/difflib"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/yaml"
+)
+
+// reportDryRun reports the changes that applying obj, the result of a
+// dry run, would have made to current, the existing resource (if
+// exists), with [config.Config.DryRun]. They're logged with a diff and
+// reported through an event on the ingress owning the resource.
+func (ir *IngressReconciler) reportDryRun(ctx context.Context, kind string, exists bool,
+	current, obj crclient.Object) (controllerutil.OperationResult, error) {
+	op, diff, err := plannedChanges(exists, current, obj)
+	if err != nil || op == controllerutil.OperationResultNone {
+		return op, err
+	}
+
+	ir.log.Info("dry run: resource would have been changed", slog.String("kind", kind),
+		slog.String("name", crclient.ObjectKeyFromObject(obj).String()), slog.String("operation", string(op)),
+		slog.String("diff", diff))
+
+	if key, ok := getOwner(obj.GetLabels()); ok {
+		owner := &networkingv1.Ingress{}
+		if err := ir.client.Get(ctx, key, owner); err == nil {
+			ir.recorder.Eventf(owner, obj, corev1.EventTypeNormal, "DryRun", "Reconcile",
+				"Would have %s %s %s/%s", op, kind, obj.GetNamespace(), obj.GetName())
+		}
+	}
+	return op, nil
+}
+
+// logPlannedChanges logs the changes that applying ac will make to
+// current, the existing resource (if exists), when debug logging is
+// enabled. They're determined through a server-side dry run of the
+// apply, so they include defaulting and the fields owned by others.
+func (ir *IngressReconciler) logPlannedChanges(ctx context.Context, kind string, exists bool,
+	current crclient.Object, ac *unstructured.Unstructured) error {
+	if !ir.log.GetHandler().Enabled(ctx, slog.LevelDebug) {
+		return nil
+	}
+
+	planned := ac.DeepCopy()
+	if err := ir.client.Apply(ctx, crclient.ApplyConfigurationFromUnstructured(planned),
+		crclient.FieldOwner(FieldManager), crclient.ForceOwnership, crclient.DryRunAll); err != nil {
+		return err
+	}
+	op, diff, err := plannedChanges(exists, current, planned)
+	if err != nil || op == controllerutil.OperationResultNone {
+		return err
+	}
+
+	ir.log.Debug("applying changes to resource", slog.String("kind", kind),
+		slog.String("name", crclient.ObjectKeyFromObject(planned).String()), slog.String("operation", string(op)),
+		slog.String("diff", diff))
+	return nil
+}
+
+// plannedChanges returns the operation that turns current, the existing
+// resource (if exists), into obj, along with a unified diff of their
+// YAML. Only the fields kept by [dryRunComparable] are compared.
+func plannedChanges(exists bool, current, obj crclient.Object) (controllerutil.OperationResult, string, error) {
+	var before string
+	if exists {
+		u, err := dryRunComparable(current)
+		if err != nil {
+			return controllerutil.OperationResultNone, "", err
+		}
+		b, err := yaml.Marshal(u)
+		if err != nil {
+			return controllerutil.OperationResultNone, "", err
+		}
+		before = string(b)
+	}
+	u, err := dryRunComparable(obj)
+	if err != nil {
+		return controllerutil.OperationResultNone, "", err
+	}
+	b, err := yaml.Marshal(u)
+	if err != nil {
+		return controllerutil.OperationResultNone, "", err
+	}
+	after := string(b)
+
+	op := controllerutil.OperationResultNone
+	switch {
+	case !exists:
+		op = controllerutil.OperationResultCreated
+	case before != after:
+		op = controllerutil.OperationResultUpdated
+	default:
+		return op, "", nil
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(before),
+		B:        difflib.SplitLines(after),
+		FromFile: "current",
+		ToFile:   "planned",
+		Context:  3,
+	})
+	return op, diff, err
+}
+
+// dryRunComparable returns obj as an unstructured object without its
+// status and the fields set by the API server on every write, so that
+// only the changes made by the controller show up in diffs.
+func dryRunComparable(obj crclient.Object) (map[string]any, error) {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	// The type is only set on some objects, depending on where they come
+	// from.
+	delete(u, "apiVersion")
+	delete(u, "kind")
+	delete(u, "status")
+	if md, ok := u["metadata"].(map[string]any); ok {
+		for _, k := range []string{"resourceVersion", "uid", "generation", "creationTimestamp", "managedFields"} {
+			delete(md, k)
+		}
+		if annotations, ok := md["annotations"].(map[string]any); ok {
+			delete(annotations, SpecHashAnnotation)
+			if len(annotations) == 0 {
+				delete(md, "annotations")
+			}
+		}
+	}
+	return u, nil
+}
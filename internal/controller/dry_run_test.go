@@ -0,0 +1,136 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestDryRunComparable(t *testing.T) {
+	current := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", ResourceVersion: "1", UID: "a"},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP},
+	}
+	applied := current.DeepCopy()
+	applied.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Service"}
+	applied.ResourceVersion = "2"
+	applied.Annotations = map[string]string{SpecHashAnnotation: "abc"}
+	applied.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+
+	before, err := dryRunComparable(current)
+	if err != nil {
+		t.Fatalf("dryRunComparable() error = %v", err)
+	}
+	after, err := dryRunComparable(applied)
+	if err != nil {
+		t.Fatalf("dryRunComparable() error = %v", err)
+	}
+	if diff := cmp.Diff(before, after); diff != "" {
+		t.Errorf("expected no changes, got (-before +after):\n%s", diff)
+	}
+
+	applied.Spec.Type = corev1.ServiceTypeExternalName
+	if after, _ = dryRunComparable(applied); cmp.Equal(before, after) {
+		t.Error("expected a change of the spec to show up")
+	}
+}
+
+func TestPlannedChanges(t *testing.T) {
+	current := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", ResourceVersion: "1"},
+		Data:       map[string]string{"a": "1", "b": "2"},
+	}
+	planned := current.DeepCopy()
+	planned.ResourceVersion = "2"
+
+	op, diff, err := plannedChanges(true, current, planned)
+	if err != nil {
+		t.Fatalf("plannedChanges() error = %v", err)
+	}
+	if op != controllerutil.OperationResultNone || diff != "" {
+		t.Errorf("expected no changes, got %q with diff:\n%s", op, diff)
+	}
+
+	planned.Data["b"] = "3"
+	op, diff, err = plannedChanges(true, current, planned)
+	if err != nil {
+		t.Fatalf("plannedChanges() error = %v", err)
+	}
+	want := `--- current
++++ planned
+@@ -1,6 +1,6 @@
+ data:
+   a: "1"
+-  b: "2"
++  b: "3"
+ metadata:
+   name: web
+   namespace: default
+`
+	if op != controllerutil.OperationResultUpdated {
+		t.Errorf("expected %q, got %q", controllerutil.OperationResultUpdated, op)
+	}
+	if d := cmp.Diff(want, diff); d != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", d)
+	}
+
+	if op, _, _ = plannedChanges(false, nil, planned); op != controllerutil.OperationResultCreated {
+		t.Errorf("expected %q, got %q", controllerutil.OperationResultCreated, op)
+	}
+}
+
+func TestDryRunReported(t *testing.T) {
+	gvk := corev1.SchemeGroupVersion.WithKind("Service")
+	owner := crclient.ObjectKey{Name: "web", Namespace: "default"}
+	obj := &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+		Name:      "ia-web",
+		Namespace: "ingress-anubis",
+		Labels:    ownerLabels(reconcile.Request{NamespacedName: owner}),
+	}}
+	current := obj.DeepCopy()
+	current.ResourceVersion = "1"
+
+	av := newAppliedVersions()
+	if av.reported(gvk, obj, current, "a") {
+		t.Fatal("expected changes not to be reported yet")
+	}
+	av.recordReported(gvk, obj, current, "a")
+	if !av.reported(gvk, obj, current, "a") {
+		t.Error("expected changes to be reported")
+	}
+	if av.reported(gvk, obj, current, "b") {
+		t.Error("expected changes to the desired state to be reported again")
+	}
+	changed := current.DeepCopy()
+	changed.ResourceVersion = "2"
+	if av.reported(gvk, obj, changed, "a") {
+		t.Error("expected changes to the existing resource to be reported again")
+	}
+
+	av.forget(owner)
+	if av.reported(gvk, obj, current, "a") {
+		t.Error("expected changes to be forgotten with their owner")
+	}
+}
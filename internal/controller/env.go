@@ -0,0 +1,90 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// EnvChecksumAnnotation is the pod template annotation containing the
+// checksum of the ConfigMaps and Secrets anubis gets its environment
+// variables from, causing pods to be rolled when they change (the
+// environment of running containers is never updated).
+const EnvChecksumAnnotation = "ingress-anubis.jaredallard.github.com/env-checksum"
+
+// envChecksum returns a checksum of the contents of the ConfigMaps and
+// Secrets referenced by envFrom, or an empty string if there are none.
+// Sources that don't exist are part of the checksum, so that pods are
+// rolled once they're created.
+func (ir *IngressReconciler) envChecksum(ctx context.Context, envFrom []corev1.EnvFromSource) (string, error) {
+	if len(envFrom) == 0 {
+		return "", nil
+	}
+
+	h := sha256.New()
+	for _, src := range envFrom {
+		var data map[string][]byte
+		var err error
+		switch {
+		case src.ConfigMapRef != nil:
+			fmt.Fprintf(h, "configmap/%s\x00", src.ConfigMapRef.Name)
+			var cm corev1.ConfigMap
+			err = ir.client.Get(ctx, crclient.ObjectKey{Namespace: ir.cfg.Namespace, Name: src.ConfigMapRef.Name}, &cm)
+			data = maps.Clone(cm.BinaryData)
+			if data == nil {
+				data = make(map[string][]byte, len(cm.Data))
+			}
+			for k, v := range cm.Data {
+				data[k] = []byte(v)
+			}
+		case src.SecretRef != nil:
+			fmt.Fprintf(h, "secret/%s\x00", src.SecretRef.Name)
+			var sec corev1.Secret
+			err = ir.client.Get(ctx, crclient.ObjectKey{Namespace: ir.cfg.Namespace, Name: src.SecretRef.Name}, &sec)
+			data = sec.Data
+		}
+		if crclient.IgnoreNotFound(err) != nil {
+			return "", fmt.Errorf("failed to get environment source: %w", err)
+		}
+
+		for _, k := range slices.Sorted(maps.Keys(data)) {
+			fmt.Fprintf(h, "%s=%s\x00", k, data[k])
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// envVarsFromMap returns the environment variables in vars, sorted by
+// name so that the pod template doesn't change (rolling the pods)
+// between reconciles because of the map's random iteration order.
+func envVarsFromMap(vars map[string]string) []corev1.EnvVar {
+	envVars := make([]corev1.EnvVar, 0, len(vars))
+	for _, k := range slices.Sorted(maps.Keys(vars)) {
+		envVars = append(envVars, corev1.EnvVar{Name: k, Value: vars[k]})
+	}
+	return envVars
+}
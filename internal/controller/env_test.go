@@ -0,0 +1,108 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestEnvVarsFromMap(t *testing.T) {
+	vars := map[string]string{"TARGET": "http://web", "BIND": ":8080", "DIFFICULTY": "4", "COOKIE_DOMAIN": "example.com"}
+	want := []corev1.EnvVar{
+		{Name: "BIND", Value: ":8080"},
+		{Name: "COOKIE_DOMAIN", Value: "example.com"},
+		{Name: "DIFFICULTY", Value: "4"},
+		{Name: "TARGET", Value: "http://web"},
+	}
+
+	// Map iteration order is random, so a single run could pass by
+	// chance.
+	for range 20 {
+		if diff := cmp.Diff(want, envVarsFromMap(vars)); diff != "" {
+			t.Fatalf("envVarsFromMap() mismatch (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestReconcileEnvChecksum(t *testing.T) {
+	env := func(name, value string) crclient.Object {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ingress-anubis"},
+			Data:       map[string]string{"WEBMASTER_EMAIL": value},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		objs        []crclient.Object
+		update      crclient.Object
+		wantChanged bool
+	}{
+		{
+			name:        "should roll the pods when the environment changes",
+			objs:        []crclient.Object{env("anubis-env", "a@example.com"), env("other", "a@example.com")},
+			update:      env("anubis-env", "b@example.com"),
+			wantChanged: true,
+		},
+		{
+			name:   "should not roll the pods when other configmaps change",
+			objs:   []crclient.Object{env("anubis-env", "a@example.com"), env("other", "a@example.com")},
+			update: env("other", "b@example.com"),
+		},
+		{
+			name:        "should roll the pods when the environment is created",
+			objs:        []crclient.Object{env("other", "a@example.com")},
+			update:      env("anubis-env", "a@example.com"),
+			wantChanged: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, map[string]string{"NAMESPACE": "ingress-anubis", "ENV_FROM_CM": "anubis-env"})
+			ing := testIngress(cfg, nil)
+			ir := newTestReconciler(t, cfg, append(tt.objs, ing)...)
+			reconcileTestIngress(t, ir, crclient.ObjectKeyFromObject(ing))
+			before := testDeployment(t, ir, "ia-web-82b3ade9").Spec.Template.Annotations[EnvChecksumAnnotation]
+			if before == "" {
+				t.Fatalf("pod template is missing the %s annotation", EnvChecksumAnnotation)
+			}
+
+			var err error
+			if slices.ContainsFunc(tt.objs, func(obj crclient.Object) bool { return obj.GetName() == tt.update.GetName() }) {
+				err = ir.client.Update(t.Context(), tt.update)
+			} else {
+				err = ir.client.Create(t.Context(), tt.update)
+			}
+			if err != nil {
+				t.Fatalf("failed to update configmap: %v", err)
+			}
+			reconcileTestIngress(t, ir, crclient.ObjectKeyFromObject(ing))
+
+			after := testDeployment(t, ir, "ia-web-82b3ade9").Spec.Template.Annotations[EnvChecksumAnnotation]
+			if changed := before != after; changed != tt.wantChanged {
+				t.Errorf("checksum changed = %v, want %v", changed, tt.wantChanged)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	"go.rgst.io/jaredallard/slogext/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// garbageCollector periodically deletes the resources created for
+// ingresses that no longer exist. These are normally removed by our
+// finalizer, but are leaked when it's removed by hand (e.g., while the
+// controller is down).
+type garbageCollector struct {
+	log    slogext.Logger
+	cfg    *config.Config
+	reader crclient.Reader
+	client crclient.Client
+}
+
+// newGarbageCollector creates a new garbageCollector for a cluster. The
+// provided reader should not be cached, so that ingresses missing from
+// a stale cache aren't mistaken as deleted.
+func newGarbageCollector(log slogext.Logger, cfg *config.Config, reader crclient.Reader,
+	client crclient.Client) *garbageCollector {
+	return &garbageCollector{log, cfg, reader, client}
+}
+
+// collect deletes all managed resources whose owning ingress doesn't
+// exist.
+func (gc *garbageCollector) collect(ctx context.Context) error {
+	for list, opts := range map[crclient.ObjectList][]crclient.ListOption{
+		&appsv1.DeploymentList{}:            {crclient.InNamespace(gc.cfg.Namespace)},
+		&networkingv1.IngressList{}:         {crclient.InNamespace(gc.cfg.Namespace)},
+		&corev1.ConfigMapList{}:             {crclient.InNamespace(gc.cfg.Namespace)},
+		&policyv1.PodDisruptionBudgetList{}: {crclient.InNamespace(gc.cfg.Namespace)},
+		&networkingv1.NetworkPolicyList{}:   {crclient.InNamespace(gc.cfg.Namespace)},
+		&corev1.SecretList{}:                {crclient.InNamespace(gc.cfg.Namespace)},
+		// Services created for in-place interposition live in the
+		// namespace of their ingress.
+		&corev1.ServiceList{}: nil,
+	} {
+		opts = append(opts, crclient.MatchingLabels{ManagedLabel: "true"})
+		if err := gc.client.List(ctx, list, opts...); err != nil {
+			return fmt.Errorf("failed to list managed resources: %w", err)
+		}
+
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			obj, ok := item.(crclient.Object)
+			if !ok {
+				continue
+			}
+
+			if err := gc.collectObject(ctx, obj); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// collectObject deletes the provided managed resource if its owning
+// ingress doesn't exist.
+func (gc *garbageCollector) collectObject(ctx context.Context, obj crclient.Object) error {
+	owner, ok := getOwner(obj.GetLabels())
+	if !ok {
+		return nil
+	}
+
+	err := gc.reader.Get(ctx, owner, &networkingv1.Ingress{})
+	if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	gvk, err := apiutil.GVKForObject(obj, gc.client.Scheme())
+	if err != nil {
+		return err
+	}
+
+	gc.log.Info("deleting orphaned resource", slog.String("kind", gvk.Kind),
+		slog.String("namespace", obj.GetNamespace()), slog.String("name", obj.GetName()),
+		slog.String("owner", owner.String()))
+	if err := gc.client.Delete(ctx, obj); crclient.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete orphaned resource %s: %w", obj.GetName(), err)
+	}
+	orphanedResourcesDeleted.WithLabelValues(gvk.Kind).Inc()
+
+	return nil
+}
+
+// Start implements [manager.Runnable].
+func (gc *garbageCollector) Start(ctx context.Context) error {
+	t := time.NewTicker(gc.cfg.GarbageCollectionInterval)
+	defer t.Stop()
+
+	for {
+		if err := gc.collect(ctx); err != nil {
+			gc.log.Error("failed to collect orphaned resources", slog.String("err", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+	}
+}
+
+// NeedLeaderElection implements [manager.LeaderElectionRunnable].
+func (gc *garbageCollector) NeedLeaderElection() bool {
+	return true
+}
@@ -0,0 +1,95 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"testing"
+
+	"go.rgst.io/jaredallard/slogext/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGarbageCollectorCollect(t *testing.T) {
+	owner := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+	meta := func(namespace string, labels map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: "ia-web", Namespace: namespace, Labels: labels}
+	}
+	managed := func(ns, name string) map[string]string {
+		return map[string]string{ManagedLabel: "true", OwnerNamespaceLabel: ns, OwnerNameLabel: name}
+	}
+
+	tests := []struct {
+		name        string
+		obj         crclient.Object
+		wantDeleted bool
+	}{
+		{
+			name: "should keep resources of existing ingresses",
+			obj:  &appsv1.Deployment{ObjectMeta: meta("ingress-anubis", managed("default", "web"))},
+		},
+		{
+			name:        "should delete resources of deleted ingresses",
+			obj:         &appsv1.Deployment{ObjectMeta: meta("ingress-anubis", managed("default", "api"))},
+			wantDeleted: true,
+		},
+		{
+			name:        "should delete resources of deleted ingresses with legacy labels",
+			obj:         &corev1.ConfigMap{ObjectMeta: meta("ingress-anubis", map[string]string{ManagedLabel: "true", OwningLabel: "default--api"})},
+			wantDeleted: true,
+		},
+		{
+			name:        "should delete in-place services of deleted ingresses",
+			obj:         &corev1.Service{ObjectMeta: meta("default", managed("default", "api"))},
+			wantDeleted: true,
+		},
+		{
+			name: "should keep unmanaged resources",
+			obj:  &appsv1.Deployment{ObjectMeta: meta("ingress-anubis", map[string]string{OwnerNamespaceLabel: "default", OwnerNameLabel: "api"})},
+		},
+		{
+			name: "should keep resources without an owner",
+			obj:  &appsv1.Deployment{ObjectMeta: meta("ingress-anubis", map[string]string{ManagedLabel: "true"})},
+		},
+		{
+			name: "should keep resources outside of the controller namespace",
+			obj:  &corev1.ConfigMap{ObjectMeta: meta("default", managed("default", "api"))},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, map[string]string{"NAMESPACE": "ingress-anubis"})
+			client := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(owner, tt.obj).Build()
+			gc := newGarbageCollector(slogext.New(), cfg, client, client)
+			if err := gc.collect(t.Context()); err != nil {
+				t.Fatalf("collect() error = %v", err)
+			}
+
+			err := client.Get(t.Context(), crclient.ObjectKeyFromObject(tt.obj), tt.obj)
+			if deleted := apierrors.IsNotFound(err); deleted != tt.wantDeleted {
+				t.Errorf("deleted = %v, want %v (err = %v)", deleted, tt.wantDeleted, err)
+			}
+		})
+	}
+}
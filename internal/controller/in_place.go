@@ -0,0 +1,330 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/json"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// OriginalBackendsAnnotation is the annotation used to stash the
+// original backends of an ingress that was rewritten by
+// [config.InterpositionInPlace].
+const OriginalBackendsAnnotation = "ingress-anubis.jaredallard.github.com/original-backends"
+
+// originalBackends contains the backends of an ingress before they were
+// rewritten to point at Anubis.
+type originalBackends struct {
+	// DefaultBackend is the original default backend, if any.
+	DefaultBackend *networkingv1.IngressBackend `json:"defaultBackend,omitempty"`
+
+	// Paths contains the original backends of each path, indexed by rule
+	// and then by path.
+	Paths [][]networkingv1.IngressBackend `json:"paths,omitempty"`
+
+	// Services contains the names of the services the backends were
+	// rewritten to point at.
+	Services []string `json:"services,omitempty"`
+}
+
+// getOriginalBackends returns the backends stashed on the provided
+// ingress, or nil if there are none.
+func getOriginalBackends(ing *networkingv1.Ingress) (*originalBackends, error) {
+	v, ok := ing.Annotations[OriginalBackendsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	var ob originalBackends
+	if err := json.Unmarshal([]byte(v), &ob); err != nil {
+		return nil, fmt.Errorf("failed to parse annotation %s: %w", OriginalBackendsAnnotation, err)
+	}
+
+	return &ob, nil
+}
+
+// isServiceBackend returns true if the provided backend points at one
+// of the services with the provided names.
+func isServiceBackend(b *networkingv1.IngressBackend, names ...string) bool {
+	return b != nil && b.Service != nil && slices.Contains(names, b.Service.Name)
+}
+
+// collectBackends returns the backends of the provided spec. Backends
+// pointing at one of the services svcNames or prev.Services (i.e.,
+// already rewritten) are taken from prev, when possible, instead.
+func collectBackends(spec *networkingv1.IngressSpec, prev *originalBackends, svcNames ...string) *originalBackends {
+	if prev == nil {
+		prev = &originalBackends{}
+	}
+	svcNames = slices.Concat(svcNames, prev.Services)
+
+	ob := &originalBackends{}
+	if spec.DefaultBackend != nil {
+		ob.DefaultBackend = spec.DefaultBackend.DeepCopy()
+		if isServiceBackend(ob.DefaultBackend, svcNames...) && prev.DefaultBackend != nil {
+			ob.DefaultBackend = prev.DefaultBackend.DeepCopy()
+		}
+	}
+
+	ob.Paths = make([][]networkingv1.IngressBackend, len(spec.Rules))
+	for i, r := range spec.Rules {
+		if r.HTTP == nil {
+			continue
+		}
+
+		for j := range r.HTTP.Paths {
+			b := *r.HTTP.Paths[j].Backend.DeepCopy()
+			if isServiceBackend(&b, svcNames...) && i < len(prev.Paths) && j < len(prev.Paths[i]) {
+				b = *prev.Paths[i][j].DeepCopy()
+			}
+			ob.Paths[i] = append(ob.Paths[i], b)
+		}
+	}
+
+	return ob
+}
+
+// applyBackends sets the backends of the provided spec to those in ob.
+// Backends that no longer line up with the spec are left as-is.
+func applyBackends(spec *networkingv1.IngressSpec, ob *originalBackends) {
+	if spec.DefaultBackend != nil && ob.DefaultBackend != nil {
+		spec.DefaultBackend = ob.DefaultBackend.DeepCopy()
+	}
+
+	for i, r := range spec.Rules {
+		if r.HTTP == nil || i >= len(ob.Paths) {
+			continue
+		}
+
+		for j := range r.HTTP.Paths {
+			if j >= len(ob.Paths[i]) {
+				continue
+			}
+			spec.Rules[i].HTTP.Paths[j].Backend = *ob.Paths[i][j].DeepCopy()
+		}
+	}
+}
+
+// getOriginalSpec returns the spec of the provided ingress as it was
+// before its backends were rewritten to point at one of the services
+// svcNames by [config.InterpositionInPlace]. If it hasn't been
+// rewritten, a copy of the spec is returned as-is.
+func getOriginalSpec(ing *networkingv1.Ingress, svcNames ...string) (*networkingv1.IngressSpec, error) {
+	spec := ing.Spec.DeepCopy()
+
+	ob, err := getOriginalBackends(ing)
+	if err != nil {
+		return nil, err
+	}
+	if ob != nil {
+		applyBackends(spec, collectBackends(spec, ob, svcNames...))
+	}
+
+	return spec, nil
+}
+
+// reconcileInPlace rewrites the backends of the provided ingress to
+// point at the Anubis instance of the matching backend in backends,
+// stashing the original backends in [OriginalBackendsAnnotation]. Since
+// services can't be referenced across namespaces, an ExternalName
+// service pointing at each Anubis service is created in the ingress'
+// namespace.
+func (ir *IngressReconciler) reconcileInPlace(ctx context.Context, ing *networkingv1.Ingress,
+	icfg *config.IngressConfig, backends []*anubisBackend, req reconcile.Request) error {
+	names := make([]string, 0, len(backends))
+	for _, b := range backends {
+		if err := ir.reconcileInPlaceService(ctx, b.name, *icfg.Port, req); err != nil {
+			return err
+		}
+		names = append(names, b.name)
+	}
+
+	prev, err := getOriginalBackends(ing)
+	if err != nil {
+		return err
+	}
+
+	orig := ing.DeepCopy()
+	ob := collectBackends(&ing.Spec, prev, ir.inPlaceServiceNames(req)...)
+	ob.Services = names
+	stash, err := json.Marshal(ob)
+	if err != nil {
+		return fmt.Errorf("failed to encode original backends: %w", err)
+	}
+
+	if ing.Annotations == nil {
+		ing.Annotations = make(map[string]string)
+	}
+	ing.Annotations[OriginalBackendsAnnotation] = string(stash)
+	applyBackends(&ing.Spec, ob)
+	setServiceBackends(&ing.Spec, func(isb *networkingv1.IngressServiceBackend) *networkingv1.IngressServiceBackend {
+		if b := findBackend(backends, isb); b != nil {
+			return &networkingv1.IngressServiceBackend{Name: b.name, Port: networkingv1.ServiceBackendPort{Name: "http"}}
+		}
+		return isb
+	})
+
+	if !equality.Semantic.DeepEqual(orig, ing) {
+		if err := ir.client.Patch(ctx, ing, crclient.StrategicMergeFrom(orig)); err != nil {
+			return fmt.Errorf("failed to rewrite ingress backends: %w", err)
+		}
+	}
+
+	// Now that nothing points at them anymore, remove the services of
+	// backends that were removed and the one created under the legacy
+	// name, if any.
+	return ir.deleteInPlaceServices(ctx, req, names...)
+}
+
+// reconcileInPlaceService ensures that the ExternalName service with
+// the provided name, pointing at the Anubis service of the same name
+// (exposed on port), exists in the namespace of the ingress in req.
+func (ir *IngressReconciler) reconcileInPlaceService(ctx context.Context, name string, port uint32,
+	req reconcile.Request) error {
+	serv := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: req.Namespace,
+		},
+	}
+
+	// Don't take over a service that we didn't create.
+	var existing corev1.Service
+	if err := ir.client.Get(ctx, crclient.ObjectKeyFromObject(serv), &existing); err == nil {
+		if existing.Labels[ManagedLabel] != "true" {
+			return degraded("ServiceConflict", fmt.Errorf("service %s/%s already exists and is not managed by us", req.Namespace, name))
+		}
+	} else if err := crclient.IgnoreNotFound(err); err != nil {
+		return fmt.Errorf("failed to check existence of service: %w", err)
+	}
+
+	labels := map[string]string{
+		ManagedLabel:        "true",
+		OwnerNamespaceLabel: req.Namespace,
+		OwnerNameLabel:      req.Name,
+	}
+
+	if _, err := ir.apply(ctx, serv, func() error {
+		serv.Labels = labels
+		serv.Spec.Type = corev1.ServiceTypeExternalName
+		serv.Spec.ExternalName = fmt.Sprintf("%s.%s.svc.cluster.local", name, ir.cfg.Namespace)
+		//nolint:gosec // Why: Not a possible overflow.
+		port := int32(port)
+		serv.Spec.Ports = []corev1.ServicePort{{
+			Name:       "http",
+			Port:       port,
+			Protocol:   corev1.ProtocolTCP,
+			TargetPort: intstr.FromInt32(port),
+		}}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to reconcile service: %w", err)
+	}
+
+	return nil
+}
+
+// restoreInPlace reverts the changes made by
+// [IngressReconciler.reconcileInPlace], if any.
+func (ir *IngressReconciler) restoreInPlace(ctx context.Context, ing *networkingv1.Ingress, req reconcile.Request) error {
+	ob, err := getOriginalBackends(ing)
+	if err != nil {
+		return err
+	}
+
+	if ob != nil {
+		patch := crclient.StrategicMergeFrom(ing.DeepCopy())
+		applyBackends(&ing.Spec, collectBackends(&ing.Spec, ob, ir.inPlaceServiceNames(req)...))
+		delete(ing.Annotations, OriginalBackendsAnnotation)
+		if err := ir.client.Patch(ctx, ing, patch); err != nil {
+			return fmt.Errorf("failed to restore ingress backends: %w", err)
+		}
+	}
+
+	return ir.deleteInPlaceServices(ctx, req)
+}
+
+// inPlaceServiceNames returns the names of the services that
+// [IngressReconciler.reconcileInPlace] pointed ingresses at before the
+// names were stashed in [originalBackends.Services], current and legacy
+// (see [IngressReconciler.legacyResourceName]).
+func (ir *IngressReconciler) inPlaceServiceNames(req reconcile.Request) []string {
+	return []string{ir.resourceName(req.NamespacedName), ir.legacyResourceName(req.Name)}
+}
+
+// deleteInPlaceServices deletes the ExternalName services created by
+// [IngressReconciler.reconcileInPlace] for the ingress in req, except
+// those with one of the provided names.
+func (ir *IngressReconciler) deleteInPlaceServices(ctx context.Context, req reconcile.Request, keep ...string) error {
+	// In-place interposition isn't supported in the controller namespace,
+	// where the services owned by the ingress are the anubis ones.
+	if req.Namespace == ir.cfg.Namespace {
+		return nil
+	}
+
+	var svcs corev1.ServiceList
+	if err := ir.client.List(ctx, &svcs, crclient.InNamespace(req.Namespace),
+		crclient.MatchingFields{ownerIndex: req.String()}); err != nil {
+		return fmt.Errorf("failed to list in-place services: %w", err)
+	}
+
+	for i := range svcs.Items {
+		svc := &svcs.Items[i]
+		if slices.Contains(keep, svc.Name) {
+			continue
+		}
+
+		if err := ir.client.Delete(ctx, svc); crclient.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete in-place service: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// setServiceBackends replaces all service backends in the provided spec
+// with the result of calling f with them. Resource backends are left
+// as-is, since anubis can't proxy to them.
+func setServiceBackends(spec *networkingv1.IngressSpec,
+	f func(*networkingv1.IngressServiceBackend) *networkingv1.IngressServiceBackend) {
+	if spec.DefaultBackend != nil && spec.DefaultBackend.Service != nil {
+		spec.DefaultBackend.Service = f(spec.DefaultBackend.Service)
+	}
+	for i, r := range spec.Rules {
+		if r.HTTP == nil {
+			continue // TODO(jaredallard): Validate this case.
+		}
+		for j := range r.HTTP.Paths {
+			if b := &spec.Rules[i].HTTP.Paths[j].Backend; b.Service != nil {
+				b.Service = f(b.Service)
+			}
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// serviceBackend returns an ingress backend pointing at the named port
+// "http" of the provided service.
+func serviceBackend(name string) networkingv1.IngressBackend {
+	return networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+		Name: name, Port: networkingv1.ServiceBackendPort{Name: "http"},
+	}}
+}
+
+// specWithBackends returns an ingress spec with the provided default
+// backend (if not nil) and a rule with a path for each of paths.
+func specWithBackends(def *networkingv1.IngressBackend, paths ...networkingv1.IngressBackend) *networkingv1.IngressSpec {
+	spec := &networkingv1.IngressSpec{DefaultBackend: def}
+	rule := networkingv1.IngressRule{Host: "example.com", IngressRuleValue: networkingv1.IngressRuleValue{
+		HTTP: &networkingv1.HTTPIngressRuleValue{},
+	}}
+	for _, b := range paths {
+		rule.HTTP.Paths = append(rule.HTTP.Paths, networkingv1.HTTPIngressPath{Path: "/", Backend: b})
+	}
+	spec.Rules = append(spec.Rules, rule, networkingv1.IngressRule{Host: "no-http.example.com"})
+	return spec
+}
+
+func TestCollectBackends(t *testing.T) {
+	web, api, anubis := serviceBackend("web"), serviceBackend("api"), serviceBackend("anubis-web")
+	original := &originalBackends{
+		DefaultBackend: &web,
+		Paths:          [][]networkingv1.IngressBackend{{web, api}, nil},
+		Services:       []string{"anubis-web"},
+	}
+
+	tests := []struct {
+		name     string
+		spec     *networkingv1.IngressSpec
+		prev     *originalBackends
+		svcNames []string
+		want     *originalBackends
+	}{
+		{
+			name: "should collect the backends of a spec that wasn't rewritten",
+			spec: specWithBackends(&web, web, api),
+			want: &originalBackends{DefaultBackend: &web, Paths: [][]networkingv1.IngressBackend{{web, api}, nil}},
+		},
+		{
+			name: "should take rewritten backends from the previous ones",
+			spec: specWithBackends(&anubis, anubis, anubis),
+			prev: original,
+			want: &originalBackends{DefaultBackend: &web, Paths: [][]networkingv1.IngressBackend{{web, api}, nil}},
+		},
+		{
+			name:     "should take backends pointing at svcNames from the previous ones",
+			spec:     specWithBackends(nil, serviceBackend("legacy"), api),
+			prev:     &originalBackends{Paths: [][]networkingv1.IngressBackend{{web}}},
+			svcNames: []string{"legacy"},
+			want:     &originalBackends{Paths: [][]networkingv1.IngressBackend{{web, api}, nil}},
+		},
+		{
+			name: "should keep backends changed since they were rewritten",
+			spec: specWithBackends(&api, api, anubis, anubis),
+			prev: original,
+			want: &originalBackends{DefaultBackend: &api, Paths: [][]networkingv1.IngressBackend{{api, api, anubis}, nil}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := collectBackends(tt.spec, tt.prev, tt.svcNames...)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("collectBackends() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestApplyBackends(t *testing.T) {
+	web, api, anubis := serviceBackend("web"), serviceBackend("api"), serviceBackend("anubis-web")
+
+	spec := specWithBackends(&anubis, anubis, anubis)
+	applyBackends(spec, &originalBackends{DefaultBackend: &web, Paths: [][]networkingv1.IngressBackend{{api}}})
+
+	// The second path no longer lines up with the original backends, so
+	// it's left as-is.
+	if diff := cmp.Diff(specWithBackends(&web, api, anubis), spec); diff != "" {
+		t.Errorf("applyBackends() mismatch (-want +got):\n%s", diff)
+	}
+
+	// Default backends are only restored when the spec still has one.
+	spec = specWithBackends(nil, anubis)
+	applyBackends(spec, &originalBackends{DefaultBackend: &web})
+	if diff := cmp.Diff(specWithBackends(nil, anubis), spec); diff != "" {
+		t.Errorf("applyBackends() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGetOriginalSpec(t *testing.T) {
+	web, api, anubis := serviceBackend("web"), serviceBackend("api"), serviceBackend("anubis-web")
+
+	stash, err := json.Marshal(&originalBackends{
+		DefaultBackend: &web,
+		Paths:          [][]networkingv1.IngressBackend{{web, api}},
+		Services:       []string{"anubis-web"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		spec        *networkingv1.IngressSpec
+		want        *networkingv1.IngressSpec
+		wantErr     bool
+	}{
+		{
+			name: "should return ingresses that weren't rewritten as-is",
+			spec: specWithBackends(&web, web, api),
+			want: specWithBackends(&web, web, api),
+		},
+		{
+			name:        "should restore the backends of rewritten ingresses",
+			annotations: map[string]string{OriginalBackendsAnnotation: string(stash)},
+			spec:        specWithBackends(&anubis, anubis, anubis),
+			want:        specWithBackends(&web, web, api),
+		},
+		{
+			name:        "should fail when the stashed backends are invalid",
+			annotations: map[string]string{OriginalBackendsAnnotation: "{"},
+			spec:        specWithBackends(&anubis, anubis),
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ing := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}, Spec: *tt.spec}
+			got, err := getOriginalSpec(ing)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getOriginalSpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("getOriginalSpec() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(*tt.spec, ing.Spec); diff != "" {
+				t.Errorf("getOriginalSpec() modified the ingress (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
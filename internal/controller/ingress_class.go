@@ -0,0 +1,244 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jaredallard/ingress-anubis/internal/api/v1alpha1"
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	"go.rgst.io/jaredallard/slogext/v2"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// hasIngressClass returns true if the provided ingress uses one of the
+// ingress classes handled by this controller, i.e., one of
+// [config.Config.IngressClassNames] whose controller is
+// [config.Config.ControllerName]. Ingress classes that don't exist (yet)
+// aren't ours.
+func (ir *IngressReconciler) hasIngressClass(ctx context.Context, ing *networkingv1.Ingress) (bool, error) {
+	if ing.Spec.IngressClassName == nil || !slices.Contains(ir.cfg.IngressClassNames, *ing.Spec.IngressClassName) {
+		return false, nil
+	}
+
+	var ic networkingv1.IngressClass
+	if err := ir.client.Get(ctx, crclient.ObjectKey{Name: *ing.Spec.IngressClassName}, &ic); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get ingress class %q: %w", *ing.Spec.IngressClassName, err)
+	}
+	return ic.Spec.Controller == ir.cfg.ControllerName, nil
+}
+
+// ingressClassRequests returns a [handler.TypedMapFunc] mapping an
+// ingress class to the ingresses using it, so that they're picked up
+// (or released) when it's created or deleted.
+func ingressClassRequests[T crclient.Object](r crclient.Reader) handler.TypedMapFunc[T, reconcile.Request] {
+	return func(ctx context.Context, obj T) []reconcile.Request {
+		var ings networkingv1.IngressList
+		if err := r.List(ctx, &ings); err != nil {
+			return nil
+		}
+
+		var reqs []reconcile.Request
+		for i := range ings.Items {
+			ing := &ings.Items[i]
+			if ing.Spec.IngressClassName != nil && *ing.Spec.IngressClassName == obj.GetName() {
+				reqs = append(reqs, reconcile.Request{NamespacedName: crclient.ObjectKeyFromObject(ing)})
+			}
+		}
+		return reqs
+	}
+}
+
+// getClassParams returns the annotations (without
+// [config.AnnotationKeyBase]) set by the [v1alpha1.AnubisIngressClassParams]
+// referenced by the ingress class of the provided ingress, if any. See
+// [config.Config.IngressClassParams].
+func getClassParams(ctx context.Context, r crclient.Reader, cfg *config.Config,
+	ing *networkingv1.Ingress) (map[string]string, error) {
+	if !cfg.IngressClassParams || ing.Spec.IngressClassName == nil {
+		return nil, nil
+	}
+
+	var ic networkingv1.IngressClass
+	if err := r.Get(ctx, crclient.ObjectKey{Name: *ing.Spec.IngressClassName}, &ic); err != nil {
+		return nil, crclient.IgnoreNotFound(err)
+	}
+
+	ref := ic.Spec.Parameters
+	if !isClassParamsRef(ref) {
+		return nil, nil
+	}
+	if ptr.Deref(ref.Scope, networkingv1.IngressClassParametersReferenceScopeCluster) !=
+		networkingv1.IngressClassParametersReferenceScopeCluster {
+		return nil, fmt.Errorf("ingress class %q references namespaced parameters, but %s are cluster scoped",
+			ic.Name, v1alpha1.AnubisIngressClassParamsKind)
+	}
+
+	var params v1alpha1.AnubisIngressClassParams
+	if err := r.Get(ctx, crclient.ObjectKey{Name: ref.Name}, &params); err != nil {
+		return nil, fmt.Errorf("failed to get parameters %q of ingress class %q: %w", ref.Name, ic.Name, err)
+	}
+
+	annotations := maps.Clone(params.Spec.Annotations)
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	key := func(k config.AnnotationKey) string {
+		return strings.TrimPrefix(k.String(), config.AnnotationKeyBase)
+	}
+	if params.Spec.Difficulty != nil {
+		annotations[key(config.AnnotationKeyDifficulty)] = strconv.Itoa(*params.Spec.Difficulty)
+	}
+	if params.Spec.AnubisImage != nil {
+		annotations[key(config.AnnotationKeyAnubisImage)] = *params.Spec.AnubisImage
+	}
+	if params.Spec.Resources != nil {
+		b, err := json.Marshal(params.Spec.Resources)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode resources of parameters %q: %w", ref.Name, err)
+		}
+		annotations[key(config.AnnotationKeyResources)] = string(b)
+	}
+	if params.Spec.IngressClass != nil {
+		annotations[key(config.AnnotationKeyIngressClass)] = *params.Spec.IngressClass
+	}
+	return annotations, nil
+}
+
+// isClassParamsRef returns true if the provided ingress class parameters
+// reference is to [v1alpha1.AnubisIngressClassParams].
+func isClassParamsRef(ref *networkingv1.IngressClassParametersReference) bool {
+	return ref != nil && ptr.Deref(ref.APIGroup, "") == v1alpha1.GroupVersion.Group &&
+		ref.Kind == v1alpha1.AnubisIngressClassParamsKind
+}
+
+// classParamsRequests returns a [handler.TypedMapFunc] mapping
+// [v1alpha1.AnubisIngressClassParams] to the ingresses of the ingress
+// classes referencing them, so that changes to them are applied right
+// away.
+func classParamsRequests[T crclient.Object](r crclient.Reader) handler.TypedMapFunc[T, reconcile.Request] {
+	return func(ctx context.Context, obj T) []reconcile.Request {
+		var ics networkingv1.IngressClassList
+		if err := r.List(ctx, &ics); err != nil {
+			return nil
+		}
+
+		var reqs []reconcile.Request
+		for i := range ics.Items {
+			ic := &ics.Items[i]
+			if isClassParamsRef(ic.Spec.Parameters) && ic.Spec.Parameters.Name == obj.GetName() {
+				reqs = append(reqs, ingressClassRequests[*networkingv1.IngressClass](r)(ctx, ic)...)
+			}
+		}
+		return reqs
+	}
+}
+
+// ingressClassManager creates the ingress classes handled by the
+// controller, [config.Config.IngressClassNames], when they don't exist.
+// Existing ingress classes are never modified, as their controller is
+// immutable.
+type ingressClassManager struct {
+	log    slogext.Logger
+	cfg    *config.Config
+	client crclient.Client
+}
+
+// newIngressClassManager creates a new ingressClassManager for a
+// cluster.
+func newIngressClassManager(log slogext.Logger, cfg *config.Config, client crclient.Client) *ingressClassManager {
+	return &ingressClassManager{log, cfg, client}
+}
+
+// ensure creates all missing ingress classes, returning an error for
+// those that exist with another controller.
+func (m *ingressClassManager) ensure(ctx context.Context) error {
+	var errs []error
+	for _, name := range m.cfg.IngressClassNames {
+		var ic networkingv1.IngressClass
+		err := m.client.Get(ctx, crclient.ObjectKey{Name: name}, &ic)
+		switch {
+		case apierrors.IsNotFound(err):
+			ic = networkingv1.IngressClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   name,
+					Labels: map[string]string{ManagedLabel: "true"},
+				},
+				Spec: networkingv1.IngressClassSpec{Controller: m.cfg.ControllerName},
+			}
+			if err := m.client.Create(ctx, &ic); crclient.IgnoreAlreadyExists(err) != nil {
+				errs = append(errs, fmt.Errorf("failed to create ingress class %q: %w", name, err))
+				continue
+			}
+			m.log.Info("created ingress class", slog.String("name", name), slog.String("controller", m.cfg.ControllerName))
+		case err != nil:
+			errs = append(errs, fmt.Errorf("failed to get ingress class %q: %w", name, err))
+		case ic.Spec.Controller != m.cfg.ControllerName:
+			errs = append(errs, fmt.Errorf("ingress class %q belongs to controller %q instead of %q, its ingresses will not be managed",
+				name, ic.Spec.Controller, m.cfg.ControllerName))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Start implements [manager.Runnable].
+func (m *ingressClassManager) Start(ctx context.Context) error {
+	t := time.NewTicker(m.cfg.PreflightInterval)
+	defer t.Stop()
+
+	var prevErr error
+	for {
+		// Only log errors when they change to avoid repeating them every
+		// interval.
+		err := m.ensure(ctx)
+		if err != nil && (prevErr == nil || err.Error() != prevErr.Error()) {
+			m.log.Error("failed to ensure ingress classes", slog.String("err", err.Error()))
+		}
+		prevErr = err
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+	}
+}
+
+// NeedLeaderElection implements [manager.LeaderElectionRunnable].
+func (m *ingressClassManager) NeedLeaderElection() bool {
+	return true
+}
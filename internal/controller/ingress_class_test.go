@@ -0,0 +1,122 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	"go.rgst.io/jaredallard/slogext/v2"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// ingressClass returns an ingress class with the provided name and
+// controller.
+func ingressClass(name, controller string) *networkingv1.IngressClass {
+	return &networkingv1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       networkingv1.IngressClassSpec{Controller: controller},
+	}
+}
+
+func TestHasIngressClass(t *testing.T) {
+	cfg := &config.Config{IngressClassNames: []string{"anubis", "anubis-strict"}, ControllerName: "example.com/ingress-anubis"}
+	client := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(
+		ingressClass("anubis", cfg.ControllerName),
+		ingressClass("anubis-strict", "example.com/other"),
+		ingressClass("nginx", cfg.ControllerName),
+	).Build()
+	ir := &IngressReconciler{cfg: cfg, client: client}
+
+	tests := []struct {
+		name  string
+		class *string
+		want  bool
+	}{
+		{name: "our ingress class", class: ptr.To("anubis"), want: true},
+		{name: "our ingress class of another controller", class: ptr.To("anubis-strict")},
+		{name: "ingress class not handled by us", class: ptr.To("nginx")},
+		{name: "missing ingress class", class: ptr.To("missing")},
+		{name: "default ingress class"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ir.hasIngressClass(t.Context(), &networkingv1.Ingress{Spec: networkingv1.IngressSpec{IngressClassName: tt.class}})
+			if err != nil {
+				t.Fatalf("hasIngressClass() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("hasIngressClass() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIngressClassManagerEnsure(t *testing.T) {
+	const controller = "example.com/ingress-anubis"
+
+	tests := []struct {
+		name    string
+		objs    []crclient.Object
+		wantErr bool
+
+		// want contains the controller of each ingress class afterwards.
+		want map[string]string
+	}{
+		{
+			name: "should create missing ingress classes",
+			want: map[string]string{"anubis": controller, "anubis-strict": controller},
+		},
+		{
+			name: "should keep existing ingress classes",
+			objs: []crclient.Object{ingressClass("anubis", controller)},
+			want: map[string]string{"anubis": controller, "anubis-strict": controller},
+		},
+		{
+			name:    "should fail for ingress classes of another controller",
+			objs:    []crclient.Object{ingressClass("anubis", "example.com/other")},
+			wantErr: true,
+			want:    map[string]string{"anubis": "example.com/other", "anubis-strict": controller},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{IngressClassNames: []string{"anubis", "anubis-strict"}, ControllerName: controller}
+			client := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(tt.objs...).Build()
+			m := newIngressClassManager(slogext.New(), cfg, client)
+			if err := m.ensure(t.Context()); (err != nil) != tt.wantErr {
+				t.Fatalf("ensure() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			for name, want := range tt.want {
+				var ic networkingv1.IngressClass
+				if err := client.Get(t.Context(), crclient.ObjectKey{Name: name}, &ic); err != nil {
+					t.Fatalf("failed to get ingress class %q: %v", name, err)
+				}
+				if ic.Spec.Controller != want {
+					t.Errorf("controller of ingress class %q = %q, want %q", name, ic.Spec.Controller, want)
+				}
+			}
+		})
+	}
+}
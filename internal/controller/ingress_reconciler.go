@@ -19,25 +19,44 @@ package controller
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log/slog"
 	"maps"
+	"net/url"
+	"path"
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jaredallard/ingress-anubis/internal/config"
+	"github.com/jaredallard/ingress-anubis/internal/translate"
 	"go.rgst.io/jaredallard/slogext/v2"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
-	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/client-go/tools/events"
 	"k8s.io/utils/ptr"
 
 	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -46,99 +65,384 @@ const (
 	// controller.
 	ManagedLabel = "ingress-anubis.jaredallard.github.com/managed"
 
-	// OwningLabel is the label used to store the owning ingress.
+	// OwnerNamespaceLabel and OwnerNameLabel are the labels used to
+	// store the namespace and name of the owning ingress.
+	OwnerNamespaceLabel = "ingress-anubis.jaredallard.github.com/owner-namespace"
+	OwnerNameLabel      = "ingress-anubis.jaredallard.github.com/owner-name"
+
+	// OwningLabel is the label used to store the owning ingress, as
+	// "namespace--name", before [OwnerNamespaceLabel] and
+	// [OwnerNameLabel]. It's only read to handle older resources.
 	OwningLabel = "ingress-anubis.jaredallard.github.com/owner"
 
 	// FinalizerKey is the key to use for ingress-anubis's finalizer.
 	FinalizerKey = "ingress-anubis.jaredallard.github.com/finalizer"
+
+	// FieldManager is the field manager used when applying the resources
+	// created by the controller.
+	FieldManager = "ingress-anubis"
+
+	// nginxMirrorTargetAnnotation is the ingress-nginx annotation used to
+	// mirror requests to Anubis when using [config.ModeShadow].
+	nginxMirrorTargetAnnotation = "nginx.ingress.kubernetes.io/mirror-target"
 )
 
 // IngressReconciler is the main reconciler of the controller. See
 // [IngressReconciler.Reconcile] for more information.
 type IngressReconciler struct {
-	log    slogext.Logger
-	cfg    *config.Config
-	client crclient.Client
+	cluster  string
+	log      slogext.Logger
+	cfg      *config.Config
+	client   crclient.Client
+	recorder events.EventRecorder
+	rollouts *rolloutLimiter
+	images   *imageRollout
+	breaker  *circuitBreaker
+	verifier *routeVerifier
+	notifier *notifier
+	degraded *degradedTracker
+	applied  *appliedVersions
 }
 
-// mirrorStatus mirrors the status from a managed ingress to the owning
-// ingressClass'd ingress
-func (ir *IngressReconciler) mirrorStatus(ctx context.Context, ing *networkingv1.Ingress) (reconcile.Result, error) {
-	targetIngKey, ok := ing.Labels[OwningLabel]
-	if !ok {
-		return reconcile.Result{}, nil
-	}
+// ownerLabels returns the labels identifying the ingress in req as the
+// owner of a resource.
+func ownerLabels(req reconcile.Request) map[string]string {
+	return map[string]string{OwnerNamespaceLabel: req.Namespace, OwnerNameLabel: req.Name}
+}
 
-	// TODO(jaredallard): This probably will break on any namespaces that
-	// have '--' in the name.
-	spl := strings.Split(targetIngKey, "--")
-	if len(spl) != 2 {
-		return reconcile.Result{},
-			fmt.Errorf("failed to determine owner from owning label value %q", targetIngKey)
-	}
+// ownerSelectors returns the label selectors matching resources owned
+// by the ingress in req, including older ones only labeled with
+// [OwningLabel]. Use [getOwner] to check the owner of the matching
+// resources, since [OwningLabel] is ambiguous.
+func ownerSelectors(req reconcile.Request) []crclient.MatchingLabels {
+	return []crclient.MatchingLabels{ownerLabels(req), {OwningLabel: req.Namespace + "--" + req.Name}}
+}
 
-	owningIng := &networkingv1.Ingress{}
-	if err := ir.client.Get(ctx, crclient.ObjectKey{
-		Namespace: spl[0],
-		Name:      spl[1],
-	}, owningIng); err != nil {
-		return reconcile.Result{}, crclient.IgnoreNotFound(err)
+// ownerIndex indexes resources created by the controller by the
+// ingress owning them (see [getOwner]), as "namespace/name".
+const ownerIndex = "ingress-anubis.jaredallard.github.com/owner"
+
+// ownerIndexedTypes are the types of the resources created by the
+// controller that are indexed by [ownerIndex]. Others aren't cached
+// (see [clientOptions]), so they're looked up through [ownerSelectors].
+var ownerIndexedTypes = []crclient.Object{&appsv1.Deployment{}, &corev1.Service{}, &networkingv1.Ingress{}}
+
+// indexOwners registers [ownerIndex] with the provided field indexer.
+func indexOwners(ctx context.Context, indexer crclient.FieldIndexer) error {
+	for _, obj := range ownerIndexedTypes {
+		if err := indexer.IndexField(ctx, obj, ownerIndex, func(obj crclient.Object) []string {
+			owner, ok := getOwner(obj.GetLabels())
+			if !ok || obj.GetLabels()[ManagedLabel] != "true" {
+				return nil
+			}
+			return []string{owner.String()}
+		}); err != nil {
+			return fmt.Errorf("failed to index %T by owner: %w", obj, err)
+		}
 	}
+	return nil
+}
 
-	patch := crclient.StrategicMergeFrom(owningIng.DeepCopy())
-	owningIng.Status = ing.Status
-	if err := ir.client.Status().Patch(ctx, owningIng, patch); err != nil {
-		return reconcile.Result{}, fmt.Errorf("failed to update status: %w", err)
+// getOwner returns the key of the ingress owning a resource with the
+// provided labels, see [ownerLabels].
+func getOwner(labels map[string]string) (crclient.ObjectKey, bool) {
+	if ns, name := labels[OwnerNamespaceLabel], labels[OwnerNameLabel]; ns != "" && name != "" {
+		return crclient.ObjectKey{Namespace: ns, Name: name}, true
 	}
 
-	return reconcile.Result{}, nil
+	// Older resources, which can't be told apart when the namespace or
+	// name contains "--".
+	ns, name, ok := strings.Cut(labels[OwningLabel], "--")
+	if !ok || strings.Contains(name, "--") {
+		return crclient.ObjectKey{}, false
+	}
+	return crclient.ObjectKey{Namespace: ns, Name: name}, true
 }
 
 // Reconcile contains the main logic for reconciling all of the
 // resources that make up the ingress controller. The following logic is
 // documented below:
 //
-// 1. ingressClassName == anubis
+// 1. ingressClassName == anubis (or opted in through an annotation)
 // 2. reconcile deployment
 // 3. reconcile service
 // 4. reconcile ingress (wrapper/child)
 func (ir *IngressReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
 	origIng := &networkingv1.Ingress{}
 	if err := ir.client.Get(ctx, req.NamespacedName, origIng); err != nil {
+		if apierrors.IsNotFound(err) {
+			ir.breaker.forget(req.NamespacedName)
+			ir.notifier.forget(ir.cluster, req.NamespacedName)
+		}
 		return reconcile.Result{}, crclient.IgnoreNotFound(err)
 	}
 
-	// Not controlled by us, only check to see if its a managed ingress
-	// which we do want to handle for status mirroring purposes.
-	if origIng.Spec.IngressClassName == nil || *origIng.Spec.IngressClassName != ir.cfg.IngressClassName {
-		if origIng.Labels[ManagedLabel] == "true" {
-			return ir.mirrorStatus(ctx, origIng)
+	// Managed (child) ingresses are only handled for status mirroring
+	// purposes, see statusReconciler.
+	if origIng.Labels[ManagedLabel] == "true" {
+		return reconcile.Result{}, nil
+	}
+
+	log := ir.log.With(slog.String("name", req.Name), slog.String("namespace", req.Namespace))
+
+	managed, err := ir.isManaged(ctx, origIng)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if !managed {
+		return ir.releaseIngress(ctx, log, origIng, req)
+	}
+
+	res, err := ir.reconcileIngress(ctx, log, origIng, req)
+	if origIng.DeletionTimestamp.IsZero() {
+		if serr := ir.recordState(ctx, origIng, req, err); serr != nil {
+			log.Warn("failed to record reconciliation state", slog.String("err", serr.Error()))
 		}
+	}
+	return ir.observeResult(log, origIng, res, err)
+}
+
+// isManaged returns true if the provided ingress should be wrapped by
+// this controller. This is the case when it's in a watched namespace
+// (see [config.Config.WatchesNamespace]) and shard (see
+// [config.Config.InShard]), matches
+// [config.Config.IngressLabelSelector] and it uses our ingress class or
+// has opted in through [config.AnnotationKeyProtect].
+func (ir *IngressReconciler) isManaged(ctx context.Context, ing *networkingv1.Ingress) (bool, error) {
+	if !ir.cfg.WatchesNamespace(ing.Namespace) || !ir.cfg.InShard(ing.Namespace, ing.Name) {
+		return false, nil
+	}
+
+	//nolint:errcheck // Why: Validated when loading the configuration.
+	if sel, _ := ir.cfg.GetIngressLabelSelector(); !sel.Matches(k8slabels.Set(ing.Labels)) {
+		return false, nil
+	}
+
+	if ok, err := ir.hasIngressClass(ctx, ing); ok || err != nil {
+		return ok, err
+	}
+
+	protect, err := strconv.ParseBool(ing.Annotations[config.AnnotationKeyProtect.String()])
+	return err == nil && protect, nil
+}
+
+// ingressSelectorPredicate returns a predicate filtering out events for
+// ingresses not matching [config.Config.IngressLabelSelector]. Updates
+// are let through when either version matches, so that ingresses that
+// stop matching are released, as are our own (child) ingresses.
+func ingressSelectorPredicate[T crclient.Object](cfg *config.Config) predicate.TypedPredicate[T] {
+	//nolint:errcheck // Why: Validated when loading the configuration.
+	sel, _ := cfg.GetIngressLabelSelector()
+	matches := func(obj T) bool {
+		return obj.GetLabels()[ManagedLabel] == "true" || sel.Matches(k8slabels.Set(obj.GetLabels()))
+	}
+
+	return predicate.TypedFuncs[T]{
+		CreateFunc:  func(e event.TypedCreateEvent[T]) bool { return matches(e.Object) },
+		UpdateFunc:  func(e event.TypedUpdateEvent[T]) bool { return matches(e.ObjectOld) || matches(e.ObjectNew) },
+		DeleteFunc:  func(e event.TypedDeleteEvent[T]) bool { return matches(e.Object) },
+		GenericFunc: func(e event.TypedGenericEvent[T]) bool { return matches(e.Object) },
+	}
+}
+
+// ownerRequests returns a [handler.TypedMapFunc] mapping resources
+// created by the controller back to the ingress owning them (see
+// [getOwner]), so that manual changes to them (or their deletion)
+// are reverted right away.
+func ownerRequests[T crclient.Object](cfg *config.Config) handler.TypedMapFunc[T, reconcile.Request] {
+	return func(_ context.Context, obj T) []reconcile.Request {
+		if obj.GetNamespace() != cfg.Namespace || obj.GetLabels()[ManagedLabel] != "true" {
+			return nil
+		}
+
+		owner, ok := getOwner(obj.GetLabels())
+		if !ok {
+			return nil
+		}
+		return []reconcile.Request{{NamespacedName: owner}}
+	}
+}
+
+// specChangedPredicate returns a predicate filtering out updates that
+// only change the status of a resource, which we don't manage.
+func specChangedPredicate[T crclient.Object]() predicate.TypedPredicate[T] {
+	return predicate.Or[T](
+		predicate.TypedGenerationChangedPredicate[T]{},
+		predicate.TypedLabelChangedPredicate[T]{},
+		predicate.TypedAnnotationChangedPredicate[T]{},
+	)
+}
+
+// ingressChangedPredicate returns a predicate filtering out updates to
+// ingresses that only change their status, which follow every
+// reconciliation of the ingresses we wrap (see
+// [IngressReconciler.mirrorStatus]), or their state (see
+// [stateChangedPredicate]). Resyncs (see [config.Config.SyncPeriod])
+// and ingresses getting their first address, which is needed to verify
+// their routing (see [routeVerifier]), are let through. Our own (child)
+// ingresses are filtered out, as they're handled by [statusReconciler].
+func ingressChangedPredicate[T crclient.Object]() predicate.TypedPredicate[T] {
+	notChild := predicate.NewTypedPredicateFuncs(func(obj T) bool {
+		return obj.GetLabels()[ManagedLabel] != "true"
+	})
+	hasAddress := func(obj T) bool {
+		ing, ok := any(obj).(*networkingv1.Ingress)
+		return ok && len(ing.Status.LoadBalancer.Ingress) > 0
+	}
+	resyncOrAddressed := predicate.TypedFuncs[T]{
+		UpdateFunc: func(e event.TypedUpdateEvent[T]) bool {
+			return e.ObjectOld.GetResourceVersion() == e.ObjectNew.GetResourceVersion() ||
+				(!hasAddress(e.ObjectOld) && hasAddress(e.ObjectNew))
+		},
+	}
+	return predicate.And[T](notChild,
+		predicate.Or[T](resyncOrAddressed, predicate.And[T](stateChangedPredicate[T](), specChangedPredicate[T]())))
+}
+
+// getProfile returns the name of the configmap configured for the
+// ingress class of the provided ingress through
+// [config.Config.IngressClassProfiles], if any.
+func (ir *IngressReconciler) getProfile(ing *networkingv1.Ingress) string {
+	if ing.Spec.IngressClassName == nil {
+		return ""
+	}
+	return ir.cfg.IngressClassProfiles[*ing.Spec.IngressClassName]
+}
 
+// releaseIngress handles an ingress that isn't managed by this
+// controller. If it previously was (e.g., its ingress class was changed
+// or it opted out), all of the resources we created for it are removed
+// along with our finalizer.
+func (ir *IngressReconciler) releaseIngress(ctx context.Context, log slogext.Logger,
+	ing *networkingv1.Ingress, req reconcile.Request) (reconcile.Result, error) {
+	// Failures from while it was managed no longer apply.
+	ir.breaker.forget(req.NamespacedName)
+
+	if !slices.Contains(ing.Finalizers, FinalizerKey) {
 		return reconcile.Result{}, nil
 	}
 
-	if origIng.GetLabels()[ManagedLabel] == "true" {
-		return reconcile.Result{}, reconcile.TerminalError(fmt.Errorf("attempted to reconcile ingress owned by self"))
+	// Other instances of ingress-anubis use the same finalizer, so only
+	// release ingresses that we have created resources for.
+	owned, err := ir.listOwned(ctx, req)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if len(owned) == 0 {
+		return reconcile.Result{}, nil
 	}
 
-	log := ir.log.With(slog.String("name", req.Name), slog.String("namespace", req.Namespace))
+	log.Info("ingress is no longer managed, pruning resources")
+	if err := ir.restoreInPlace(ctx, ing, req); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err := ir.finalize(ctx, ing, req); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err := ir.clearState(ctx, ing); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// finalize removes all resources created for the provided ingress and
+// then removes our finalizer from it.
+func (ir *IngressReconciler) finalize(ctx context.Context, ing *networkingv1.Ingress, req reconcile.Request) error {
+	if err := ir.deleteResources(ctx, req); err != nil {
+		return fmt.Errorf("failed to prune resources: %w", err)
+	}
+	ir.verifier.forget(req.NamespacedName)
+	ir.degraded.forget(req.NamespacedName)
+	ir.applied.forget(req.NamespacedName)
+
+	// Remove the finalizer if it exists
+	if slices.Contains(ing.Finalizers, FinalizerKey) {
+		patch := crclient.StrategicMergeFrom(ing.DeepCopy())
+		ing.Finalizers = slices.Delete(ing.Finalizers, slices.Index(ing.Finalizers, FinalizerKey), 1)
+		if err := ir.client.Patch(ctx, ing, patch); err != nil {
+			return fmt.Errorf("failed to remove finalizer: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// observeResult records the result of reconciling the provided ingress
+// with the [circuitBreaker]. If the circuit is open, the ingress is
+// parked on a slow retry schedule instead of the usual backoff.
+// Degraded ingresses (see [degradedError]) are retried on their own
+// schedule until they time out.
+func (ir *IngressReconciler) observeResult(log slogext.Logger, ing *networkingv1.Ingress,
+	res reconcile.Result, err error) (reconcile.Result, error) {
+	key := crclient.ObjectKeyFromObject(ing)
+	de, isDegraded := asDegraded(err)
+	if !isDegraded {
+		ir.degraded.forget(key)
+	}
+
+	if err == nil {
+		ir.notifier.resolve(ir.cluster, key)
+		if ir.breaker.success(key) {
+			log.Info("ingress reconciled successfully, closed circuit breaker")
+			ir.recorder.Eventf(ing, nil, corev1.EventTypeNormal, "CircuitClosed", "Reconcile",
+				"Reconciled successfully, resuming normal retries")
+		}
+		return res, nil
+	}
+	reconcileErrors.WithLabelValues(errorReason(err)).Inc()
+
+	if isDegraded {
+		if ir.degraded.retry(key, ing.Generation) {
+			ir.recorder.Eventf(ing, nil, corev1.EventTypeWarning, de.reason, "Reconcile",
+				"Unable to protect ingress yet, retrying in %s: %v", ir.cfg.DegradedRetryInterval, err)
+			log.Info("ingress is degraded, retrying later", slog.Duration("retry_after", ir.cfg.DegradedRetryInterval),
+				slog.String("err", err.Error()))
+			return reconcile.Result{RequeueAfter: ir.cfg.DegradedRetryInterval}, nil
+		}
+		err = reconcile.TerminalError(err)
+	}
+
+	// Terminal errors are never retried, so there's nothing to break.
+	// Surface them on the ingress since they need to be fixed by the
+	// user.
+	if errors.Is(err, reconcile.TerminalError(nil)) {
+		ir.recorder.Eventf(ing, nil, corev1.EventTypeWarning, "InvalidIngress", "Reconcile",
+			"Unable to protect ingress: %v", err)
+		ir.notifier.fail(ir.cluster, key, "InvalidIngress", err.Error())
+		return res, err
+	}
+
+	failures, opened := ir.breaker.failure(key)
+	if !ir.breaker.isOpen(failures) {
+		return res, err
+	}
+
+	if opened {
+		ir.recorder.Eventf(ing, nil, corev1.EventTypeWarning, "NeedsAttention", "Reconcile",
+			"Failed to reconcile %d consecutive times, retrying every %s: %v",
+			failures, ir.breaker.retryInterval, err)
+		ir.notifier.fail(ir.cluster, key, "NeedsAttention", fmt.Sprintf("failed to reconcile %d consecutive times: %v", failures, err))
+	}
+	log.Error("ingress is persistently failing to reconcile, retrying later",
+		slog.Int("failures", failures), slog.Duration("retry_after", ir.breaker.retryInterval),
+		slog.String("err", err.Error()))
 
+	return reconcile.Result{RequeueAfter: ir.breaker.retryInterval}, nil
+}
+
+// reconcileIngress reconciles all of the resources for an ingress that
+// is handled by this controller.
+func (ir *IngressReconciler) reconcileIngress(ctx context.Context, log slogext.Logger,
+	origIng *networkingv1.Ingress, req reconcile.Request) (reconcile.Result, error) {
 	// Ingress was deleted, clean up resources.
 	if !origIng.DeletionTimestamp.IsZero() {
 		log.Info("ingress was deleted, pruning resources")
 
-		if err := ir.deleteResources(ctx, req.Name); err != nil {
-			return reconcile.Result{}, fmt.Errorf("failed to prune resources: %w", err)
-		}
-
-		// Remove the finalizer if it exists
-		if slices.Contains(origIng.Finalizers, FinalizerKey) {
-			patch := crclient.StrategicMergeFrom(origIng.DeepCopy())
-			origIng.Finalizers = slices.Delete(origIng.Finalizers, slices.Index(origIng.Finalizers, FinalizerKey), 1)
-			if err := ir.client.Patch(ctx, origIng, patch); err != nil {
-				return reconcile.Result{}, fmt.Errorf("failed to remove finalizer: %w", err)
-			}
+		if err := ir.finalize(ctx, origIng, req); err != nil {
+			return reconcile.Result{}, err
 		}
 
 		log.Info("finished pruning resources and removed finalizer")
@@ -161,97 +465,558 @@ func (ir *IngressReconciler) Reconcile(ctx context.Context, req reconcile.Reques
 		return reconcile.Result{Requeue: true}, nil
 	}
 
-	// Grab the first valid backend from the ingress, we'll use that as
-	// anubis' target. Note that technically ingresses can have more than
-	// one target, so this won't work in that case.
-	var svcBackend *networkingv1.IngressServiceBackend
-	if origIng.Spec.DefaultBackend != nil { // Preference to default backend
-		svcBackend = origIng.Spec.DefaultBackend.Service
-	} else {
-		if len(origIng.Spec.Rules) == 0 {
-			return reconcile.Result{}, reconcile.TerminalError(fmt.Errorf("no rules or default backend in ingress"))
-		}
+	classParams, err := getClassParams(ctx, ir.client, ir.cfg, origIng)
+	if err != nil {
+		ir.recorder.Eventf(origIng, nil, corev1.EventTypeWarning, "InvalidIngressClassParams", "Reconcile",
+			"Unable to get the parameters of the ingress class: %v", err)
+		return reconcile.Result{}, err
+	}
 
-		rule := origIng.Spec.Rules[0]
-		if rule.HTTP == nil {
-			return reconcile.Result{}, reconcile.TerminalError(fmt.Errorf("ingress rule 0 HTTP was nil"))
-		}
+	icfg, err := ir.cfg.GetIngressConfig(origIng, classParams)
+	if err != nil {
+		ir.recorder.Eventf(origIng, nil, corev1.EventTypeWarning, "InvalidAnnotation", "Reconcile",
+			"Unable to parse annotations: %v", err)
+		return reconcile.Result{}, err
+	}
 
-		if len(rule.HTTP.Paths) == 0 {
-			return reconcile.Result{}, reconcile.TerminalError(fmt.Errorf("ingress rule 0 paths was empty"))
+	// Leave everything as-is while suspended, allowing the managed
+	// resources to be modified by hand.
+	if *icfg.Suspend {
+		log.Info("reconciliation is suspended, skipping")
+		ir.recorder.Eventf(origIng, nil, corev1.EventTypeNormal, "Suspended", "Reconcile",
+			"Reconciliation is suspended through %s, managed resources will not be modified", config.AnnotationKeySuspend)
+		return reconcile.Result{}, nil
+	}
+
+	inPlace := *icfg.Interposition == config.InterpositionInPlace
+	if inPlace {
+		ours, err := ir.hasIngressClass(ctx, origIng)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if ours {
+			return reconcile.Result{}, reconcile.TerminalError(fmt.Errorf(
+				"in-place interposition requires opting in through %s instead of using ingress class %q",
+				config.AnnotationKeyProtect, *origIng.Spec.IngressClassName))
 		}
+		if *icfg.Mode == config.ModeShadow {
+			return reconcile.Result{}, reconcile.TerminalError(fmt.Errorf("shadow mode is not supported with in-place interposition"))
+		}
+		// The ExternalName services would have the same names as the
+		// anubis services they point at.
+		if req.Namespace == ir.cfg.Namespace {
+			return reconcile.Result{}, reconcile.TerminalError(fmt.Errorf(
+				"in-place interposition is not supported for ingresses in the controller namespace %q", ir.cfg.Namespace))
+		}
+	}
+	if err := ir.checkShadowMode(icfg); err != nil {
+		return reconcile.Result{}, reconcile.TerminalError(err)
+	}
+	if !inPlace {
+		if _, err := ir.childIngressClass(ctx, origIng, icfg); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	if !inPlace || *icfg.Bypass {
+		// Switched away from in-place interposition (or bypassing
+		// anubis), revert it.
+		if err := ir.restoreInPlace(ctx, origIng, req); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
 
-		path := rule.HTTP.Paths[0]
-		svcBackend = path.Backend.Service
+	// When rewritten in-place, the original backends are what we want to
+	// point anubis at.
+	spec, err := getOriginalSpec(origIng, ir.inPlaceServiceNames(req)...)
+	if err != nil {
+		return reconcile.Result{}, err
 	}
 
-	target, err := ir.getTargetFromService(ctx, origIng.Namespace, svcBackend)
+	if resources := getResourceBackends(spec); len(resources) > 0 {
+		if *icfg.ResourceBackends == config.ResourceBackendsReject {
+			return reconcile.Result{}, reconcile.TerminalError(fmt.Errorf(
+				"resource backends can't be protected and are rejected through %s: %s",
+				config.AnnotationKeyResourceBackends, strings.Join(resources, ", ")))
+		}
+		ir.recorder.Eventf(origIng, nil, corev1.EventTypeWarning, "ResourceBackendsUnprotected", "Reconcile",
+			"Resource backends can't be protected by anubis and are passed through as-is: %s", strings.Join(resources, ", "))
+	}
+
+	// Each distinct backend of the ingress gets its own instance of
+	// anubis, since anubis can only proxy to a single target.
+	services, err := getTargetBackends(spec)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
-	icfg, err := config.GetIngressConfigFromIngress(origIng)
+	backends, err := ir.getBackends(ctx, services, *icfg.TargetScheme, req)
 	if err != nil {
+		ir.recorder.Eventf(origIng, nil, corev1.EventTypeWarning, "TargetResolutionFailed", "Reconcile",
+			"Unable to resolve the target of anubis: %v", err)
 		return reconcile.Result{}, err
 	}
 
-	if err := ir.reconcileDeployment(ctx, target, icfg, req); err != nil {
+	if *icfg.Bypass {
+		return reconcile.Result{}, ir.reconcileBypass(ctx, log, origIng, backends, icfg, inPlace, req)
+	}
+
+	policyChecksum, err := ir.reconcilePolicy(ctx, icfg, req)
+	if err != nil {
 		return reconcile.Result{}, err
 	}
 
-	if err := ir.reconcileService(ctx, req); err != nil {
+	// In shadow mode, all requests are mirrored to the anubis instance of
+	// the first backend (see [IngressReconciler.reconcileChildIngress]),
+	// so the others wouldn't receive any traffic.
+	protected := backends
+	if *icfg.Mode == config.ModeShadow {
+		protected = backends[:1]
+	}
+
+	version, promoteAfter, err := ir.anubisVersion(ctx, icfg)
+	if err != nil {
 		return reconcile.Result{}, err
 	}
 
-	if err := ir.reconcileChildIngress(ctx, origIng, icfg, req); err != nil {
+	var rolloutDelay time.Duration
+	keep := make([]string, 0, 2*len(backends)+1)
+	for _, b := range protected {
+		delay, err := ir.reconcileDeployment(ctx, b, icfg, version, ir.getProfile(origIng), policyChecksum, req)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		rolloutDelay = max(rolloutDelay, delay)
+
+		if err := ir.checkDeployment(ctx, origIng, b.name); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		if err := ir.reconcileService(ctx, b, icfg, req); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		if err := ir.reconcilePodDisruptionBudget(ctx, icfg, b, req); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		if err := ir.reconcileNetworkPolicy(ctx, origIng.Namespace, b, req); err != nil {
+			return reconcile.Result{}, err
+		}
+		keep = append(keep, b.name)
+	}
+
+	if policyChecksum != "" {
+		keep = append(keep, ir.policyName(req))
+	}
+	if inPlace {
+		if err := ir.reconcileInPlace(ctx, origIng, icfg, backends, req); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		// The child ingress shares its name with the deployment and
+		// service of the first backend, so it needs to be explicitly
+		// removed.
+		child := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: backends[0].name, Namespace: ir.cfg.Namespace}}
+		if err := ir.client.Delete(ctx, child); crclient.IgnoreNotFound(err) != nil {
+			return reconcile.Result{}, fmt.Errorf("failed to delete child ingress: %w", err)
+		}
+	} else {
+		for _, b := range backends {
+			if err := ir.reconcileDirectService(ctx, b, icfg, req); err != nil {
+				return reconcile.Result{}, err
+			}
+			keep = append(keep, b.name+"-direct")
+		}
+
+		tlsSecrets, err := ir.reconcileTLSSecrets(ctx, origIng, req)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		keep = slices.AppendSeq(keep, maps.Values(tlsSecrets))
+
+		if err := ir.reconcileChildIngress(ctx, origIng, icfg, backends, tlsSecrets, req); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if err := ir.pruneStaleResources(ctx, req, keep...); err != nil {
 		return reconcile.Result{}, err
 	}
 
-	return reconcile.Result{}, nil
+	if rolloutDelay > 0 {
+		log.Info("deployment rollout deferred due to rollout limit", slog.Duration("retry_after", rolloutDelay))
+		return reconcile.Result{RequeueAfter: rolloutDelay}, nil
+	}
+
+	// Ingresses waiting for a canary version to be promoted are checked
+	// again once it may have been.
+	res := reconcile.Result{RequeueAfter: promoteAfter}
+
+	// Shadow mode doesn't route traffic through anubis, so there's
+	// nothing to verify.
+	if *icfg.Mode == config.ModeShadow {
+		return res, nil
+	}
+
+	if vres := ir.verifyRouting(ctx, log, origIng); vres.RequeueAfter > 0 &&
+		(res.RequeueAfter == 0 || vres.RequeueAfter < res.RequeueAfter) {
+		res = vres
+	}
+	return res, nil
+}
+
+// reconcileBypass reconciles an ingress with [config.IngressConfig.Bypass]
+// set, routing traffic directly to the original backends and removing
+// anubis. In-place ingresses have already been restored by this point,
+// so only child ingresses need to be pointed at the original backends.
+func (ir *IngressReconciler) reconcileBypass(ctx context.Context, log slogext.Logger, origIng *networkingv1.Ingress,
+	backends []*anubisBackend, icfg *config.IngressConfig, inPlace bool, req reconcile.Request) error {
+	log.Warn("bypassing anubis for ingress")
+
+	var keep []string
+	if !inPlace {
+		for _, b := range backends {
+			if err := ir.reconcileDirectService(ctx, b, icfg, req); err != nil {
+				return err
+			}
+			keep = append(keep, b.name+"-direct")
+		}
+
+		tlsSecrets, err := ir.reconcileTLSSecrets(ctx, origIng, req)
+		if err != nil {
+			return err
+		}
+		keep = slices.AppendSeq(keep, maps.Values(tlsSecrets))
+
+		if err := ir.reconcileChildIngress(ctx, origIng, icfg, backends, tlsSecrets, req); err != nil {
+			return err
+		}
+
+		// The deployment, service, pod disruption budget and network
+		// policy of the first backend share their name with the child
+		// ingress, so they need to be explicitly removed.
+		name := backends[0].name
+		meta := metav1.ObjectMeta{Name: name, Namespace: ir.cfg.Namespace}
+		for _, obj := range []crclient.Object{
+			&appsv1.Deployment{ObjectMeta: meta}, &corev1.Service{ObjectMeta: meta},
+			&policyv1.PodDisruptionBudget{ObjectMeta: meta}, &networkingv1.NetworkPolicy{ObjectMeta: meta},
+		} {
+			if err := ir.client.Delete(ctx, obj); crclient.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to delete anubis resources: %w", err)
+			}
+		}
+		keep = append(keep, name)
+	}
+
+	return ir.pruneStaleResources(ctx, req, keep...)
 }
 
-// deleteResources cleans up all resources created by this controller,
-// if they exist
-func (ir *IngressReconciler) deleteResources(ctx context.Context, name string) error {
-	meta := metav1.ObjectMeta{
-		Name:      "ia-" + name,
-		Namespace: ir.cfg.Namespace,
+// verifyRouting verifies that anubis is served for the provided ingress
+// (see [routeVerifier]), recording the result. Failing ingresses are
+// requeued to be verified again later.
+func (ir *IngressReconciler) verifyRouting(ctx context.Context, log slogext.Logger, ing *networkingv1.Ingress) reconcile.Result {
+	if ir.verifier == nil {
+		return reconcile.Result{}
+	}
+
+	// The status of the wrapped ingress is mirrored to this one once it
+	// has an address, which will trigger another reconcile.
+	err := ir.verifier.verify(ctx, ing)
+	if errors.Is(err, errRoutingPending) {
+		return reconcile.Result{}
 	}
 
-	ing := &networkingv1.Ingress{}
-	if err := ir.client.Get(ctx, crclient.ObjectKeyFromObject(&networkingv1.Ingress{ObjectMeta: meta}), ing); err == nil {
-		if err := ir.client.Delete(ctx, ing); err != nil {
-			return fmt.Errorf("failed to delete wrapped ingress: %w", err)
+	if ir.verifier.observe(crclient.ObjectKeyFromObject(ing), err == nil) {
+		if err != nil {
+			ir.recorder.Eventf(ing, nil, corev1.EventTypeWarning, "RoutingVerificationFailed", "Verify",
+				"Requests are not being served by anubis: %v", err)
+		} else {
+			ir.recorder.Eventf(ing, nil, corev1.EventTypeNormal, "RoutingVerified", "Verify",
+				"Verified that requests are served by anubis")
 		}
-	} else if err := crclient.IgnoreNotFound(err); err != nil {
-		return fmt.Errorf("failed to check existence of wrapped ingress: %w", err)
 	}
 
-	svc := &corev1.Service{}
-	if err := ir.client.Get(ctx, crclient.ObjectKeyFromObject(&corev1.Service{ObjectMeta: meta}), svc); err == nil {
-		if err := ir.client.Delete(ctx, svc); err != nil {
-			return fmt.Errorf("failed to delete service: %w", err)
+	if err != nil {
+		log.Warn("failed to verify routing through anubis", slog.String("err", err.Error()))
+		return reconcile.Result{RequeueAfter: ir.verifier.interval}
+	}
+
+	return reconcile.Result{}
+}
+
+// deleteResources cleans up all resources created by this controller
+// for the ingress in req, whatever their names, if they exist.
+func (ir *IngressReconciler) deleteResources(ctx context.Context, req reconcile.Request) error {
+	sel := crclient.MatchingLabels(ownerLabels(req))
+	for _, obj := range []crclient.Object{
+		&appsv1.Deployment{}, &corev1.Service{}, &networkingv1.Ingress{},
+		&corev1.ConfigMap{}, &policyv1.PodDisruptionBudget{}, &networkingv1.NetworkPolicy{},
+		&corev1.Secret{},
+	} {
+		if err := ir.client.DeleteAllOf(ctx, obj, crclient.InNamespace(ir.cfg.Namespace), sel); err != nil {
+			return fmt.Errorf("failed to delete %T resources: %w", obj, err)
 		}
-	} else if err := crclient.IgnoreNotFound(err); err != nil {
-		return fmt.Errorf("failed to check existence of service: %w", err)
 	}
 
-	dep := &appsv1.Deployment{}
-	if err := ir.client.Get(ctx, crclient.ObjectKeyFromObject(&appsv1.Deployment{ObjectMeta: meta}), dep); err == nil {
-		if err := ir.client.Delete(ctx, dep); err != nil {
-			return fmt.Errorf("failed to delete deployment: %w", err)
+	// In-place services live in the namespace of the ingress.
+	sel = maps.Clone(sel)
+	sel[ManagedLabel] = "true"
+	if err := ir.client.DeleteAllOf(ctx, &corev1.Service{}, crclient.InNamespace(req.Namespace), sel); err != nil {
+		return fmt.Errorf("failed to delete in-place services: %w", err)
+	}
+
+	// Older resources are only labeled with OwningLabel, which is
+	// ambiguous, so they're checked one by one.
+	return ir.pruneStaleResources(ctx, req)
+}
+
+// maxResourceNameLength is the maximum length of the names returned by
+// [IngressReconciler.resourceName], leaving room for suffixes (e.g.,
+// "-direct") within the 63 character limit of service names.
+const maxResourceNameLength = 56
+
+// resourceName returns the name of the resources created for the
+// ingress with the provided key: [config.Config.ResourcePrefix], its
+// name (truncated if needed) and a hash of its namespace and name, so
+// that ingresses with the same name in different namespaces don't
+// collide.
+func (ir *IngressReconciler) resourceName(key types.NamespacedName) string {
+	return ir.hashedResourceName(key.Name, key.String())
+}
+
+// hashedResourceName returns [config.Config.ResourcePrefix], the
+// provided name (truncated if needed) and a hash of id.
+func (ir *IngressReconciler) hashedResourceName(name, id string) string {
+	sum := sha256.Sum256([]byte(id))
+	hash := hex.EncodeToString(sum[:4])
+
+	// Ingress names may contain dots, service names may not.
+	name = strings.ReplaceAll(name, ".", "-")
+	if maxLen := max(maxResourceNameLength-len(ir.cfg.ResourcePrefix)-len(hash)-1, 0); len(name) > maxLen {
+		name = strings.TrimRight(name[:maxLen], "-")
+	}
+	return ir.cfg.ResourcePrefix + name + "-" + hash
+}
+
+// legacyResourceName returns the name resources were created under
+// before [IngressReconciler.resourceName] included a hash. Resources in
+// the controller namespace are migrated by
+// [IngressReconciler.pruneStaleResources], in-place services by
+// [IngressReconciler.reconcileInPlace].
+func (ir *IngressReconciler) legacyResourceName(name string) string {
+	return ir.cfg.ResourcePrefix + name
+}
+
+// apply creates or updates obj through server-side apply, so that only
+// the fields set by f are owned by us and fields set by others (e.g.,
+// an HPA) are left alone. Unlike [controllerutil.CreateOrUpdate], f is
+// called on obj as provided (usually with only its name and namespace
+// set) rather than on the current state of the resource, and obj is
+// updated with the result. Resources that are up to date aren't applied
+// again, see [SpecHashAnnotation]. The operation is counted in
+// [childResourceOperations].
+func (ir *IngressReconciler) apply(ctx context.Context, obj crclient.Object,
+	f func() error) (controllerutil.OperationResult, error) {
+	gvk, err := apiutil.GVKForObject(obj, ir.client.Scheme())
+	if err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+
+	//nolint:errcheck // Why: DeepCopyObject always returns the same type.
+	current := obj.DeepCopyObject().(crclient.Object)
+	exists := true
+	if err := ir.client.Get(ctx, crclient.ObjectKeyFromObject(obj), current); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return controllerutil.OperationResultNone, err
+		}
+		exists = false
+	}
+
+	if err := f(); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+	delete(u, "status")
+	ac := &unstructured.Unstructured{Object: pruneNulls(u)}
+	ac.SetGroupVersionKind(gvk)
+
+	// Skip applying resources that are already up to date, which would be
+	// no-ops, to keep steady-state reconciles from writing to the API
+	// server.
+	hash, err := specHash(ac.Object)
+	if err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+	// Dry runs don't change anything, so the same changes would otherwise
+	// be reported on every reconcile.
+	reported := ir.cfg.DryRun && ir.applied.reported(gvk, obj, current, hash)
+	if (exists && ir.applied.upToDate(gvk, current, hash)) || reported {
+		if !exists {
+			return controllerutil.OperationResultNone, nil
+		}
+		cu, err := runtime.DefaultUnstructuredConverter.ToUnstructured(current)
+		if err != nil {
+			return controllerutil.OperationResultNone, err
+		}
+		return controllerutil.OperationResultNone, runtime.DefaultUnstructuredConverter.FromUnstructured(cu, obj)
+	}
+	annotations := ac.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[SpecHashAnnotation] = hash
+	ac.SetAnnotations(annotations)
+
+	if !ir.cfg.DryRun {
+		if err := ir.logPlannedChanges(ctx, gvk.Kind, exists, current, ac); err != nil {
+			return controllerutil.OperationResultNone, err
+		}
+	}
+	if err := ir.client.Apply(ctx, crclient.ApplyConfigurationFromUnstructured(ac),
+		crclient.FieldOwner(FieldManager), crclient.ForceOwnership); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(ac.Object, obj); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+	if ir.cfg.DryRun {
+		op, err := ir.reportDryRun(ctx, gvk.Kind, exists, current, obj)
+		if err != nil {
+			return op, err
+		}
+		ir.applied.recordReported(gvk, obj, current, hash)
+		return op, nil
+	}
+	ir.applied.record(gvk, obj)
+
+	op := controllerutil.OperationResultNone
+	switch {
+	case !exists:
+		op = controllerutil.OperationResultCreated
+	case obj.GetResourceVersion() != current.GetResourceVersion():
+		op = controllerutil.OperationResultUpdated
+	}
+	if op != controllerutil.OperationResultNone {
+		childResourceOperations.WithLabelValues(gvk.Kind, string(op)).Inc()
+	}
+	if op == controllerutil.OperationResultCreated {
+		ir.recordCreated(ctx, obj, gvk.Kind)
+	}
+	return op, nil
+}
+
+// recordCreated emits an event on the ingress owning the provided
+// resource (see [getOwner]), which was just created.
+func (ir *IngressReconciler) recordCreated(ctx context.Context, obj crclient.Object, kind string) {
+	key, ok := getOwner(obj.GetLabels())
+	if !ok {
+		return
+	}
+
+	owner := &networkingv1.Ingress{}
+	if err := ir.client.Get(ctx, key, owner); err != nil {
+		return
+	}
+
+	ir.recorder.Eventf(owner, obj, corev1.EventTypeNormal, "Created", "Reconcile",
+		"Created %s %s/%s", kind, obj.GetNamespace(), obj.GetName())
+}
+
+// pruneNulls removes all null values from the provided object, e.g.,
+// unset timestamps, so that they aren't applied by
+// [IngressReconciler.apply].
+func pruneNulls(obj map[string]any) map[string]any {
+	for k, v := range obj {
+		switch v := v.(type) {
+		case nil:
+			delete(obj, k)
+		case map[string]any:
+			pruneNulls(v)
+		case []any:
+			for _, item := range v {
+				if m, ok := item.(map[string]any); ok {
+					pruneNulls(m)
+				}
+			}
+		}
+	}
+	return obj
+}
+
+// listOwned returns all resources in the controller namespace owned by
+// the ingress in req.
+func (ir *IngressReconciler) listOwned(ctx context.Context, req reconcile.Request) ([]crclient.Object, error) {
+	// See ownerIndexedTypes.
+	lists := []crclient.ObjectList{&appsv1.DeploymentList{}, &corev1.ServiceList{}, &networkingv1.IngressList{}}
+	for _, list := range lists {
+		if err := ir.client.List(ctx, list, crclient.InNamespace(ir.cfg.Namespace),
+			crclient.MatchingFields{ownerIndex: req.String()}); err != nil {
+			return nil, fmt.Errorf("failed to list owned resources: %w", err)
+		}
+	}
+	for _, sel := range ownerSelectors(req) {
+		for _, list := range []crclient.ObjectList{
+			&corev1.ConfigMapList{}, &policyv1.PodDisruptionBudgetList{}, &networkingv1.NetworkPolicyList{},
+			&corev1.SecretList{},
+		} {
+			if err := ir.client.List(ctx, list, crclient.InNamespace(ir.cfg.Namespace), sel); err != nil {
+				return nil, fmt.Errorf("failed to list owned resources: %w", err)
+			}
+			lists = append(lists, list)
+		}
+	}
+
+	var objs []crclient.Object
+	for _, list := range lists {
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			obj, ok := item.(crclient.Object)
+			if !ok {
+				continue
+			}
+			if owner, ok := getOwner(obj.GetLabels()); ok && owner == req.NamespacedName {
+				objs = append(objs, obj)
+			}
+		}
+	}
+
+	return objs, nil
+}
+
+// pruneStaleResources deletes all resources owned by the ingress in req
+// whose names are not in keep. This ensures resources created under a
+// previous [config.Config.ResourcePrefix] aren't orphaned.
+func (ir *IngressReconciler) pruneStaleResources(ctx context.Context, req reconcile.Request, keep ...string) error {
+	objs, err := ir.listOwned(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objs {
+		if slices.Contains(keep, obj.GetName()) {
+			continue
+		}
+
+		if err := ir.client.Delete(ctx, obj); crclient.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete stale resource %s: %w", obj.GetName(), err)
 		}
-	} else if err := crclient.IgnoreNotFound(err); err != nil {
-		return fmt.Errorf("failed to check existence of deployment: %w", err)
 	}
 
 	return nil
 }
 
-// getTargetFromService returns a that can be used to communicate with
-// the given service in isb from inside of Kubernetes.
+// getTargetFromService returns a URL, using the provided scheme, that
+// can be used to communicate with the given service in isb from inside
+// of Kubernetes.
 func (ir *IngressReconciler) getTargetFromService(ctx context.Context, ns string,
-	isb *networkingv1.IngressServiceBackend) (string, error) {
+	isb *networkingv1.IngressServiceBackend, scheme config.TargetScheme) (string, error) {
 	// If the target is a name, we need to look up the service's real
 	// port.
 	port := isb.Port.Number
@@ -276,12 +1041,84 @@ func (ir *IngressReconciler) getTargetFromService(ctx context.Context, ns string
 		}
 	}
 
-	return fmt.Sprintf("http://%s.%s.svc.cluster.local:%d", isb.Name, ns, port), nil
+	return fmt.Sprintf("%s://%s.%s.svc.cluster.local:%d", scheme, isb.Name, ns, port), nil
+}
+
+// getImage returns the Anubis image to use for the provided ingress
+// configuration and version (see [IngressReconciler.anubisVersion]).
+func (ir *IngressReconciler) getImage(icfg *config.IngressConfig, version string) string {
+	image := ir.cfg.AnubisImage
+	if icfg.AnubisImage != nil {
+		image = *icfg.AnubisImage
+	}
+
+	return config.ImageReference(image, version)
+}
+
+// getPodLabels returns the labels of the anubis pod, which are the
+// provided labels (also used as the deployment's selector) on top of
+// [config.Config.PodLabels].
+func (ir *IngressReconciler) getPodLabels(labels map[string]string) map[string]string {
+	podLabels := maps.Clone(ir.cfg.PodLabels)
+	if podLabels == nil {
+		return labels
+	}
+	maps.Copy(podLabels, labels)
+	return podLabels
+}
+
+// getImagePullSecrets returns the secrets used to pull the anubis image.
+func (ir *IngressReconciler) getImagePullSecrets() []corev1.LocalObjectReference {
+	var refs []corev1.LocalObjectReference
+	for _, name := range ir.cfg.ImagePullSecrets {
+		refs = append(refs, corev1.LocalObjectReference{Name: name})
+	}
+	return refs
+}
+
+// getProbes returns the probes for the anubis container, defaulting to
+// a readiness probe against the metrics endpoint (or the main port, if
+// metrics are disabled).
+func getProbes(icfg *config.IngressConfig) config.Probes {
+	probes := ptr.Deref(icfg.Probes, config.Probes{})
+	switch {
+	case probes.Readiness != nil:
+	case !*icfg.MetricsEnabled:
+		probes.Readiness = &corev1.Probe{
+			FailureThreshold: 3,
+			ProbeHandler: corev1.ProbeHandler{
+				TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString("http")},
+			},
+		}
+	default:
+		probes.Readiness = &corev1.Probe{
+			FailureThreshold: 3,
+			ProbeHandler: corev1.ProbeHandler{
+				HTTPGet: &corev1.HTTPGetAction{
+					//nolint:gosec // Why: Not a possible overflow.
+					Port: intstr.FromInt32(int32(*icfg.MetricsPort)),
+					Path: "/metrics",
+				},
+			},
+		}
+	}
+	return probes
+}
+
+// getPorts returns the container ports of the anubis container.
+func getPorts(icfg *config.IngressConfig) []corev1.ContainerPort {
+	//nolint:gosec // Why: Not a possible overflow.
+	ports := []corev1.ContainerPort{{Name: "http", ContainerPort: int32(*icfg.Port)}}
+	if *icfg.MetricsEnabled {
+		//nolint:gosec // Why: Not a possible overflow.
+		ports = append(ports, corev1.ContainerPort{Name: "http-metrics", ContainerPort: int32(*icfg.MetricsPort)})
+	}
+	return ports
 }
 
 // getEnvFrom returns an EnvFrom block for the current ingress
 // configuration
-func (ir *IngressReconciler) getEnvFrom(icfg *config.IngressConfig) []corev1.EnvFromSource {
+func (ir *IngressReconciler) getEnvFrom(icfg *config.IngressConfig, profile string) []corev1.EnvFromSource {
 	envFrom := make([]corev1.EnvFromSource, 0)
 
 	if ir.cfg.EnvFromCM != "" {
@@ -304,6 +1141,16 @@ func (ir *IngressReconciler) getEnvFrom(icfg *config.IngressConfig) []corev1.Env
 		})
 	}
 
+	if profile != "" {
+		envFrom = append(envFrom, corev1.EnvFromSource{
+			ConfigMapRef: &corev1.ConfigMapEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: profile,
+				},
+			},
+		})
+	}
+
 	if icfg.EnvFromCM != nil {
 		envFrom = append(envFrom, corev1.EnvFromSource{
 			ConfigMapRef: &corev1.ConfigMapEnvSource{
@@ -329,102 +1176,243 @@ func (ir *IngressReconciler) getEnvFrom(icfg *config.IngressConfig) []corev1.Env
 
 // getVolumeMounts returns the volume mounts for this instance
 func (ir *IngressReconciler) getVolumeMounts() []corev1.VolumeMount {
-	var r []corev1.VolumeMount
+	//nolint:errcheck // Why: Validated when loading the configuration.
+	mounts, _ := ir.cfg.GetVolumeMounts()
+	return mounts
+}
+
+// getResources returns the compute resources of the anubis container,
+// preferring the ones configured on the ingress.
+func (ir *IngressReconciler) getResources(icfg *config.IngressConfig) corev1.ResourceRequirements {
+	if icfg.Resources != nil {
+		return *icfg.Resources
+	}
 
-	//nolint:errcheck // Why: Best effort
-	_ = json.Unmarshal([]byte(ir.cfg.VolumeMounts), &r)
+	//nolint:errcheck // Why: Validated when loading the configuration.
+	r, _ := ir.cfg.GetAnubisResources()
+	return ptr.Deref(r, corev1.ResourceRequirements{})
+}
 
-	return r
+// getNodeSelector returns the node selector of the anubis pod,
+// preferring the one configured on the ingress.
+func (ir *IngressReconciler) getNodeSelector(icfg *config.IngressConfig) map[string]string {
+	if icfg.NodeSelector != nil {
+		return icfg.NodeSelector
+	}
+	return ir.cfg.AnubisNodeSelector
+}
+
+// getTolerations returns the tolerations of the anubis pod, preferring
+// the ones configured on the ingress.
+func (ir *IngressReconciler) getTolerations(icfg *config.IngressConfig) []corev1.Toleration {
+	if icfg.Tolerations != nil {
+		return icfg.Tolerations
+	}
+
+	//nolint:errcheck // Why: Validated when loading the configuration.
+	t, _ := ir.cfg.GetAnubisTolerations()
+	return t
+}
+
+// getAffinity returns the affinity of the anubis pod, preferring the
+// one configured on the ingress.
+func (ir *IngressReconciler) getAffinity(icfg *config.IngressConfig) *corev1.Affinity {
+	if icfg.Affinity != nil {
+		return icfg.Affinity
+	}
+
+	//nolint:errcheck // Why: Validated when loading the configuration.
+	a, _ := ir.cfg.GetAnubisAffinity()
+	return a
+}
+
+// getTopologySpreadConstraints returns the topology spread constraints
+// of the anubis pod, preferring the ones configured on the ingress.
+// Constraints without a label selector are scoped to the pods of the
+// provided backend.
+func (ir *IngressReconciler) getTopologySpreadConstraints(icfg *config.IngressConfig, b *anubisBackend,
+	req reconcile.Request) []corev1.TopologySpreadConstraint {
+	tsc := icfg.TopologySpreadConstraints
+	if tsc == nil {
+		//nolint:errcheck // Why: Validated when loading the configuration.
+		tsc, _ = ir.cfg.GetAnubisTopologySpreadConstraints()
+	}
+
+	tsc = slices.Clone(tsc)
+	for i := range tsc {
+		if tsc[i].LabelSelector == nil {
+			tsc[i].LabelSelector = &metav1.LabelSelector{MatchLabels: b.selectorLabels(req)}
+		}
+	}
+	return tsc
 }
 
 // getVolumes returns the volumes for this instance
 func (ir *IngressReconciler) getVolumes() []corev1.Volume {
-	var r []corev1.Volume
+	//nolint:errcheck // Why: Validated when loading the configuration.
+	volumes, _ := ir.cfg.GetVolumes()
+	return volumes
+}
 
-	//nolint:errcheck // Why: Best effort
-	_ = json.Unmarshal([]byte(ir.cfg.Volumes), &r)
+// SigningKeySecretKey is the key of the signing key in the secret
+// referenced by [config.AnnotationKeySigningKeySecret].
+const SigningKeySecretKey = "key"
+
+// applySigningKey configures the provided pod template to sign cookies
+// with the key in the provided secret.
+func applySigningKey(tmpl *corev1.PodTemplateSpec, secret string) {
+	const mountPath = "/etc/anubis/signing-key"
+
+	tmpl.Spec.Volumes = append(tmpl.Spec.Volumes, corev1.Volume{
+		Name: "anubis-signing-key",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: secret,
+				Items:      []corev1.KeyToPath{{Key: SigningKeySecretKey, Path: SigningKeySecretKey}},
+			},
+		},
+	})
 
-	return r
+	c := &tmpl.Spec.Containers[0]
+	c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{
+		Name:      "anubis-signing-key",
+		MountPath: mountPath,
+		ReadOnly:  true,
+	})
+	c.Env = append(c.Env, corev1.EnvVar{
+		Name:  "ED25519_PRIVATE_KEY_HEX_FILE",
+		Value: path.Join(mountPath, SigningKeySecretKey),
+	})
 }
 
-// reconcileDeployment ensures that a deployment of anubis exists
-func (ir *IngressReconciler) reconcileDeployment(ctx context.Context, target string,
-	icfg *config.IngressConfig, req reconcile.Request) error {
+// errRolloutDeferred is returned when applying a deployment is deferred
+// because of the rollout limit, see [IngressReconciler.reconcileDeployment].
+var errRolloutDeferred = errors.New("rollout deferred")
+
+// reconcileDeployment ensures that a deployment of the provided version
+// of anubis exists for the provided backend. If rolling the deployment was deferred because
+// of the rollout limit (see [rolloutLimiter]), the amount of time to
+// wait before trying again is returned.
+func (ir *IngressReconciler) reconcileDeployment(ctx context.Context, b *anubisBackend,
+	icfg *config.IngressConfig, version, profile, policyChecksum string, req reconcile.Request) (time.Duration, error) {
 	dep := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "ia-" + req.Name,
+			Name:      b.name,
 			Namespace: ir.cfg.Namespace,
 		},
 	}
 
-	labels := map[string]string{
-		"app.kubernetes.io/instance": "anubis",
-		"app.kubernetes.io/name":     "anubis",
-		ManagedLabel:                 "true",
-		OwningLabel:                  req.Namespace + "--" + req.Name,
+	selector := b.appLabels(req)
+	labels := maps.Clone(selector)
+	maps.Copy(labels, ownerLabels(req))
+	if icfg.AnubisVersion == nil {
+		labels[VersionChannelLabel] = string(*icfg.VersionChannel)
 	}
 
-	_, err := controllerutil.CreateOrUpdate(ctx, ir.client, dep, func() error {
-		// Deployment selector is immutable so we set this value only if
-		// a new object is going to be created
-		if dep.CreationTimestamp.IsZero() {
-			dep.Spec.Selector = &metav1.LabelSelector{
-				MatchLabels: labels,
-			}
+	// The current deployment holds the tuned difficulty and is needed to
+	// tell whether its pods would be rolled.
+	current := &appsv1.Deployment{}
+	exists := true
+	if err := ir.client.Get(ctx, crclient.ObjectKeyFromObject(dep), current); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return 0, fmt.Errorf("failed to get deployment: %w", err)
+		}
+		exists = false
+	}
+
+	envFrom := ir.getEnvFrom(icfg, profile)
+	envChecksum, err := ir.envChecksum(ctx, envFrom)
+	if err != nil {
+		return 0, err
+	}
+
+	var rolloutDelay time.Duration
+	_, err = ir.apply(ctx, dep, func() error {
+		// The selector is immutable, but never changes since it's always
+		// set to the same labels.
+		dep.Spec.Selector = &metav1.LabelSelector{
+			MatchLabels: selector,
 		}
 
 		dep.Labels = labels
+		dep.Annotations = map[string]string{AnubisVersionAnnotation: version}
 
 		// Only one replica is supported by anubis currently
 		dep.Spec.Replicas = ptr.To(int32(1))
-		dep.Spec.Strategy = appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+		dep.Spec.RevisionHistoryLimit = icfg.RevisionHistoryLimit
+		dep.Spec.MinReadySeconds = *icfg.MinReadySeconds
+		dep.Spec.Strategy = ptr.Deref(icfg.Strategy, appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType})
 
 		envVars := maps.Clone(ir.cfg.EnvironmentVariables)
 		if envVars == nil {
 			envVars = make(map[string]string)
 		}
+		maps.Copy(envVars, icfg.Env)
 
 		// We override/set a few values controlled by us but also that have
 		// their own annotation configuration values.
-		envVars["BIND"] = ":8080"
-		envVars["DIFFICULTY"] = strconv.Itoa(*icfg.Difficulty)
+		envVars["BIND"] = ":" + strconv.Itoa(int(*icfg.Port))
+		envVars["DIFFICULTY"] = strconv.Itoa(getDifficulty(ir.cfg, current, icfg))
 		envVars["METRICS_BIND"] = ":" + strconv.Itoa(int(*icfg.MetricsPort))
+		if !*icfg.MetricsEnabled {
+			envVars["METRICS_BIND"] = "127.0.0.1" + envVars["METRICS_BIND"]
+		}
 		envVars["SERVE_ROBOTS_TXT"] = strconv.FormatBool(*icfg.ServeRobotsTxt)
-		envVars["TARGET"] = target
+		envVars["TARGET"] = b.target
 		envVars["OG_PASSTHROUGH"] = strconv.FormatBool(*icfg.OGPassthrough)
-
-		cEnvVars := make([]corev1.EnvVar, 0, len(envVars))
-		for k, v := range envVars {
-			cEnvVars = append(cEnvVars, corev1.EnvVar{
-				Name:  k,
-				Value: v,
-			})
+		if icfg.OGExpiryTime != nil {
+			envVars["OG_EXPIRY_TIME"] = icfg.OGExpiryTime.String()
+		}
+		if icfg.CookieDomain != nil {
+			envVars["COOKIE_DOMAIN"] = *icfg.CookieDomain
+		}
+		if icfg.CookieSameSite != nil {
+			envVars["COOKIE_SAME_SITE"] = *icfg.CookieSameSite
+		}
+		if icfg.CookiePartitioned != nil {
+			envVars["COOKIE_PARTITIONED"] = strconv.FormatBool(*icfg.CookiePartitioned)
+		}
+		if icfg.CookiePrefix != nil {
+			envVars["COOKIE_PREFIX"] = *icfg.CookiePrefix
+		}
+		if icfg.CookieExpirationTime != nil {
+			envVars["COOKIE_EXPIRATION_TIME"] = icfg.CookieExpirationTime.String()
+		}
+		if icfg.WebmasterEmail != nil {
+			envVars["WEBMASTER_EMAIL"] = *icfg.WebmasterEmail
+		}
+		if *icfg.TargetInsecureSkipVerify {
+			envVars["TARGET_INSECURE_SKIP_VERIFY"] = "true"
+		}
+		if len(icfg.RedirectDomains) > 0 {
+			envVars["REDIRECT_DOMAINS"] = strings.Join(icfg.RedirectDomains, ",")
+		}
+		if icfg.BasePrefix != nil && *icfg.BasePrefix != "" {
+			envVars["BASE_PREFIX"] = *icfg.BasePrefix
+		}
+		if icfg.TargetHost != nil {
+			envVars["TARGET_HOST"] = *icfg.TargetHost
+			if *icfg.TargetScheme == config.TargetSchemeHTTPS {
+				envVars["TARGET_SNI"] = *icfg.TargetHost
+			}
 		}
 
-		dep.Spec.Template = corev1.PodTemplateSpec{
-			ObjectMeta: metav1.ObjectMeta{Labels: labels, Annotations: ir.cfg.Annotations},
+		probes := getProbes(icfg)
+		tmpl := corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: ir.getPodLabels(selector), Annotations: maps.Clone(ir.cfg.Annotations)},
 			Spec: corev1.PodSpec{
 				Containers: []corev1.Container{{
-					Name:  "main",
-					Image: ir.cfg.AnubisImage + ":" + ir.cfg.AnubisVersion,
-					Env:   cEnvVars,
-					ReadinessProbe: &corev1.Probe{
-						FailureThreshold: 3,
-						ProbeHandler: corev1.ProbeHandler{
-							HTTPGet: &corev1.HTTPGetAction{
-								//nolint:gosec // Why: Not a possible overflow.
-								Port: intstr.FromInt32(int32(*icfg.MetricsPort)),
-								Path: "/metrics",
-							},
-						},
-					},
-					EnvFrom: ir.getEnvFrom(icfg),
-					Ports: []corev1.ContainerPort{
-						{Name: "http", ContainerPort: 8080},
-						//nolint:gosec // Why: Not a possible overflow.
-						{Name: "http-metrics", ContainerPort: int32(*icfg.MetricsPort)},
-					},
-					VolumeMounts: ir.getVolumeMounts(),
+					Name:            "main",
+					Image:           ir.getImage(icfg, version),
+					ImagePullPolicy: ir.cfg.AnubisImagePullPolicy,
+					Env:             envVarsFromMap(envVars),
+					ReadinessProbe:  probes.Readiness,
+					LivenessProbe:   probes.Liveness,
+					StartupProbe:    probes.Startup,
+					EnvFrom:         envFrom,
+					Resources:       ir.getResources(icfg),
+					Ports:           getPorts(icfg),
+					VolumeMounts:    ir.getVolumeMounts(),
 					SecurityContext: &corev1.SecurityContext{
 						AllowPrivilegeEscalation: ptr.To(false),
 						RunAsUser:                ptr.To(int64(1000)),
@@ -435,53 +1423,301 @@ func (ir *IngressReconciler) reconcileDeployment(ctx context.Context, target str
 						SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
 					},
 				}},
-				Volumes: ir.getVolumes(),
+				ImagePullSecrets:          ir.getImagePullSecrets(),
+				Volumes:                   ir.getVolumes(),
+				NodeSelector:              ir.getNodeSelector(icfg),
+				Tolerations:               ir.getTolerations(icfg),
+				Affinity:                  ir.getAffinity(icfg),
+				PriorityClassName:         ptr.Deref(icfg.PriorityClassName, ir.cfg.AnubisPriorityClassName),
+				TopologySpreadConstraints: ir.getTopologySpreadConstraints(icfg, b, req),
 			},
 		}
+		//nolint:errcheck // Why: Validated when loading the configuration.
+		sidecars, _ := ir.cfg.GetSidecars()
+		tmpl.Spec.Containers = append(tmpl.Spec.Containers, sidecars...)
+		tmpl.Spec.TerminationGracePeriodSeconds = ptr.To(int64(*icfg.TerminationGracePeriod / time.Second))
+		if ir.cfg.AnubisRuntimeClassName != "" {
+			tmpl.Spec.RuntimeClassName = ptr.To(ir.cfg.AnubisRuntimeClassName)
+		}
+		if envChecksum != "" {
+			if tmpl.Annotations == nil {
+				tmpl.Annotations = make(map[string]string)
+			}
+			tmpl.Annotations[EnvChecksumAnnotation] = envChecksum
+		}
+		if policyChecksum != "" {
+			ir.applyPolicy(&tmpl, req, policyChecksum)
+		}
+		if icfg.SigningKeySecret != nil {
+			applySigningKey(&tmpl, *icfg.SigningKeySecret)
+		}
+
+		// Changing the template of an existing deployment rolls its pods,
+		// which is subject to the rollout limit, and changing its image to
+		// the batches of image rollouts. New deployments are always created
+		// right away.
+		if exists && !equality.Semantic.DeepDerivative(tmpl, current.Spec.Template) {
+			if containerImage(&tmpl.Spec, "main") != containerImage(&current.Spec.Template.Spec, "main") {
+				var err error
+				if rolloutDelay, err = ir.images.reserve(ctx, crclient.ObjectKeyFromObject(dep)); err != nil {
+					return err
+				}
+				if rolloutDelay > 0 {
+					return errRolloutDeferred
+				}
+			}
+			if rolloutDelay = ir.rollouts.reserve(); rolloutDelay > 0 {
+				return errRolloutDeferred
+			}
+		}
+		dep.Spec.Template = tmpl
 
 		return nil
 	})
-	return err
+	if errors.Is(err, errRolloutDeferred) {
+		return rolloutDelay, nil
+	}
+	return rolloutDelay, err
+}
+
+// checkDeployment emits a warning event on the provided ingress when
+// its deployment with the provided name is failing to roll out, e.g.,
+// because its image can't be pulled or its pods can't be scheduled.
+func (ir *IngressReconciler) checkDeployment(ctx context.Context, ing *networkingv1.Ingress, name string) error {
+	dep := &appsv1.Deployment{}
+	key := crclient.ObjectKey{Namespace: ir.cfg.Namespace, Name: name}
+	if err := ir.client.Get(ctx, key, dep); err != nil {
+		return crclient.IgnoreNotFound(err)
+	}
+
+	for _, c := range dep.Status.Conditions {
+		failing := (c.Type == appsv1.DeploymentProgressing && c.Status == corev1.ConditionFalse) ||
+			(c.Type == appsv1.DeploymentReplicaFailure && c.Status == corev1.ConditionTrue)
+		if failing {
+			ir.recorder.Eventf(ing, dep, corev1.EventTypeWarning, "DeploymentNotReady", "Reconcile",
+				"Deployment %s is not ready: %s", dep.Name, c.Message)
+		}
+	}
+
+	return nil
 }
 
-// reconcileService ensures that the service exists
-func (ir *IngressReconciler) reconcileService(ctx context.Context, req reconcile.Request) error {
+// reconcileService ensures that the service of the provided backend
+// exists
+func (ir *IngressReconciler) reconcileService(ctx context.Context, b *anubisBackend, icfg *config.IngressConfig,
+	req reconcile.Request) error {
 	serv := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "ia-" + req.Name,
+			Name:      b.name,
 			Namespace: ir.cfg.Namespace,
 		},
 	}
 
-	labels := map[string]string{
-		"app.kubernetes.io/instance": "anubis",
-		"app.kubernetes.io/name":     "anubis",
-		ManagedLabel:                 "true",
-		OwningLabel:                  req.Namespace + "--" + req.Name,
-	}
+	selector := b.appLabels(req)
+	labels := maps.Clone(selector)
+	maps.Copy(labels, ownerLabels(req))
 
-	_, err := controllerutil.CreateOrUpdate(ctx, ir.client, serv, func() error {
+	_, err := ir.apply(ctx, serv, func() error {
+		//nolint:gosec // Why: Not a possible overflow.
+		port := int32(*icfg.Port)
 		serv.Spec.Ports = []corev1.ServicePort{{
 			Name:       "http",
-			Port:       8080,
+			Port:       port,
 			Protocol:   corev1.ProtocolTCP,
 			TargetPort: intstr.FromString("http"),
 		}}
 
-		serv.Spec.Selector = labels
+		serv.Labels = labels
+		serv.Spec.Selector = selector
 		serv.Spec.Type = corev1.ServiceTypeClusterIP
+		serv.Spec.IPFamilies = ir.cfg.ServiceIPFamilies
+		if ir.cfg.ServiceIPFamilyPolicy != "" {
+			serv.Spec.IPFamilyPolicy = ptr.To(ir.cfg.ServiceIPFamilyPolicy)
+		}
 
 		return nil
 	})
 	return err
 }
 
-// reconcileChildIngress reconciles the child (managed) Ingress
-func (ir *IngressReconciler) reconcileChildIngress(ctx context.Context, origIng *networkingv1.Ingress,
+// deleteDirectService deletes the service created by
+// [IngressReconciler.reconcileDirectService] for the backend with the
+// provided name, if it exists.
+func (ir *IngressReconciler) deleteDirectService(ctx context.Context, name string) error {
+	svc := &corev1.Service{}
+	key := crclient.ObjectKey{Namespace: ir.cfg.Namespace, Name: name + "-direct"}
+	if err := ir.client.Get(ctx, key, svc); err == nil {
+		if err := ir.client.Delete(ctx, svc); err != nil {
+			return fmt.Errorf("failed to delete direct service: %w", err)
+		}
+	} else if err := crclient.IgnoreNotFound(err); err != nil {
+		return fmt.Errorf("failed to check existence of direct service: %w", err)
+	}
+
+	return nil
+}
+
+// checkShadowMode returns an error if the provided ingress configuration
+// uses [config.ModeShadow] with a wrapped ingress controller that
+// requests can't be mirrored with. Mirroring is configured through an
+// ingress-nginx annotation, which has no equivalent in other dialects.
+func (ir *IngressReconciler) checkShadowMode(icfg *config.IngressConfig) error {
+	if *icfg.Mode != config.ModeShadow || *icfg.Bypass {
+		return nil
+	}
+	if dialect := translate.Dialect(ir.cfg.WrappedIngressDialect); dialect != translate.DialectNginx {
+		return fmt.Errorf("shadow mode is not supported with wrapped ingress dialect %q", dialect)
+	}
+	return nil
+}
+
+// reconcileDirectService ensures that, when using [config.ModeShadow] or
+// bypassing anubis, an ExternalName service pointing at the original
+// backend exists for the child ingress to route traffic to. Otherwise,
+// it ensures that it does not exist.
+func (ir *IngressReconciler) reconcileDirectService(ctx context.Context, b *anubisBackend,
 	icfg *config.IngressConfig, req reconcile.Request) error {
+	if *icfg.Mode != config.ModeShadow && !*icfg.Bypass {
+		return ir.deleteDirectService(ctx, b.name)
+	}
+
+	u, err := url.Parse(b.target)
+	if err != nil {
+		return fmt.Errorf("failed to parse target %q: %w", b.target, err)
+	}
+
+	port, err := strconv.ParseInt(u.Port(), 10, 32)
+	if err != nil {
+		return fmt.Errorf("failed to parse port of target %q: %w", b.target, err)
+	}
+
+	serv := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.name + "-direct",
+			Namespace: ir.cfg.Namespace,
+		},
+	}
+
+	labels := map[string]string{
+		ManagedLabel:        "true",
+		OwnerNamespaceLabel: req.Namespace,
+		OwnerNameLabel:      req.Name,
+	}
+
+	_, err = ir.apply(ctx, serv, func() error {
+		serv.Labels = labels
+		serv.Spec.Type = corev1.ServiceTypeExternalName
+		serv.Spec.ExternalName = u.Hostname()
+		//nolint:gosec // Why: ParseInt ensures this fits in 32 bits.
+		port := int32(port)
+		serv.Spec.Ports = []corev1.ServicePort{{
+			Name:       "http",
+			Port:       port,
+			Protocol:   corev1.ProtocolTCP,
+			TargetPort: intstr.FromInt32(port),
+		}}
+
+		return nil
+	})
+	return err
+}
+
+// stripAnnotations returns a copy of the provided annotations without
+// those matching any of the provided patterns. Patterns ending in "*"
+// match all annotations with that prefix.
+func stripAnnotations(annotations map[string]string, patterns ...[]string) map[string]string {
+	all := slices.Concat(patterns...)
+	matches := func(k string) bool {
+		return slices.ContainsFunc(all, func(p string) bool {
+			if prefix, ok := strings.CutSuffix(p, "*"); ok {
+				return strings.HasPrefix(k, prefix)
+			}
+			return k == p
+		})
+	}
+
+	resp := make(map[string]string, len(annotations))
+	for k, v := range annotations {
+		if !matches(k) {
+			resp[k] = v
+		}
+	}
+	return resp
+}
+
+// prefixPatterns returns the [stripAnnotations] patterns matching the
+// provided prefixes.
+func prefixPatterns(prefixes []string) []string {
+	patterns := make([]string, 0, len(prefixes))
+	for _, p := range prefixes {
+		patterns = append(patterns, p+"*")
+	}
+	return patterns
+}
+
+// checkIngressClass ensures that the ingress class with the provided
+// name, used by the child ingress of ing, exists. Otherwise, no ingress
+// controller would ever serve the child ingress, so a warning event is
+// emitted and an error returned to retry later.
+func (ir *IngressReconciler) checkIngressClass(ctx context.Context, ing *networkingv1.Ingress, name *string) error {
+	if name == nil {
+		// The default ingress class is used, if any.
+		return nil
+	}
+
+	var ic networkingv1.IngressClass
+	if err := ir.client.Get(ctx, crclient.ObjectKey{Name: *name}, &ic); err != nil {
+		if apierrors.IsNotFound(err) {
+			ir.recorder.Eventf(ing, nil, corev1.EventTypeWarning, "IngressClassNotFound", "Reconcile",
+				"Ingress class %q of the wrapped ingress does not exist", *name)
+		}
+		return fmt.Errorf("failed to get ingress class %q: %w", *name, err)
+	}
+
+	return nil
+}
+
+// childIngressClass returns the ingress class of the child ingress of
+// the provided ingress: [config.IngressConfig.IngressClass] if set,
+// [config.Config.WrappedIngressClassName] for ingresses using our
+// ingress class and the original ingress class for ingresses that opted
+// in through [config.AnnotationKeyProtect]. The original ingress of the
+// latter is still served by its ingress controller, so a child of the
+// same class would leave Anubis bypassed and a terminal error is
+// returned instead.
+func (ir *IngressReconciler) childIngressClass(ctx context.Context, origIng *networkingv1.Ingress,
+	icfg *config.IngressConfig) (*string, error) {
+	ours, err := ir.hasIngressClass(ctx, origIng)
+	if err != nil {
+		return nil, err
+	}
+	className := origIng.Spec.IngressClassName
+	switch {
+	case icfg.IngressClass != nil:
+		className = icfg.IngressClass
+	case ours:
+		className = &ir.cfg.WrappedIngressClassName
+	}
+	if ours || !ptr.Equal(className, origIng.Spec.IngressClassName) {
+		return className, nil
+	}
+
+	return nil, reconcile.TerminalError(fmt.Errorf(
+		"ingresses opted in through %s need %s set to another ingress class than %q, or %s set to %q, "+
+			"otherwise the original ingress is served instead of anubis",
+		config.AnnotationKeyProtect, config.AnnotationKeyIngressClass, ptr.Deref(origIng.Spec.IngressClassName, ""),
+		config.AnnotationKeyInterposition, config.InterpositionInPlace))
+}
+
+// reconcileChildIngress reconciles the child (managed) Ingress, pointing
+// each of its backends at the anubis instance of the matching backend in
+// backends and its TLS secrets at their copies in tlsSecrets (see
+// [IngressReconciler.reconcileTLSSecrets]).
+func (ir *IngressReconciler) reconcileChildIngress(ctx context.Context, origIng *networkingv1.Ingress,
+	icfg *config.IngressConfig, backends []*anubisBackend, tlsSecrets map[string]string, req reconcile.Request) error {
 	ing := &networkingv1.Ingress{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "ia-" + req.Name,
+			Name:      backends[0].name,
 			Namespace: ir.cfg.Namespace,
 		},
 	}
@@ -490,45 +1726,91 @@ func (ir *IngressReconciler) reconcileChildIngress(ctx context.Context, origIng
 		"app.kubernetes.io/instance": "anubis",
 		"app.kubernetes.io/name":     "anubis",
 		ManagedLabel:                 "true",
-		OwningLabel:                  req.Namespace + "--" + req.Name,
+		OwnerNamespaceLabel:          req.Namespace,
+		OwnerNameLabel:               req.Name,
 	}
 
-	_, err := controllerutil.CreateOrUpdate(ctx, ir.client, ing, func() error {
+	className, err := ir.childIngressClass(ctx, origIng, icfg)
+	if err != nil {
+		return err
+	}
+	if err := ir.checkIngressClass(ctx, origIng, className); err != nil {
+		return err
+	}
+
+	var untranslated []string
+	op, err := ir.apply(ctx, ing, func() error {
 		ing.Spec = *origIng.Spec.DeepCopy()
-		ing.Annotations = origIng.DeepCopy().GetAnnotations()
 
-		if icfg.IngressClass != nil {
-			ing.Spec.IngressClassName = icfg.IngressClass
-		} else {
-			ing.Spec.IngressClassName = &ir.cfg.WrappedIngressClassName
+		// Translate ingress-nginx annotations for the wrapped ingress
+		// controller, if it isn't ingress-nginx.
+		var err error
+		ing.Annotations, untranslated, err = translate.Annotations(
+			translate.Dialect(ir.cfg.WrappedIngressDialect),
+			stripAnnotations(origIng.GetAnnotations(), ir.cfg.StripAnnotations, icfg.StripAnnotations,
+				prefixPatterns(ir.cfg.StripAnnotationPrefixes), prefixPatterns([]string{StateAnnotationPrefix})),
+		)
+		if err != nil {
+			return err
+		}
+		delete(ing.Annotations, config.AnnotationKeyProtect.String())
+		if len(ir.cfg.ChildIngressAnnotations) > 0 || len(icfg.ChildAnnotations) > 0 {
+			if ing.Annotations == nil {
+				ing.Annotations = make(map[string]string)
+			}
+			maps.Copy(ing.Annotations, ir.cfg.ChildIngressAnnotations)
+			maps.Copy(ing.Annotations, icfg.ChildAnnotations)
 		}
 
-		// Ensure our labels are set.
-		if ing.Labels == nil {
-			ing.Labels = make(map[string]string)
+		ing.Spec.IngressClassName = className
+		for i := range ing.Spec.TLS {
+			if name, ok := tlsSecrets[ing.Spec.TLS[i].SecretName]; ok {
+				ing.Spec.TLS[i].SecretName = name
+			}
+		}
+
+		ing.Labels = labels
+
+		// In shadow mode, traffic goes directly to the original backends
+		// and Anubis only receives a mirrored copy of each request. Since
+		// only one mirror target can be set, all of them go to the Anubis
+		// instance of the first backend. When bypassed, it receives
+		// nothing at all.
+		direct := *icfg.Bypass || *icfg.Mode == config.ModeShadow
+		if *icfg.Mode == config.ModeShadow && !*icfg.Bypass {
+			if ing.Annotations == nil {
+				ing.Annotations = make(map[string]string)
+			}
+			ing.Annotations[nginxMirrorTargetAnnotation] = fmt.Sprintf(
+				"http://%s.%s.svc.cluster.local:%d$request_uri", backends[0].name, ir.cfg.Namespace, *icfg.Port,
+			)
 		}
-		maps.Insert(ing.Labels, maps.All(labels))
 
 		// Ensure all hosts point to us instead of whatever was originally
 		// set.
-		backend := &networkingv1.IngressServiceBackend{
-			Name: "ia-" + req.Name,
-			Port: networkingv1.ServiceBackendPort{
-				Name: "http",
-			},
-		}
-		if ing.Spec.DefaultBackend != nil {
-			ing.Spec.DefaultBackend.Service = backend
-		}
-		for i, r := range ing.Spec.Rules {
-			if r.HTTP == nil {
-				continue // TODO(jaredallard): Validate this case.
+		setServiceBackends(&ing.Spec, func(isb *networkingv1.IngressServiceBackend) *networkingv1.IngressServiceBackend {
+			b := findBackend(backends, isb)
+			if b == nil {
+				return isb
 			}
-			for j := range r.HTTP.Paths {
-				ing.Spec.Rules[i].HTTP.Paths[j].Backend.Service = backend
+
+			name := b.name
+			if direct {
+				name += "-direct"
 			}
-		}
+			return &networkingv1.IngressServiceBackend{Name: name, Port: networkingv1.ServiceBackendPort{Name: "http"}}
+		})
 		return nil
 	})
-	return err
+	if err != nil {
+		return err
+	}
+
+	if op != controllerutil.OperationResultNone && len(untranslated) > 0 {
+		ir.recorder.Eventf(origIng, nil, corev1.EventTypeWarning, "UntranslatedAnnotations", "Reconcile",
+			"Annotations not supported by the %s ingress controller were ignored: %s",
+			ir.cfg.WrappedIngressDialect, strings.Join(untranslated, ", "))
+	}
+
+	return nil
 }
@@ -0,0 +1,1097 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	"go.rgst.io/jaredallard/slogext/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/managedfields"
+	"k8s.io/apimachinery/pkg/util/validation"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// fakeIndexer registers field indexes with a [fake.ClientBuilder].
+type fakeIndexer struct{ b *fake.ClientBuilder }
+
+func (fi fakeIndexer) IndexField(_ context.Context, obj crclient.Object, field string, fn crclient.IndexerFunc) error {
+	fi.b.WithIndex(obj, field, fn)
+	return nil
+}
+
+// testConfig returns the configuration loaded with the provided
+// overrides.
+func testConfig(t *testing.T, overrides map[string]string) *config.Config {
+	t.Helper()
+
+	cfg, err := config.Load(overrides)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	return cfg
+}
+
+// testIngress returns an ingress "default/web" using our ingress class
+// with a single backend, the service "web" on port 80.
+func testIngress(cfg *config.Config, annotations map[string]string) *networkingv1.Ingress {
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Annotations: annotations},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: ptr.To(cfg.IngressClassNames[0]),
+			DefaultBackend: &networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{Name: "web", Port: networkingv1.ServiceBackendPort{Number: 80}},
+			},
+		},
+	}
+}
+
+// newTestReconciler returns an [IngressReconciler] using a fake client
+// containing objs and the ingress classes of cfg.
+func newTestReconciler(t *testing.T, cfg *config.Config, objs ...crclient.Object) *IngressReconciler {
+	t.Helper()
+
+	objs = append(objs,
+		&networkingv1.IngressClass{
+			ObjectMeta: metav1.ObjectMeta{Name: cfg.IngressClassNames[0]},
+			Spec:       networkingv1.IngressClassSpec{Controller: cfg.ControllerName},
+		},
+		&networkingv1.IngressClass{
+			ObjectMeta: metav1.ObjectMeta{Name: cfg.WrappedIngressClassName},
+			Spec:       networkingv1.IngressClassSpec{Controller: "k8s.io/ingress-nginx"},
+		},
+	)
+	// The schema of the default type converter fails to apply network
+	// policies, so the types are deduced from the objects instead.
+	b := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(objs...).
+		WithStatusSubresource(&networkingv1.Ingress{}).WithTypeConverters(managedfields.NewDeducedTypeConverter())
+	if err := indexOwners(t.Context(), fakeIndexer{b}); err != nil {
+		t.Fatalf("failed to index owners: %v", err)
+	}
+	if err := indexReferences(t.Context(), cfg, fakeIndexer{b}); err != nil {
+		t.Fatalf("failed to index references: %v", err)
+	}
+	client := b.Build()
+
+	log := slogext.New()
+	return &IngressReconciler{
+		cluster:  LocalClusterName,
+		log:      log,
+		cfg:      cfg,
+		client:   client,
+		recorder: events.NewFakeRecorder(100),
+		rollouts: newRolloutLimiter(cfg),
+		images:   newImageRollout(log, cfg, client),
+		breaker:  newCircuitBreaker(cfg),
+		verifier: newRouteVerifier(cfg, LocalClusterName),
+		degraded: newDegradedTracker(cfg),
+		applied:  newAppliedVersions(),
+	}
+}
+
+// reconcileTestIngress reconciles the ingress with the provided key
+// until it no longer asks to be requeued, failing the test on errors.
+func reconcileTestIngress(t *testing.T, ir *IngressReconciler, key types.NamespacedName) reconcile.Result {
+	t.Helper()
+
+	for {
+		res, err := ir.Reconcile(t.Context(), reconcile.Request{NamespacedName: key})
+		if err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+		//nolint:staticcheck // Why: Set by Reconcile after adding the finalizer.
+		if !res.Requeue {
+			return res
+		}
+	}
+}
+
+// testDeployment returns the deployment with the provided name.
+func testDeployment(t *testing.T, ir *IngressReconciler, name string) *appsv1.Deployment {
+	t.Helper()
+
+	var dep appsv1.Deployment
+	if err := ir.client.Get(t.Context(), types.NamespacedName{Namespace: ir.cfg.Namespace, Name: name}, &dep); err != nil {
+		t.Fatalf("failed to get deployment: %v", err)
+	}
+	return &dep
+}
+
+// testDeploymentEnv returns the environment of the anubis container of
+// the deployment with the provided name.
+func testDeploymentEnv(t *testing.T, ir *IngressReconciler, name string) map[string]string {
+	t.Helper()
+
+	dep := testDeployment(t, ir, name)
+	env := make(map[string]string)
+	for _, e := range dep.Spec.Template.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	return env
+}
+
+func TestResourceName(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		key    types.NamespacedName
+		want   string
+	}{
+		{
+			name:   "should append a hash of the namespace and name",
+			prefix: "ia-",
+			key:    types.NamespacedName{Namespace: "default", Name: "web"},
+			want:   "ia-web-82b3ade9",
+		},
+		{
+			name:   "should not collide across namespaces",
+			prefix: "ia-",
+			key:    types.NamespacedName{Namespace: "other", Name: "web"},
+			want:   "ia-web-666c9f84",
+		},
+		{
+			name:   "should replace dots",
+			prefix: "ia-",
+			key:    types.NamespacedName{Namespace: "default", Name: "www.example.com"},
+			want:   "ia-www-example-com-89c62a6a",
+		},
+		{
+			name:   "should truncate long names",
+			prefix: "ia-",
+			key:    types.NamespacedName{Namespace: "default", Name: strings.Repeat("a", 60)},
+			want:   "ia-" + strings.Repeat("a", 44) + "-bf5d8047",
+		},
+		{
+			name:   "should trim dashes left by truncation",
+			prefix: "ia-",
+			key:    types.NamespacedName{Namespace: "default", Name: strings.Repeat("a", 43) + "-" + strings.Repeat("b", 10)},
+			want:   "ia-" + strings.Repeat("a", 43) + "-d82be897",
+		},
+		{
+			name:   "should leave room for long prefixes",
+			prefix: "abcdefghijklmnopqrstuvwxyz-ab-",
+			key:    types.NamespacedName{Namespace: "default", Name: strings.Repeat("a", 60)},
+			want:   "abcdefghijklmnopqrstuvwxyz-ab-" + strings.Repeat("a", 17) + "-bf5d8047",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ir := &IngressReconciler{cfg: &config.Config{ResourcePrefix: tt.prefix}}
+			got := ir.resourceName(tt.key)
+			if got != tt.want {
+				t.Errorf("resourceName() = %q, want %q", got, tt.want)
+			}
+			if len(got) > maxResourceNameLength {
+				t.Errorf("resourceName() = %q is longer than %d characters", got, maxResourceNameLength)
+			}
+			if errs := validation.IsDNS1035Label(got); len(errs) > 0 {
+				t.Errorf("resourceName() = %q is not a valid service name: %v", got, errs)
+			}
+		})
+	}
+}
+
+func TestCheckShadowMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect string
+		mode    config.Mode
+		bypass  bool
+		wantErr bool
+	}{
+		{name: "enforce with nginx", dialect: "nginx", mode: config.ModeEnforce},
+		{name: "enforce with traefik", dialect: "traefik", mode: config.ModeEnforce},
+		{name: "shadow with nginx", dialect: "nginx", mode: config.ModeShadow},
+		{name: "shadow with traefik", dialect: "traefik", mode: config.ModeShadow, wantErr: true},
+		{name: "shadow with kong", dialect: "kong", mode: config.ModeShadow, wantErr: true},
+		{name: "bypassed shadow with traefik", dialect: "traefik", mode: config.ModeShadow, bypass: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ir := &IngressReconciler{cfg: &config.Config{WrappedIngressDialect: tt.dialect}}
+			err := ir.checkShadowMode(&config.IngressConfig{Mode: ptr.To(tt.mode), Bypass: ptr.To(tt.bypass)})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkShadowMode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChildIngressClass(t *testing.T) {
+	cfg := &config.Config{
+		IngressClassNames:       []string{"anubis"},
+		ControllerName:          "example.com/ingress-anubis",
+		WrappedIngressClassName: "nginx",
+	}
+	ic := &networkingv1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "anubis"},
+		Spec:       networkingv1.IngressClassSpec{Controller: cfg.ControllerName},
+	}
+	ir := &IngressReconciler{cfg: cfg, client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(ic).Build()}
+
+	tests := []struct {
+		name     string
+		class    *string
+		override *string
+		want     string
+		wantErr  bool
+	}{
+		{name: "our ingress class", class: ptr.To("anubis"), want: "nginx"},
+		{name: "our ingress class with override", class: ptr.To("anubis"), override: ptr.To("traefik"), want: "traefik"},
+		{name: "protected", class: ptr.To("nginx"), wantErr: true},
+		{name: "protected with override", class: ptr.To("nginx"), override: ptr.To("nginx-anubis"), want: "nginx-anubis"},
+		{name: "protected with same override", class: ptr.To("nginx"), override: ptr.To("nginx"), wantErr: true},
+		{name: "protected default class", wantErr: true},
+		{name: "protected default class with override", override: ptr.To("nginx"), want: "nginx"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ing := &networkingv1.Ingress{Spec: networkingv1.IngressSpec{IngressClassName: tt.class}}
+			got, err := ir.childIngressClass(t.Context(), ing, &config.IngressConfig{IngressClass: tt.override})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("childIngressClass() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, reconcile.TerminalError(nil)) {
+					t.Errorf("childIngressClass() error = %v, want a terminal error", err)
+				}
+				return
+			}
+			if ptr.Deref(got, "") != tt.want {
+				t.Errorf("childIngressClass() = %q, want %q", ptr.Deref(got, ""), tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileShadowMode(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       config.Mode
+		wantMirror string
+		wantTarget string
+	}{
+		{
+			name:       "enforce",
+			mode:       config.ModeEnforce,
+			wantTarget: "ia-web-82b3ade9",
+		},
+		{
+			name:       "shadow",
+			mode:       config.ModeShadow,
+			wantMirror: "http://ia-web-82b3ade9.ingress-anubis.svc.cluster.local:8080$request_uri",
+			wantTarget: "ia-web-82b3ade9-direct",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, map[string]string{"NAMESPACE": "ingress-anubis"})
+			ing := testIngress(cfg, map[string]string{config.AnnotationKeyMode.String(): string(tt.mode)})
+			ir := newTestReconciler(t, cfg, ing)
+			reconcileTestIngress(t, ir, crclient.ObjectKeyFromObject(ing))
+
+			var child networkingv1.Ingress
+			if err := ir.client.Get(t.Context(), types.NamespacedName{Namespace: "ingress-anubis", Name: "ia-web-82b3ade9"}, &child); err != nil {
+				t.Fatalf("failed to get child ingress: %v", err)
+			}
+			if got := child.Annotations[nginxMirrorTargetAnnotation]; got != tt.wantMirror {
+				t.Errorf("mirror target = %q, want %q", got, tt.wantMirror)
+			}
+			if got := child.Spec.DefaultBackend.Service.Name; got != tt.wantTarget {
+				t.Errorf("child ingress backend = %q, want %q", got, tt.wantTarget)
+			}
+
+			var svc corev1.Service
+			err := ir.client.Get(t.Context(), types.NamespacedName{Namespace: "ingress-anubis", Name: "ia-web-82b3ade9-direct"}, &svc)
+			if tt.mode == config.ModeShadow && err != nil {
+				t.Errorf("failed to get direct service: %v", err)
+			}
+			if tt.mode == config.ModeEnforce && !apierrors.IsNotFound(err) {
+				t.Errorf("direct service exists in enforce mode: %v", err)
+			}
+		})
+	}
+}
+
+func TestReconcileDeploymentEnv(t *testing.T) {
+	// hosts sets the hosts of the rules of an ingress.
+	hosts := func(hosts ...string) func(*networkingv1.Ingress) {
+		return func(ing *networkingv1.Ingress) {
+			for _, h := range hosts {
+				ing.Spec.Rules = append(ing.Spec.Rules, networkingv1.IngressRule{Host: h})
+			}
+		}
+	}
+
+	tests := []struct {
+		name        string
+		annotations map[config.AnnotationKey]string
+		modify      func(*networkingv1.Ingress)
+
+		// want contains the expected environment variables, with an empty
+		// value for those that shouldn't be set.
+		want map[string]string
+	}{
+		{
+			name: "should set the defaults",
+			want: map[string]string{
+				"BIND":           ":8080",
+				"DIFFICULTY":     "4",
+				"METRICS_BIND":   ":9090",
+				"TARGET":         "http://web.default.svc.cluster.local:80",
+				"COOKIE_DOMAIN":  "",
+				"OG_PASSTHROUGH": "true",
+			},
+		},
+		{
+			name:        "should set the cookie domain",
+			annotations: map[config.AnnotationKey]string{config.AnnotationKeyCookieDomain: "example.com"},
+			want:        map[string]string{"COOKIE_DOMAIN": "example.com"},
+		},
+		{
+			name:        "should derive the cookie domain from the hosts",
+			annotations: map[config.AnnotationKey]string{config.AnnotationKeyCookieDomain: config.CookieDomainAuto},
+			modify:      hosts("a.example.co.uk", "b.example.co.uk"),
+			want:        map[string]string{"COOKIE_DOMAIN": "example.co.uk"},
+		},
+		{
+			name:        "should use https targets",
+			annotations: map[config.AnnotationKey]string{config.AnnotationKeyTargetScheme: "https"},
+			want: map[string]string{
+				"TARGET":                      "https://web.default.svc.cluster.local:80",
+				"TARGET_INSECURE_SKIP_VERIFY": "",
+			},
+		},
+		{
+			name: "should skip verifying https targets",
+			annotations: map[config.AnnotationKey]string{
+				config.AnnotationKeyTargetScheme:             "https",
+				config.AnnotationKeyTargetInsecureSkipVerify: "true",
+			},
+			want: map[string]string{
+				"TARGET":                      "https://web.default.svc.cluster.local:80",
+				"TARGET_INSECURE_SKIP_VERIFY": "true",
+			},
+		},
+		{
+			name:        "should override the target host",
+			annotations: map[config.AnnotationKey]string{config.AnnotationKeyTargetHost: "app.example.com"},
+			want:        map[string]string{"TARGET_HOST": "app.example.com", "TARGET_SNI": ""},
+		},
+		{
+			name: "should override the target host and sni of https targets",
+			annotations: map[config.AnnotationKey]string{
+				config.AnnotationKeyTargetScheme: "https",
+				config.AnnotationKeyTargetHost:   "app.example.com",
+			},
+			want: map[string]string{"TARGET_HOST": "app.example.com", "TARGET_SNI": "app.example.com"},
+		},
+		{
+			name: "should not restrict redirects without hosts",
+			want: map[string]string{"REDIRECT_DOMAINS": ""},
+		},
+		{
+			name:   "should restrict redirects to the hosts",
+			modify: hosts("a.example.com", "b.example.com"),
+			want:   map[string]string{"REDIRECT_DOMAINS": "a.example.com,b.example.com"},
+		},
+		{
+			name:        "should override the redirect domains",
+			annotations: map[config.AnnotationKey]string{config.AnnotationKeyRedirectDomains: "example.com"},
+			modify:      hosts("a.example.com"),
+			want:        map[string]string{"REDIRECT_DOMAINS": "example.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, map[string]string{"NAMESPACE": "ingress-anubis"})
+			annotations := make(map[string]string, len(tt.annotations))
+			for k, v := range tt.annotations {
+				annotations[k.String()] = v
+			}
+			ing := testIngress(cfg, annotations)
+			if tt.modify != nil {
+				tt.modify(ing)
+			}
+			ir := newTestReconciler(t, cfg, ing)
+			reconcileTestIngress(t, ir, crclient.ObjectKeyFromObject(ing))
+
+			env := testDeploymentEnv(t, ir, "ia-web-82b3ade9")
+			for k, want := range tt.want {
+				if got := env[k]; got != want {
+					t.Errorf("%s = %q, want %q", k, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReconcileBypass(t *testing.T) {
+	tests := []struct {
+		name       string
+		bypass     string
+		wantAnubis bool
+		wantTarget string
+	}{
+		{
+			name:       "should route through anubis",
+			bypass:     "false",
+			wantAnubis: true,
+			wantTarget: "ia-web-82b3ade9",
+		},
+		{
+			name:       "should remove anubis and route to the backends",
+			bypass:     "true",
+			wantTarget: "ia-web-82b3ade9-direct",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, map[string]string{"NAMESPACE": "ingress-anubis"})
+			ing := testIngress(cfg, nil)
+			ir := newTestReconciler(t, cfg, ing)
+			key := crclient.ObjectKeyFromObject(ing)
+
+			// Protect the ingress first, so that bypassing it has to remove
+			// anubis.
+			reconcileTestIngress(t, ir, key)
+			if err := ir.client.Get(t.Context(), key, ing); err != nil {
+				t.Fatalf("failed to get ingress: %v", err)
+			}
+			ing.Annotations = map[string]string{config.AnnotationKeyBypass.String(): tt.bypass}
+			if err := ir.client.Update(t.Context(), ing); err != nil {
+				t.Fatalf("failed to update ingress: %v", err)
+			}
+			reconcileTestIngress(t, ir, key)
+
+			anubis := types.NamespacedName{Namespace: "ingress-anubis", Name: "ia-web-82b3ade9"}
+			for _, obj := range []crclient.Object{&appsv1.Deployment{}, &corev1.Service{}} {
+				err := ir.client.Get(t.Context(), anubis, obj)
+				if tt.wantAnubis && err != nil {
+					t.Errorf("failed to get %T: %v", obj, err)
+				}
+				if !tt.wantAnubis && !apierrors.IsNotFound(err) {
+					t.Errorf("%T exists while bypassed: %v", obj, err)
+				}
+			}
+
+			var child networkingv1.Ingress
+			if err := ir.client.Get(t.Context(), anubis, &child); err != nil {
+				t.Fatalf("failed to get child ingress: %v", err)
+			}
+			if got := child.Spec.DefaultBackend.Service.Name; got != tt.wantTarget {
+				t.Errorf("child ingress backend = %q, want %q", got, tt.wantTarget)
+			}
+		})
+	}
+}
+
+func TestStripAnnotations(t *testing.T) {
+	annotations := map[string]string{
+		"cert-manager.io/cluster-issuer":           "letsencrypt",
+		"cert-manager.io/common-name":              "example.com",
+		"external-dns.alpha.kubernetes.io/ttl":     "60",
+		"nginx.ingress.kubernetes.io/ssl-redirect": "true",
+	}
+
+	tests := []struct {
+		name     string
+		patterns [][]string
+		want     map[string]string
+	}{
+		{
+			name: "should keep all annotations without patterns",
+			want: annotations,
+		},
+		{
+			name:     "should strip exact matches",
+			patterns: [][]string{{"cert-manager.io/common-name"}},
+			want: map[string]string{
+				"cert-manager.io/cluster-issuer":           "letsencrypt",
+				"external-dns.alpha.kubernetes.io/ttl":     "60",
+				"nginx.ingress.kubernetes.io/ssl-redirect": "true",
+			},
+		},
+		{
+			name:     "should strip prefixes",
+			patterns: [][]string{{"cert-manager.io/*"}},
+			want: map[string]string{
+				"external-dns.alpha.kubernetes.io/ttl":     "60",
+				"nginx.ingress.kubernetes.io/ssl-redirect": "true",
+			},
+		},
+		{
+			name:     "should combine patterns",
+			patterns: [][]string{{"cert-manager.io/*"}, {"external-dns.alpha.kubernetes.io/ttl"}},
+			want:     map[string]string{"nginx.ingress.kubernetes.io/ssl-redirect": "true"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, stripAnnotations(annotations, tt.patterns...)); diff != "" {
+				t.Errorf("stripAnnotations() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReconcileDeployment(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		annotations map[config.AnnotationKey]string
+
+		// got returns the part of the deployment compared to want.
+		got  func(*appsv1.Deployment) any
+		want any
+	}{
+		{
+			name: "should not set image pull secrets by default",
+			got:  func(dep *appsv1.Deployment) any { return dep.Spec.Template.Spec.ImagePullSecrets },
+			want: []corev1.LocalObjectReference(nil),
+		},
+		{
+			name: "should set image pull secrets",
+			env:  map[string]string{"IMAGE_PULL_SECRETS": "registry,mirror"},
+			got:  func(dep *appsv1.Deployment) any { return dep.Spec.Template.Spec.ImagePullSecrets },
+			want: []corev1.LocalObjectReference{{Name: "registry"}, {Name: "mirror"}},
+		},
+		{
+			name: "should set the default lifecycle",
+			got: func(dep *appsv1.Deployment) any {
+				return []any{dep.Spec.RevisionHistoryLimit, dep.Spec.MinReadySeconds, dep.Spec.Template.Spec.TerminationGracePeriodSeconds}
+			},
+			want: []any{ptr.To(int32(10)), int32(0), ptr.To(int64(30))},
+		},
+		{
+			name: "should override the lifecycle",
+			annotations: map[config.AnnotationKey]string{
+				config.AnnotationKeyRevisionHistoryLimit:   "2",
+				config.AnnotationKeyMinReadySeconds:        "5",
+				config.AnnotationKeyTerminationGracePeriod: "1m",
+			},
+			got: func(dep *appsv1.Deployment) any {
+				return []any{dep.Spec.RevisionHistoryLimit, dep.Spec.MinReadySeconds, dep.Spec.Template.Spec.TerminationGracePeriodSeconds}
+			},
+			want: []any{ptr.To(int32(2)), int32(5), ptr.To(int64(60))},
+		},
+		{
+			name: "should add pod labels without overriding the selector",
+			env:  map[string]string{"POD_LABELS": "team:web,app.kubernetes.io/name:other"},
+			got:  func(dep *appsv1.Deployment) any { return dep.Spec.Template.Labels },
+			want: map[string]string{
+				"app.kubernetes.io/instance": "anubis",
+				"app.kubernetes.io/name":     "anubis",
+				"team":                       "web",
+				ManagedLabel:                 "true",
+				OwnerNamespaceLabel:          "default",
+				OwnerNameLabel:               "web",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := map[string]string{"NAMESPACE": "ingress-anubis"}
+			maps.Copy(env, tt.env)
+			cfg := testConfig(t, env)
+			annotations := make(map[string]string, len(tt.annotations))
+			for k, v := range tt.annotations {
+				annotations[k.String()] = v
+			}
+			ing := testIngress(cfg, annotations)
+			ir := newTestReconciler(t, cfg, ing)
+			reconcileTestIngress(t, ir, crclient.ObjectKeyFromObject(ing))
+
+			if diff := cmp.Diff(tt.want, tt.got(testDeployment(t, ir, "ia-web-82b3ade9"))); diff != "" {
+				t.Errorf("deployment mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReconcileChildIngressAnnotations(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		annotations map[string]string
+		want        map[string]string
+	}{
+		{
+			name:        "should copy the annotations of the ingress",
+			annotations: map[string]string{"nginx.ingress.kubernetes.io/proxy-body-size": "8m"},
+			want:        map[string]string{"nginx.ingress.kubernetes.io/proxy-body-size": "8m"},
+		},
+		{
+			name:        "should add the child ingress annotations",
+			env:         map[string]string{"CHILD_INGRESS_ANNOTATIONS": "nginx.ingress.kubernetes.io/proxy-read-timeout:60"},
+			annotations: map[string]string{"nginx.ingress.kubernetes.io/proxy-body-size": "8m"},
+			want: map[string]string{
+				"nginx.ingress.kubernetes.io/proxy-body-size":    "8m",
+				"nginx.ingress.kubernetes.io/proxy-read-timeout": "60",
+			},
+		},
+		{
+			name: "should prefer the child annotations of the ingress",
+			env:  map[string]string{"CHILD_INGRESS_ANNOTATIONS": "nginx.ingress.kubernetes.io/proxy-read-timeout:60"},
+			annotations: map[string]string{
+				config.AnnotationKeyChildAnnotations.String(): `{"nginx.ingress.kubernetes.io/proxy-read-timeout":"120"}`,
+			},
+			want: map[string]string{
+				config.AnnotationKeyChildAnnotations.String():    `{"nginx.ingress.kubernetes.io/proxy-read-timeout":"120"}`,
+				"nginx.ingress.kubernetes.io/proxy-read-timeout": "120",
+			},
+		},
+		{
+			name: "should strip the annotations of tooling by default",
+			annotations: map[string]string{
+				"kubectl.kubernetes.io/last-applied-configuration": "{}",
+				"argocd.argoproj.io/tracking-id":                   "web:networking.k8s.io/Ingress:default/web",
+				"cert-manager.io/cluster-issuer":                   "letsencrypt",
+				"nginx.ingress.kubernetes.io/proxy-body-size":      "8m",
+			},
+			want: map[string]string{"nginx.ingress.kubernetes.io/proxy-body-size": "8m"},
+		},
+		{
+			name: "should strip the configured prefixes",
+			env:  map[string]string{"STRIP_ANNOTATION_PREFIXES": "nginx.ingress.kubernetes.io/"},
+			annotations: map[string]string{
+				"cert-manager.io/cluster-issuer":              "letsencrypt",
+				"nginx.ingress.kubernetes.io/proxy-body-size": "8m",
+			},
+			want: map[string]string{"cert-manager.io/cluster-issuer": "letsencrypt"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := map[string]string{"NAMESPACE": "ingress-anubis"}
+			maps.Copy(env, tt.env)
+			cfg := testConfig(t, env)
+			ing := testIngress(cfg, tt.annotations)
+			ir := newTestReconciler(t, cfg, ing)
+			reconcileTestIngress(t, ir, crclient.ObjectKeyFromObject(ing))
+
+			var child networkingv1.Ingress
+			if err := ir.client.Get(t.Context(), types.NamespacedName{Namespace: "ingress-anubis", Name: "ia-web-82b3ade9"}, &child); err != nil {
+				t.Fatalf("failed to get child ingress: %v", err)
+			}
+			got := maps.Clone(child.Annotations)
+			delete(got, SpecHashAnnotation)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("child ingress annotations mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOwnerRequests(t *testing.T) {
+	owned := map[string]string{ManagedLabel: "true", OwnerNamespaceLabel: "default", OwnerNameLabel: "web"}
+
+	tests := []struct {
+		name      string
+		namespace string
+		labels    map[string]string
+		want      []reconcile.Request
+	}{
+		{
+			name:      "should requeue the owning ingress",
+			namespace: "ingress-anubis",
+			labels:    owned,
+			want:      []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "default", Name: "web"}}},
+		},
+		{
+			name:      "should ignore resources outside of the controller namespace",
+			namespace: "default",
+			labels:    owned,
+		},
+		{
+			name:      "should ignore unmanaged resources",
+			namespace: "ingress-anubis",
+			labels:    map[string]string{OwnerNamespaceLabel: "default", OwnerNameLabel: "web"},
+		},
+		{
+			name:      "should ignore resources without an owner",
+			namespace: "ingress-anubis",
+			labels:    map[string]string{ManagedLabel: "true"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Namespace: "ingress-anubis"}
+			dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "ia-web", Namespace: tt.namespace, Labels: tt.labels}}
+			if diff := cmp.Diff(tt.want, ownerRequests[*appsv1.Deployment](cfg)(t.Context(), dep)); diff != "" {
+				t.Errorf("ownerRequests() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIngressChangedPredicate(t *testing.T) {
+	addressed := func(ip string) func(*networkingv1.Ingress) {
+		return func(ing *networkingv1.Ingress) {
+			ing.Status.LoadBalancer.Ingress = []networkingv1.IngressLoadBalancerIngress{{IP: ip}}
+		}
+	}
+	updated := func(modify func(*networkingv1.Ingress)) func(*networkingv1.Ingress) {
+		return func(ing *networkingv1.Ingress) {
+			ing.ResourceVersion = "2"
+			modify(ing)
+		}
+	}
+	child := func(ing *networkingv1.Ingress) {
+		ing.Labels = map[string]string{ManagedLabel: "true"}
+	}
+
+	tests := []struct {
+		name     string
+		old, new func(*networkingv1.Ingress)
+		want     bool
+	}{
+		{
+			name: "should let resyncs through",
+			want: true,
+		},
+		{
+			name: "should let spec changes through",
+			new:  updated(func(ing *networkingv1.Ingress) { ing.Generation = 2 }),
+			want: true,
+		},
+		{
+			name: "should let annotation changes through",
+			new: updated(func(ing *networkingv1.Ingress) {
+				ing.Annotations = map[string]string{config.AnnotationKeyDifficulty.String(): "6"}
+			}),
+			want: true,
+		},
+		{
+			name: "should ignore status changes",
+			old:  addressed("10.0.0.1"),
+			new:  updated(addressed("10.0.0.2")),
+		},
+		{
+			name: "should ignore state changes",
+			new: updated(func(ing *networkingv1.Ingress) {
+				ing.Annotations = map[string]string{StateAnnotationPrefix + "phase": "Ready"}
+			}),
+		},
+		{
+			name: "should let the first address through",
+			new:  updated(addressed("10.0.0.1")),
+			want: true,
+		},
+		{
+			name: "should ignore child ingresses",
+			old:  child,
+			new: updated(func(ing *networkingv1.Ingress) {
+				child(ing)
+				ing.Generation = 2
+			}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ingress := func(modify func(*networkingv1.Ingress)) *networkingv1.Ingress {
+				ing := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{
+					Name: "web", Namespace: "default", ResourceVersion: "1", Generation: 1,
+				}}
+				if modify != nil {
+					modify(ing)
+				}
+				return ing
+			}
+
+			e := event.TypedUpdateEvent[*networkingv1.Ingress]{ObjectOld: ingress(tt.old), ObjectNew: ingress(tt.new)}
+			if got := ingressChangedPredicate[*networkingv1.Ingress]().Update(e); got != tt.want {
+				t.Errorf("Update() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileRevertsChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*appsv1.Deployment)
+	}{
+		{
+			name:   "should revert the replicas",
+			modify: func(dep *appsv1.Deployment) { dep.Spec.Replicas = ptr.To(int32(3)) },
+		},
+		{
+			name: "should revert the environment",
+			modify: func(dep *appsv1.Deployment) {
+				dep.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{{Name: "DIFFICULTY", Value: "1"}}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, map[string]string{"NAMESPACE": "ingress-anubis"})
+			ing := testIngress(cfg, nil)
+			ir := newTestReconciler(t, cfg, ing)
+			reconcileTestIngress(t, ir, crclient.ObjectKeyFromObject(ing))
+			want := testDeployment(t, ir, "ia-web-82b3ade9")
+
+			dep := want.DeepCopy()
+			tt.modify(dep)
+			if err := ir.client.Update(t.Context(), dep); err != nil {
+				t.Fatalf("failed to update deployment: %v", err)
+			}
+			reconcileTestIngress(t, ir, crclient.ObjectKeyFromObject(ing))
+
+			got := testDeployment(t, ir, "ia-web-82b3ade9")
+			if diff := cmp.Diff(want.Spec, got.Spec); diff != "" {
+				t.Errorf("deployment wasn't reverted (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDeleteResources(t *testing.T) {
+	meta := func(namespace string, labels map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: "ia-web", Namespace: namespace, Labels: labels}
+	}
+	owned := func(name string) map[string]string {
+		return map[string]string{ManagedLabel: "true", OwnerNamespaceLabel: "default", OwnerNameLabel: name}
+	}
+
+	tests := []struct {
+		name        string
+		obj         crclient.Object
+		wantDeleted bool
+	}{
+		{
+			name:        "should delete owned deployments",
+			obj:         &appsv1.Deployment{ObjectMeta: meta("ingress-anubis", owned("web"))},
+			wantDeleted: true,
+		},
+		{
+			name:        "should delete owned secrets",
+			obj:         &corev1.Secret{ObjectMeta: meta("ingress-anubis", owned("web"))},
+			wantDeleted: true,
+		},
+		{
+			name: "should keep the resources of other ingresses",
+			obj:  &corev1.ConfigMap{ObjectMeta: meta("ingress-anubis", owned("api"))},
+		},
+		{
+			name: "should keep resources outside of the controller namespace",
+			obj:  &appsv1.Deployment{ObjectMeta: meta("default", owned("web"))},
+		},
+		{
+			name:        "should delete owned in-place services",
+			obj:         &corev1.Service{ObjectMeta: meta("default", owned("web"))},
+			wantDeleted: true,
+		},
+		{
+			name: "should keep unmanaged services in the namespace of the ingress",
+			obj: &corev1.Service{ObjectMeta: meta("default",
+				map[string]string{OwnerNamespaceLabel: "default", OwnerNameLabel: "web"})},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, map[string]string{"NAMESPACE": "ingress-anubis"})
+			ir := newTestReconciler(t, cfg, tt.obj)
+
+			req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "web"}}
+			if err := ir.deleteResources(t.Context(), req); err != nil {
+				t.Fatalf("deleteResources() error = %v", err)
+			}
+
+			got := tt.obj.DeepCopyObject().(crclient.Object)
+			err := ir.client.Get(t.Context(), crclient.ObjectKeyFromObject(tt.obj), got)
+			if err != nil && !apierrors.IsNotFound(err) {
+				t.Fatalf("failed to get object: %v", err)
+			}
+			if deleted := apierrors.IsNotFound(err); deleted != tt.wantDeleted {
+				t.Errorf("deleted = %v, want %v", deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestGetOwner(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   types.NamespacedName
+		wantOk bool
+	}{
+		{
+			name:   "should use the owner labels",
+			labels: map[string]string{OwnerNamespaceLabel: "default", OwnerNameLabel: "web--app"},
+			want:   types.NamespacedName{Namespace: "default", Name: "web--app"},
+			wantOk: true,
+		},
+		{
+			name: "should prefer the owner labels",
+			labels: map[string]string{
+				OwnerNamespaceLabel: "default", OwnerNameLabel: "web",
+				OwningLabel: "other--web",
+			},
+			want:   types.NamespacedName{Namespace: "default", Name: "web"},
+			wantOk: true,
+		},
+		{
+			name:   "should fall back to the legacy label",
+			labels: map[string]string{OwningLabel: "default--web"},
+			want:   types.NamespacedName{Namespace: "default", Name: "web"},
+			wantOk: true,
+		},
+		{
+			name:   "should reject ambiguous legacy labels",
+			labels: map[string]string{OwningLabel: "default--web--app"},
+		},
+		{
+			name:   "should reject incomplete owner labels",
+			labels: map[string]string{OwnerNamespaceLabel: "default"},
+		},
+		{
+			name: "should reject resources without an owner",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := getOwner(tt.labels)
+			if ok != tt.wantOk {
+				t.Fatalf("getOwner() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if got != tt.want {
+				t.Errorf("getOwner() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileEvents(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        []string
+	}{
+		{
+			name: "should record the created resources",
+			want: []string{
+				"Normal Created Created Deployment ingress-anubis/ia-web-82b3ade9",
+				"Normal Created Created Service ingress-anubis/ia-web-82b3ade9",
+				"Normal Created Created Ingress ingress-anubis/ia-web-82b3ade9",
+			},
+		},
+		{
+			name:        "should record the created direct services",
+			annotations: map[string]string{config.AnnotationKeyMode.String(): string(config.ModeShadow)},
+			want: []string{
+				"Normal Created Created Deployment ingress-anubis/ia-web-82b3ade9",
+				"Normal Created Created Service ingress-anubis/ia-web-82b3ade9",
+				"Normal Created Created Service ingress-anubis/ia-web-82b3ade9-direct",
+				"Normal Created Created Ingress ingress-anubis/ia-web-82b3ade9",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, map[string]string{"NAMESPACE": "ingress-anubis"})
+			ing := testIngress(cfg, tt.annotations)
+			ir := newTestReconciler(t, cfg, ing)
+			recorder := ir.recorder.(*events.FakeRecorder)
+
+			// drain returns the events recorded so far.
+			drain := func() []string {
+				var got []string
+				for {
+					select {
+					case e := <-recorder.Events:
+						got = append(got, e)
+					default:
+						return got
+					}
+				}
+			}
+
+			reconcileTestIngress(t, ir, crclient.ObjectKeyFromObject(ing))
+			if diff := cmp.Diff(tt.want, drain()); diff != "" {
+				t.Errorf("events mismatch (-want +got):\n%s", diff)
+			}
+
+			// Nothing is created when reconciling again.
+			reconcileTestIngress(t, ir, crclient.ObjectKeyFromObject(ing))
+			if diff := cmp.Diff([]string(nil), drain()); diff != "" {
+				t.Errorf("events of unchanged ingress mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheckIngressClass(t *testing.T) {
+	ic := &networkingv1.IngressClass{ObjectMeta: metav1.ObjectMeta{Name: "nginx"}}
+
+	tests := []struct {
+		name      string
+		class     *string
+		wantErr   bool
+		wantEvent string
+	}{
+		{name: "default ingress class", class: nil},
+		{name: "existing ingress class", class: ptr.To("nginx")},
+		{
+			name:      "missing ingress class",
+			class:     ptr.To("traefik"),
+			wantErr:   true,
+			wantEvent: `Warning IngressClassNotFound Ingress class "traefik" of the wrapped ingress does not exist`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := events.NewFakeRecorder(1)
+			ir := &IngressReconciler{
+				client:   fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(ic).Build(),
+				recorder: recorder,
+			}
+			err := ir.checkIngressClass(t.Context(), &networkingv1.Ingress{}, tt.class)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkIngressClass() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var event string
+			select {
+			case event = <-recorder.Events:
+			default:
+			}
+			if event != tt.wantEvent {
+				t.Errorf("event = %q, want %q", event, tt.wantEvent)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	appsv1 "k8s.io/api/apps/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/ptr"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// metricsNamespace is the namespace used for all metrics exposed by
+// the controller.
+const metricsNamespace = "ingress_anubis"
+
+// Contains the metrics exposed by the controller on the manager's
+// metrics endpoint.
+var (
+	// circuitBreakerOpen is the number of ingresses whose circuit breaker
+	// is currently open. See [circuitBreaker].
+	circuitBreakerOpen = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "circuit_breaker_open_ingresses",
+		Help:      "Number of ingresses that are persistently failing to reconcile and need attention.",
+	})
+
+	// preflightCheckFailing is set to 1 for each preflight check, per
+	// cluster, that is currently failing. See [preflight].
+	preflightCheckFailing = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "preflight_check_failing",
+		Help:      "Whether a preflight check of the controller's environment is currently failing.",
+	}, []string{"cluster", "check"})
+
+	// routingVerified is set to 1 for each ingress, per cluster, that was
+	// verified to be served by Anubis, and 0 if verification failed. See
+	// [routeVerifier].
+	routingVerified = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "routing_verified",
+		Help:      "Whether requests to an ingress were verified to be served by Anubis.",
+	}, []string{"cluster", "namespace", "ingress"})
+
+	// reconcileErrors counts failed reconciles by reason, see
+	// [errorReason].
+	reconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_errors_total",
+		Help:      "Number of failed ingress reconciles by reason.",
+	}, []string{"reason"})
+
+	// childResourceOperations counts the resources created or updated
+	// for ingresses, by kind.
+	childResourceOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "child_resource_operations_total",
+		Help:      "Number of resources created or updated for ingresses.",
+	}, []string{"kind", "operation"})
+
+	// orphanedResourcesDeleted counts the resources deleted by the
+	// [garbageCollector], by kind.
+	orphanedResourcesDeleted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "orphaned_resources_deleted_total",
+		Help:      "Number of resources deleted because the ingress owning them no longer exists.",
+	}, []string{"kind"})
+
+	// managedIngresses reports the managed ingresses of every cluster,
+	// see [managedCollector].
+	managedIngresses = newManagedCollector()
+)
+
+// Contains the descriptions of the metrics collected by
+// [managedCollector].
+var (
+	managedIngressesDesc = prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "managed_ingresses"),
+		"Number of ingresses managed by the controller.", []string{"cluster"}, nil)
+	deploymentReadyDesc = prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "deployment_ready"),
+		"Whether the anubis deployments of an ingress have all of their replicas ready.",
+		[]string{"cluster", "namespace", "ingress"}, nil)
+)
+
+// registerMetrics registers all of the controller's metrics with the
+// controller-runtime metrics registry.
+func registerMetrics() error {
+	for _, c := range []prometheus.Collector{
+		circuitBreakerOpen,
+		preflightCheckFailing,
+		routingVerified,
+		reconcileErrors,
+		childResourceOperations,
+		orphanedResourcesDeleted,
+		managedIngresses,
+	} {
+		if err := metrics.Registry.Register(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// errorReason returns the reason reported in [reconcileErrors] for the
+// provided error: the reason of degraded errors (e.g., "NoBackends"),
+// "InvalidIngress" for terminal errors, the reason of API errors (e.g.,
+// "Conflict") or "Unknown".
+func errorReason(err error) string {
+	if de, ok := asDegraded(err); ok {
+		return de.reason
+	}
+	if errors.Is(err, reconcile.TerminalError(nil)) {
+		return "InvalidIngress"
+	}
+	if reason := apierrors.ReasonForError(err); reason != "" {
+		return string(reason)
+	}
+	return "Unknown"
+}
+
+// managedCollector is a [prometheus.Collector] reporting the ingresses
+// managed by the [IngressReconciler] of each cluster and the readiness
+// of their deployments, read from the cache when scraped.
+type managedCollector struct {
+	mu       sync.Mutex
+	clusters map[string]*IngressReconciler
+}
+
+// newManagedCollector creates a new managedCollector without any
+// clusters, see [managedCollector.add].
+func newManagedCollector() *managedCollector {
+	return &managedCollector{clusters: make(map[string]*IngressReconciler)}
+}
+
+// add reports the ingresses managed by the provided reconciler as
+// cluster, replacing the previous reconciler of cluster.
+func (c *managedCollector) add(cluster string, ir *IngressReconciler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clusters[cluster] = ir
+}
+
+// Describe implements [prometheus.Collector].
+func (c *managedCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- managedIngressesDesc
+	ch <- deploymentReadyDesc
+}
+
+// Collect implements [prometheus.Collector].
+func (c *managedCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	clusters := maps.Clone(c.clusters)
+	c.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for cluster, ir := range clusters {
+		collectManaged(ctx, ch, cluster, ir)
+	}
+}
+
+// collectManaged collects the metrics of [managedCollector] for a
+// single cluster.
+func collectManaged(ctx context.Context, ch chan<- prometheus.Metric, cluster string, ir *IngressReconciler) {
+	var ings networkingv1.IngressList
+	if err := ir.client.List(ctx, &ings); err != nil {
+		ch <- prometheus.NewInvalidMetric(managedIngressesDesc, err)
+		return
+	}
+
+	managed := 0
+	for i := range ings.Items {
+		if ings.Items[i].Labels[ManagedLabel] == "true" {
+			continue
+		}
+		ok, err := ir.isManaged(ctx, &ings.Items[i])
+		if err != nil {
+			ch <- prometheus.NewInvalidMetric(managedIngressesDesc, err)
+			return
+		}
+		if ok {
+			managed++
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(managedIngressesDesc, prometheus.GaugeValue, float64(managed), cluster)
+
+	var deps appsv1.DeploymentList
+	if err := ir.client.List(ctx, &deps, crclient.InNamespace(ir.cfg.Namespace),
+		crclient.MatchingLabels{ManagedLabel: "true"}); err != nil {
+		ch <- prometheus.NewInvalidMetric(deploymentReadyDesc, err)
+		return
+	}
+
+	// Ingresses with more than one backend have a deployment for each,
+	// all of which have to be ready.
+	ready := make(map[crclient.ObjectKey]bool)
+	for i := range deps.Items {
+		dep := &deps.Items[i]
+		owner, ok := getOwner(dep.Labels)
+		if !ok {
+			continue
+		}
+
+		depReady := dep.Status.ObservedGeneration >= dep.Generation && dep.Status.ReadyReplicas >= ptr.Deref(dep.Spec.Replicas, 1)
+		if prev, ok := ready[owner]; ok {
+			depReady = depReady && prev
+		}
+		ready[owner] = depReady
+	}
+
+	for owner, ok := range ready {
+		v := 0.0
+		if ok {
+			v = 1
+		}
+		ch <- prometheus.MustNewConstMetric(deploymentReadyDesc, prometheus.GaugeValue, v,
+			cluster, owner.Namespace, owner.Name)
+	}
+}
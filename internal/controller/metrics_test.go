@@ -0,0 +1,73 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestManagedCollectorClusters(t *testing.T) {
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	protected := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{
+		Name:        "web",
+		Namespace:   "default",
+		Annotations: map[string]string{config.AnnotationKeyProtect.String(): "true"},
+	}}
+	local := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(protected).Build()
+	remote := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+
+	// A single collector reports every cluster, registering one per
+	// cluster would fail as their descriptions are identical.
+	c := newManagedCollector()
+	c.add(LocalClusterName, &IngressReconciler{cfg: cfg, client: local})
+	c.add("remote", &IngressReconciler{cfg: cfg, client: remote})
+
+	reg := prometheus.NewRegistry()
+	if err := reg.Register(c); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+
+	got := make(map[string]float64)
+	for _, f := range families {
+		if f.GetName() != "ingress_anubis_managed_ingresses" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			got[m.GetLabel()[0].GetValue()] = m.GetGauge().GetValue()
+		}
+	}
+	if diff := cmp.Diff(map[string]float64{LocalClusterName: 1, "remote": 0}, got); diff != "" {
+		t.Errorf("managed ingresses mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// namespaceNameLabel is the label set by Kubernetes on every namespace
+// containing its name.
+const namespaceNameLabel = "kubernetes.io/metadata.name"
+
+// reconcileNetworkPolicy ensures that the NetworkPolicy of the anubis
+// deployment of the provided backend, in namespace ns, exists when
+// enabled through [config.Config.NetworkPolicyEnabled], or doesn't
+// otherwise.
+func (ir *IngressReconciler) reconcileNetworkPolicy(ctx context.Context, ns string,
+	b *anubisBackend, req reconcile.Request) error {
+	np := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.name,
+			Namespace: ir.cfg.Namespace,
+		},
+	}
+
+	if !ir.cfg.NetworkPolicyEnabled {
+		if err := ir.client.Delete(ctx, np); crclient.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete network policy: %w", err)
+		}
+		return nil
+	}
+
+	target, err := ir.getTargetPeer(ctx, ns, &b.service)
+	if err != nil {
+		return err
+	}
+
+	labels := map[string]string{
+		ManagedLabel:        "true",
+		OwnerNamespaceLabel: req.Namespace,
+		OwnerNameLabel:      req.Name,
+	}
+
+	dnsPort := intstr.FromInt32(53)
+	if _, err := ir.apply(ctx, np, func() error {
+		np.Labels = labels
+		np.Spec = networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: b.selectorLabels(req)},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From: []networkingv1.NetworkPolicyPeer{{
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{namespaceNameLabel: ir.cfg.WrappedIngressNamespace},
+					},
+				}},
+				Ports: []networkingv1.NetworkPolicyPort{{Port: ptr.To(intstr.FromString("http"))}},
+			}, {
+				// Metrics are scraped by Prometheus and the controller
+				// (see [difficultyTuner]), wherever they run.
+				Ports: []networkingv1.NetworkPolicyPort{{Port: ptr.To(intstr.FromString("http-metrics"))}},
+			}},
+			Egress: []networkingv1.NetworkPolicyEgressRule{target, {
+				Ports: []networkingv1.NetworkPolicyPort{
+					{Protocol: ptr.To(corev1.ProtocolUDP), Port: &dnsPort},
+					{Protocol: ptr.To(corev1.ProtocolTCP), Port: &dnsPort},
+				},
+			}},
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to reconcile network policy: %w", err)
+	}
+
+	return nil
+}
+
+// getTargetPeer returns the egress rule allowing traffic to the pods of
+// the provided backend in namespace ns. Services without a selector
+// (e.g., with manually managed endpoints) can't be matched, so traffic
+// to their entire namespace is allowed instead.
+func (ir *IngressReconciler) getTargetPeer(ctx context.Context, ns string,
+	isb *networkingv1.IngressServiceBackend) (networkingv1.NetworkPolicyEgressRule, error) {
+	var svc corev1.Service
+	if err := ir.client.Get(ctx, crclient.ObjectKey{Namespace: ns, Name: isb.Name}, &svc); err != nil {
+		return networkingv1.NetworkPolicyEgressRule{}, fmt.Errorf("failed to get target service: %w", err)
+	}
+
+	peer := networkingv1.NetworkPolicyPeer{
+		NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{namespaceNameLabel: ns}},
+	}
+	if len(svc.Spec.Selector) == 0 {
+		return networkingv1.NetworkPolicyEgressRule{To: []networkingv1.NetworkPolicyPeer{peer}}, nil
+	}
+	peer.PodSelector = &metav1.LabelSelector{MatchLabels: svc.Spec.Selector}
+
+	rule := networkingv1.NetworkPolicyEgressRule{To: []networkingv1.NetworkPolicyPeer{peer}}
+	for _, p := range svc.Spec.Ports {
+		if isb.Port.Name != "" && p.Name != isb.Port.Name || isb.Port.Name == "" && p.Port != isb.Port.Number {
+			continue
+		}
+
+		// Policies apply to the pods, so the service port has to be
+		// translated. An unset target port is the same as the port.
+		port := p.TargetPort
+		if port == (intstr.IntOrString{}) {
+			port = intstr.FromInt32(p.Port)
+		}
+		rule.Ports = []networkingv1.NetworkPolicyPort{{Protocol: ptr.To(p.Protocol), Port: &port}}
+		break
+	}
+	return rule, nil
+}
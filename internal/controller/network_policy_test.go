@@ -0,0 +1,125 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestReconcileNetworkPolicy(t *testing.T) {
+	namespace := &metav1.LabelSelector{MatchLabels: map[string]string{namespaceNameLabel: "default"}}
+
+	tests := []struct {
+		name       string
+		enabled    string
+		service    corev1.ServiceSpec
+		wantTarget *networkingv1.NetworkPolicyEgressRule
+	}{
+		{
+			name:    "should not create a network policy when disabled",
+			enabled: "false",
+		},
+		{
+			name:    "should allow traffic to the target pods",
+			enabled: "true",
+			service: corev1.ServiceSpec{
+				Selector: map[string]string{"app": "web"},
+				Ports:    []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt32(8080), Protocol: corev1.ProtocolTCP}},
+			},
+			wantTarget: &networkingv1.NetworkPolicyEgressRule{
+				To: []networkingv1.NetworkPolicyPeer{{
+					NamespaceSelector: namespace,
+					PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				}},
+				Ports: []networkingv1.NetworkPolicyPort{{Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(intstr.FromInt32(8080))}},
+			},
+		},
+		{
+			name:    "should use the port when the target port isn't set",
+			enabled: "true",
+			service: corev1.ServiceSpec{
+				Selector: map[string]string{"app": "web"},
+				Ports:    []corev1.ServicePort{{Port: 80, Protocol: corev1.ProtocolTCP}},
+			},
+			wantTarget: &networkingv1.NetworkPolicyEgressRule{
+				To: []networkingv1.NetworkPolicyPeer{{
+					NamespaceSelector: namespace,
+					PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				}},
+				Ports: []networkingv1.NetworkPolicyPort{{Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(intstr.FromInt32(80))}},
+			},
+		},
+		{
+			name:    "should allow traffic to the namespace of services without a selector",
+			enabled: "true",
+			service: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Port: 80, Protocol: corev1.ProtocolTCP}},
+			},
+			wantTarget: &networkingv1.NetworkPolicyEgressRule{
+				To: []networkingv1.NetworkPolicyPeer{{NamespaceSelector: namespace}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, map[string]string{"NAMESPACE": "ingress-anubis", "NETWORK_POLICY_ENABLED": tt.enabled})
+			ing := testIngress(cfg, nil)
+			svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}, Spec: tt.service}
+			ir := newTestReconciler(t, cfg, ing, svc)
+			reconcileTestIngress(t, ir, crclient.ObjectKeyFromObject(ing))
+
+			var np networkingv1.NetworkPolicy
+			err := ir.client.Get(t.Context(), crclient.ObjectKey{Namespace: "ingress-anubis", Name: "ia-web-82b3ade9"}, &np)
+			if tt.wantTarget == nil {
+				if !apierrors.IsNotFound(err) {
+					t.Errorf("network policy exists while disabled: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to get network policy: %v", err)
+			}
+
+			if len(np.Spec.Egress) == 0 {
+				t.Fatalf("network policy has no egress rules")
+			}
+			if diff := cmp.Diff(tt.wantTarget, &np.Spec.Egress[0]); diff != "" {
+				t.Errorf("target egress rule mismatch (-want +got):\n%s", diff)
+			}
+
+			wantFrom := []networkingv1.NetworkPolicyPeer{{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{namespaceNameLabel: "ingress-nginx"}},
+			}}
+			if len(np.Spec.Ingress) == 0 {
+				t.Fatalf("network policy has no ingress rules")
+			}
+			if diff := cmp.Diff(wantFrom, np.Spec.Ingress[0].From); diff != "" {
+				t.Errorf("ingress rule mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
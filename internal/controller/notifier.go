@@ -0,0 +1,238 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	"go.rgst.io/jaredallard/slogext/v2"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Contains the supported notification payload formats. See
+// [config.Config.NotifyFormat].
+const (
+	// NotifyFormatGeneric sends a [notification] as JSON.
+	NotifyFormatGeneric = "generic"
+
+	// NotifyFormatSlack sends a Slack-compatible (incoming webhook)
+	// payload.
+	NotifyFormatSlack = "slack"
+)
+
+// notification is sent when an ingress enters or leaves a failed state.
+type notification struct {
+	Cluster   string    `json:"cluster"`
+	Namespace string    `json:"namespace"`
+	Ingress   string    `json:"ingress"`
+	Failed    bool      `json:"failed"`
+	Reason    string    `json:"reason,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// text returns a human readable version of the notification.
+func (n *notification) text() string {
+	if !n.Failed {
+		return fmt.Sprintf(":white_check_mark: Ingress %s/%s in cluster %s recovered and is protected by anubis again",
+			n.Namespace, n.Ingress, n.Cluster)
+	}
+
+	return fmt.Sprintf(":warning: Ingress %s/%s in cluster %s is failing (%s): %s",
+		n.Namespace, n.Ingress, n.Cluster, n.Reason, n.Message)
+}
+
+// notifier sends notifications to a webhook when an ingress enters or
+// leaves a failed state, so that operators learn about broken
+// protection without watching the controller logs. Notifications are
+// sent asynchronously and rate limited. A nil notifier never notifies.
+// See [config.Config.NotifyWebhookURL].
+//
+// notifier implements [manager.Runnable].
+type notifier struct {
+	log     slogext.Logger
+	url     string
+	format  string
+	http    *http.Client
+	limiter *rate.Limiter
+	queue   chan notification
+
+	mu     sync.Mutex
+	failed map[notifierKey]bool
+}
+
+// notifierKey identifies an ingress across the clusters sharing a
+// [notifier].
+type notifierKey struct {
+	cluster string
+	types.NamespacedName
+}
+
+// newNotifier creates a [notifier] from the provided configuration. If
+// notifications are disabled, nil is returned.
+func newNotifier(log slogext.Logger, cfg *config.Config) (*notifier, error) {
+	if cfg.NotifyWebhookURL == "" {
+		return nil, nil
+	}
+
+	if cfg.NotifyFormat != NotifyFormatGeneric && cfg.NotifyFormat != NotifyFormatSlack {
+		return nil, fmt.Errorf("unknown notification format %q, expected one of %q or %q",
+			cfg.NotifyFormat, NotifyFormatGeneric, NotifyFormatSlack)
+	}
+
+	limiter := rate.NewLimiter(rate.Inf, 0)
+	if cfg.NotifyRateLimit > 0 {
+		every := cfg.NotifyRateLimitPeriod / time.Duration(cfg.NotifyRateLimit)
+		limiter = rate.NewLimiter(rate.Every(every), cfg.NotifyRateLimit)
+	}
+
+	return &notifier{
+		log:     log,
+		url:     cfg.NotifyWebhookURL,
+		format:  cfg.NotifyFormat,
+		http:    &http.Client{Timeout: 10 * time.Second},
+		limiter: limiter,
+		queue:   make(chan notification, 100),
+		failed:  make(map[notifierKey]bool),
+	}, nil
+}
+
+// fail records that the provided ingress of cluster is failing,
+// sending a notification if it wasn't already.
+func (n *notifier) fail(cluster string, key types.NamespacedName, reason, message string) {
+	if n == nil {
+		return
+	}
+
+	n.mu.Lock()
+	wasFailed := n.failed[notifierKey{cluster, key}]
+	n.failed[notifierKey{cluster, key}] = true
+	n.mu.Unlock()
+
+	if !wasFailed {
+		n.enqueue(notification{
+			Cluster: cluster, Namespace: key.Namespace, Ingress: key.Name, Failed: true, Reason: reason, Message: message,
+		})
+	}
+}
+
+// resolve records that the provided ingress of cluster is no longer
+// failing, sending a notification if it was.
+func (n *notifier) resolve(cluster string, key types.NamespacedName) {
+	if n == nil {
+		return
+	}
+
+	n.mu.Lock()
+	wasFailed := n.failed[notifierKey{cluster, key}]
+	delete(n.failed, notifierKey{cluster, key})
+	n.mu.Unlock()
+
+	if wasFailed {
+		n.enqueue(notification{Cluster: cluster, Namespace: key.Namespace, Ingress: key.Name})
+	}
+}
+
+// forget removes all state for the provided ingress of cluster without
+// notifying, e.g., because it was deleted.
+func (n *notifier) forget(cluster string, key types.NamespacedName) {
+	if n == nil {
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.failed, notifierKey{cluster, key})
+}
+
+// enqueue queues the provided notification to be sent, dropping it if
+// rate limited or the queue is full.
+func (n *notifier) enqueue(msg notification) {
+	msg.Time = time.Now()
+
+	if !n.limiter.Allow() {
+		n.log.Warn("dropping notification due to rate limit",
+			slog.String("cluster", msg.Cluster), slog.String("namespace", msg.Namespace),
+			slog.String("name", msg.Ingress))
+		return
+	}
+
+	select {
+	case n.queue <- msg:
+	default:
+		n.log.Warn("dropping notification, queue is full",
+			slog.String("cluster", msg.Cluster), slog.String("namespace", msg.Namespace),
+			slog.String("name", msg.Ingress))
+	}
+}
+
+// send sends the provided notification to the webhook.
+func (n *notifier) send(ctx context.Context, msg *notification) error {
+	var payload any = msg
+	if n.format == NotifyFormatSlack {
+		payload = map[string]string{"text": msg.text()}
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to send notification: unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Start implements [manager.Runnable].
+func (n *notifier) Start(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg := <-n.queue:
+			if err := n.send(ctx, &msg); err != nil {
+				n.log.Error("failed to send notification", slog.String("err", err.Error()),
+					slog.String("cluster", msg.Cluster), slog.String("namespace", msg.Namespace),
+					slog.String("name", msg.Ingress))
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	"go.rgst.io/jaredallard/slogext/v2"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestNotifierClusters(t *testing.T) {
+	n, err := newNotifier(slogext.New(), &config.Config{NotifyWebhookURL: "http://example.com", NotifyFormat: NotifyFormatGeneric})
+	if err != nil {
+		t.Fatalf("newNotifier() error = %v", err)
+	}
+
+	key := types.NamespacedName{Namespace: "default", Name: "web"}
+	n.fail("a", key, "NeedsAttention", "failed")
+	// The same ingress in another cluster never failed.
+	n.resolve("b", key)
+	n.resolve("a", key)
+	close(n.queue)
+
+	var got []notification
+	for msg := range n.queue {
+		got = append(got, msg)
+	}
+	want := []notification{
+		{Cluster: "a", Namespace: "default", Ingress: "web", Failed: true, Reason: "NeedsAttention", Message: "failed"},
+		{Cluster: "a", Namespace: "default", Ingress: "web"},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(notification{}, "Time")); diff != "" {
+		t.Errorf("notifications mismatch (-want +got):\n%s", diff)
+	}
+}
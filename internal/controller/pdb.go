@@ -0,0 +1,73 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// reconcilePodDisruptionBudget ensures that the PodDisruptionBudget of
+// the anubis deployment of the provided backend exists when enabled
+// through [config.Config.PodDisruptionBudgetEnabled], or doesn't
+// otherwise.
+func (ir *IngressReconciler) reconcilePodDisruptionBudget(ctx context.Context, icfg *config.IngressConfig,
+	b *anubisBackend, req reconcile.Request) error {
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.name,
+			Namespace: ir.cfg.Namespace,
+		},
+	}
+
+	if !ir.cfg.PodDisruptionBudgetEnabled {
+		if err := ir.client.Delete(ctx, pdb); crclient.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete pod disruption budget: %w", err)
+		}
+		return nil
+	}
+
+	labels := map[string]string{
+		ManagedLabel:        "true",
+		OwnerNamespaceLabel: req.Namespace,
+		OwnerNameLabel:      req.Name,
+	}
+
+	if _, err := ir.apply(ctx, pdb, func() error {
+		pdb.Labels = labels
+		pdb.Spec.Selector = &metav1.LabelSelector{MatchLabels: b.selectorLabels(req)}
+		pdb.Spec.MinAvailable = icfg.PDBMinAvailable
+		pdb.Spec.MaxUnavailable = icfg.PDBMaxUnavailable
+		if pdb.Spec.MinAvailable == nil && pdb.Spec.MaxUnavailable == nil {
+			pdb.Spec.MinAvailable = ptr.To(intstr.FromInt32(1))
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to reconcile pod disruption budget: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestReconcilePodDisruptionBudget(t *testing.T) {
+	tests := []struct {
+		name        string
+		enabled     string
+		annotations map[string]string
+		want        *policyv1.PodDisruptionBudgetSpec
+	}{
+		{
+			name:    "should not create a pod disruption budget when disabled",
+			enabled: "false",
+		},
+		{
+			name:    "should default to one available pod",
+			enabled: "true",
+			want:    &policyv1.PodDisruptionBudgetSpec{MinAvailable: ptr.To(intstr.FromInt32(1))},
+		},
+		{
+			name:        "should set the minimum available pods",
+			enabled:     "true",
+			annotations: map[string]string{config.AnnotationKeyPDBMinAvailable.String(): "50%"},
+			want:        &policyv1.PodDisruptionBudgetSpec{MinAvailable: ptr.To(intstr.FromString("50%"))},
+		},
+		{
+			name:        "should set the maximum unavailable pods",
+			enabled:     "true",
+			annotations: map[string]string{config.AnnotationKeyPDBMaxUnavailable.String(): "1"},
+			want:        &policyv1.PodDisruptionBudgetSpec{MaxUnavailable: ptr.To(intstr.FromInt32(1))},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, map[string]string{"NAMESPACE": "ingress-anubis", "POD_DISRUPTION_BUDGET_ENABLED": tt.enabled})
+			ing := testIngress(cfg, tt.annotations)
+			ir := newTestReconciler(t, cfg, ing)
+			reconcileTestIngress(t, ir, crclient.ObjectKeyFromObject(ing))
+
+			var pdb policyv1.PodDisruptionBudget
+			err := ir.client.Get(t.Context(), crclient.ObjectKey{Namespace: "ingress-anubis", Name: "ia-web-82b3ade9"}, &pdb)
+			if tt.want == nil {
+				if !apierrors.IsNotFound(err) {
+					t.Errorf("pod disruption budget exists while disabled: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to get pod disruption budget: %v", err)
+			}
+
+			want := tt.want.DeepCopy()
+			want.Selector = pdb.Spec.Selector
+			if diff := cmp.Diff(want, &pdb.Spec); diff != "" {
+				t.Errorf("pod disruption budget mismatch (-want +got):\n%s", diff)
+			}
+
+			selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+			if err != nil {
+				t.Fatalf("invalid pod disruption budget selector: %v", err)
+			}
+			if dep := testDeployment(t, ir, pdb.Name); !selector.Matches(k8slabels.Set(dep.Spec.Template.Labels)) {
+				t.Errorf("pod disruption budget selector %s doesn't match the anubis pods", selector)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// PolicyConfigMapKey is the key of the bot policy in the ConfigMap
+	// referenced by [config.AnnotationKeyPolicyConfigMap].
+	PolicyConfigMapKey = "botPolicies.yaml"
+
+	// PolicyChecksumAnnotation is the pod template annotation containing
+	// the checksum of the bot policy, causing pods to be rolled when it
+	// changes (Anubis only reads it on startup).
+	PolicyChecksumAnnotation = "ingress-anubis.jaredallard.github.com/policy-checksum"
+
+	// policyMountPath is where the bot policy is mounted in the Anubis
+	// container.
+	policyMountPath = "/etc/anubis/policy"
+
+	// policyVolumeName is the name of the volume containing the bot
+	// policy.
+	policyVolumeName = "anubis-policy"
+)
+
+// challengePolicyTemplate is the bot policy generated for
+// [config.IngressConfig.ChallengeMethod]. It's based on the default
+// policy shipped with Anubis, only changing how browsers are challenged.
+const challengePolicyTemplate = `bots:
+  - import: (data)/bots/_deny-pathological.yaml
+  - import: (data)/bots/aggressive-brazilian-scrapers.yaml
+  - import: (data)/meta/ai-block-aggressive.yaml
+  - import: (data)/crawlers/_allow-good.yaml
+  - import: (data)/common/keep-internet-working.yaml
+  - name: generic-browser
+    user_agent_regex: Mozilla|Opera
+    action: CHALLENGE
+    challenge:
+      difficulty: %[1]d
+      report_as: %[1]d
+      algorithm: %[2]s
+`
+
+// renderChallengePolicy returns the bot policy for the challenge method
+// of the provided ingress configuration.
+func renderChallengePolicy(icfg *config.IngressConfig) string {
+	return fmt.Sprintf(challengePolicyTemplate, *icfg.Difficulty, *icfg.ChallengeMethod)
+}
+
+// policyName returns the name of the copy of the bot policy ConfigMap
+// for the provided request.
+func (ir *IngressReconciler) policyName(req reconcile.Request) string {
+	return ir.resourceName(req.NamespacedName) + "-policy"
+}
+
+// reconcilePolicy copies the bot policy ConfigMap referenced by the
+// provided ingress configuration into the controller namespace, since
+// pods can't mount ConfigMaps from other namespaces, or generates one
+// when a challenge method is set. The checksum of the policy is
+// returned, or an empty string if no policy is configured.
+func (ir *IngressReconciler) reconcilePolicy(ctx context.Context, icfg *config.IngressConfig, req reconcile.Request) (string, error) {
+	var policy string
+	switch {
+	case icfg.PolicyConfigMap != nil:
+		var src corev1.ConfigMap
+		if err := ir.client.Get(ctx, crclient.ObjectKey{Namespace: req.Namespace, Name: *icfg.PolicyConfigMap}, &src); err != nil {
+			if apierrors.IsNotFound(err) {
+				return "", degraded("PolicyNotFound", fmt.Errorf("policy configmap %q does not exist", *icfg.PolicyConfigMap))
+			}
+			return "", fmt.Errorf("failed to get policy configmap: %w", err)
+		}
+
+		var ok bool
+		policy, ok = src.Data[PolicyConfigMapKey]
+		if !ok {
+			return "", degraded("PolicyNotFound", fmt.Errorf("policy configmap %q is missing key %q", *icfg.PolicyConfigMap, PolicyConfigMapKey))
+		}
+	case icfg.ChallengeMethod != nil:
+		policy = renderChallengePolicy(icfg)
+	default:
+		return "", nil
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ir.policyName(req),
+			Namespace: ir.cfg.Namespace,
+		},
+	}
+	if _, err := ir.apply(ctx, cm, func() error {
+		cm.Labels = map[string]string{
+			ManagedLabel:        "true",
+			OwnerNamespaceLabel: req.Namespace,
+			OwnerNameLabel:      req.Name,
+		}
+		cm.Data = map[string]string{PolicyConfigMapKey: policy}
+		return nil
+	}); err != nil {
+		return "", fmt.Errorf("failed to reconcile policy configmap: %w", err)
+	}
+
+	sum := sha256.Sum256([]byte(policy))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// applyPolicy configures the provided pod template to use the bot
+// policy copied by [IngressReconciler.reconcilePolicy].
+func (ir *IngressReconciler) applyPolicy(tmpl *corev1.PodTemplateSpec, req reconcile.Request, checksum string) {
+	if tmpl.Annotations == nil {
+		tmpl.Annotations = make(map[string]string)
+	}
+	tmpl.Annotations[PolicyChecksumAnnotation] = checksum
+
+	tmpl.Spec.Volumes = append(tmpl.Spec.Volumes, corev1.Volume{
+		Name: policyVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: ir.policyName(req)},
+			},
+		},
+	})
+
+	c := &tmpl.Spec.Containers[0]
+	c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{
+		Name:      policyVolumeName,
+		MountPath: policyMountPath,
+		ReadOnly:  true,
+	})
+	c.Env = append(c.Env, corev1.EnvVar{Name: "POLICY_FNAME", Value: path.Join(policyMountPath, PolicyConfigMapKey)})
+}
@@ -0,0 +1,111 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestReconcilePolicy(t *testing.T) {
+	policy := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+		Data:       map[string]string{PolicyConfigMapKey: "bots: []"},
+	}
+
+	tests := []struct {
+		name         string
+		configMap    string
+		objs         []crclient.Object
+		wantPolicy   string
+		wantDegraded bool
+	}{
+		{
+			name: "should not configure a policy by default",
+		},
+		{
+			name:       "should copy the policy configmap",
+			configMap:  "policy",
+			objs:       []crclient.Object{policy},
+			wantPolicy: "bots: []",
+		},
+		{
+			name:         "should wait for a missing policy configmap",
+			configMap:    "policy",
+			wantDegraded: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, map[string]string{"NAMESPACE": "ingress-anubis"})
+			var annotations map[string]string
+			if tt.configMap != "" {
+				annotations = map[string]string{config.AnnotationKeyPolicyConfigMap.String(): tt.configMap}
+			}
+			ing := testIngress(cfg, annotations)
+			ir := newTestReconciler(t, cfg, append(tt.objs, ing)...)
+			res := reconcileTestIngress(t, ir, crclient.ObjectKeyFromObject(ing))
+
+			var dep appsv1.Deployment
+			err := ir.client.Get(t.Context(), crclient.ObjectKey{Namespace: "ingress-anubis", Name: "ia-web-82b3ade9"}, &dep)
+			if tt.wantDegraded {
+				if res.RequeueAfter != cfg.DegradedRetryInterval {
+					t.Errorf("Reconcile() requeue after = %s, want %s", res.RequeueAfter, cfg.DegradedRetryInterval)
+				}
+				if !apierrors.IsNotFound(err) {
+					t.Errorf("deployment was created without its policy: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to get deployment: %v", err)
+			}
+
+			var cm corev1.ConfigMap
+			err = ir.client.Get(t.Context(), crclient.ObjectKey{Namespace: "ingress-anubis", Name: "ia-web-82b3ade9-policy"}, &cm)
+			fname := testDeploymentEnv(t, ir, dep.Name)["POLICY_FNAME"]
+			if tt.wantPolicy == "" {
+				if !apierrors.IsNotFound(err) {
+					t.Errorf("policy configmap exists without a policy: %v", err)
+				}
+				if fname != "" {
+					t.Errorf("POLICY_FNAME = %q, want it unset", fname)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to get policy configmap: %v", err)
+			}
+			if got := cm.Data[PolicyConfigMapKey]; got != tt.wantPolicy {
+				t.Errorf("policy = %q, want %q", got, tt.wantPolicy)
+			}
+			if want := "/etc/anubis/policy/" + PolicyConfigMapKey; fname != want {
+				t.Errorf("POLICY_FNAME = %q, want %q", fname, want)
+			}
+			if dep.Spec.Template.Annotations[PolicyChecksumAnnotation] == "" {
+				t.Errorf("pod template is missing the policy checksum annotation")
+			}
+		})
+	}
+}
@@ -0,0 +1,323 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jaredallard/ingress-anubis/internal/api/v1alpha1"
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	"go.rgst.io/jaredallard/slogext/v2"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// preflightCheck is a single check of the environment the controller is
+// running in.
+type preflightCheck struct {
+	// name is the name of the check, used in metrics and logs.
+	name string
+
+	// fn runs the check, returning an error if it failed.
+	fn func(ctx context.Context) error
+}
+
+// preflight verifies, on startup and then periodically, that the
+// environment the controller runs in is usable: the controller
+// namespace and wrapped ingress class exist and we have the permissions
+// we need. Failing checks fail readiness and are surfaced through
+// metrics and events, rather than every reconcile failing with an
+// opaque error.
+//
+// preflight implements [manager.Runnable] and runs on all replicas.
+type preflight struct {
+	log      slogext.Logger
+	cfg      *config.Config
+	cluster  string
+	reader   crclient.Reader
+	client   crclient.Client
+	recorder events.EventRecorder
+
+	mu      sync.RWMutex
+	ran     bool
+	failing map[string]error
+}
+
+// newPreflight creates a new [preflight] for the cluster, identified by
+// name in metrics, accessed through the provided reader and client. The
+// reader should not be cached to avoid starting informers for the
+// checked resources.
+func newPreflight(log slogext.Logger, cfg *config.Config, cluster string, reader crclient.Reader,
+	client crclient.Client, recorder events.EventRecorder) *preflight {
+	return &preflight{
+		log:      log,
+		cfg:      cfg,
+		cluster:  cluster,
+		reader:   reader,
+		client:   client,
+		recorder: recorder,
+		failing:  make(map[string]error),
+	}
+}
+
+// checks returns all of the checks to run.
+func (p *preflight) checks() []preflightCheck {
+	return []preflightCheck{
+		{"namespace", func(ctx context.Context) error {
+			var ns corev1.Namespace
+			if err := p.reader.Get(ctx, crclient.ObjectKey{Name: p.cfg.Namespace}, &ns); err != nil {
+				return fmt.Errorf("failed to get controller namespace %q: %w", p.cfg.Namespace, err)
+			}
+			return nil
+		}},
+		{"wrapped-ingress-class", func(ctx context.Context) error {
+			var ic networkingv1.IngressClass
+			if err := p.reader.Get(ctx, crclient.ObjectKey{Name: p.cfg.WrappedIngressClassName}, &ic); err != nil {
+				return fmt.Errorf("failed to get wrapped ingress class %q: %w", p.cfg.WrappedIngressClassName, err)
+			}
+			return nil
+		}},
+		{"ingress-classes", p.checkIngressClasses},
+		{"rbac", p.checkPermissions},
+	}
+}
+
+// checkIngressClasses ensures that the ingress classes handled by the
+// controller exist with [config.Config.ControllerName] as their
+// controller. Missing ones are fine when they're created by
+// [ingressClassManager].
+func (p *preflight) checkIngressClasses(ctx context.Context) error {
+	var errs []error
+	for _, name := range p.cfg.IngressClassNames {
+		var ic networkingv1.IngressClass
+		err := p.reader.Get(ctx, crclient.ObjectKey{Name: name}, &ic)
+		switch {
+		case apierrors.IsNotFound(err) && p.cfg.ManageIngressClasses:
+		case err != nil:
+			errs = append(errs, fmt.Errorf("failed to get ingress class %q: %w", name, err))
+		case ic.Spec.Controller != p.cfg.ControllerName:
+			errs = append(errs, fmt.Errorf("ingress class %q belongs to controller %q instead of %q",
+				name, ic.Spec.Controller, p.cfg.ControllerName))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// requiredPermissions returns all of the permissions the controller
+// needs to function.
+func (p *preflight) requiredPermissions() []authorizationv1.ResourceAttributes {
+	var perms []authorizationv1.ResourceAttributes
+	for _, r := range []struct{ group, resource string }{
+		{"apps", "deployments"},
+		{"", "services"},
+		{"networking.k8s.io", "ingresses"},
+		{"policy", "poddisruptionbudgets"},
+		{"networking.k8s.io", "networkpolicies"},
+	} {
+		for _, verb := range []string{"get", "list", "watch", "create", "patch", "delete", "deletecollection"} {
+			perms = append(perms, authorizationv1.ResourceAttributes{
+				Namespace: p.cfg.Namespace,
+				Group:     r.group,
+				Resource:  r.resource,
+				Verb:      verb,
+			})
+		}
+	}
+
+	// TLS secrets are copied into the controller namespace from the
+	// namespaces of their ingresses.
+	for _, verb := range []string{"list", "watch", "create", "patch", "delete", "deletecollection"} {
+		perms = append(perms, authorizationv1.ResourceAttributes{
+			Namespace: p.cfg.Namespace,
+			Resource:  "secrets",
+			Verb:      verb,
+		})
+	}
+
+	// All resources created for an ingress are deleted at once when it's
+	// deleted, including its in-place services in its namespace.
+	perms = append(perms, authorizationv1.ResourceAttributes{
+		Namespace: p.cfg.Namespace,
+		Resource:  "configmaps",
+		Verb:      "deletecollection",
+	}, authorizationv1.ResourceAttributes{
+		Resource: "services",
+		Verb:     "deletecollection",
+	})
+
+	// The ConfigMaps referenced by ingresses (e.g., bot policies) are
+	// watched.
+	for _, verb := range []string{"list", "watch"} {
+		perms = append(perms, authorizationv1.ResourceAttributes{
+			Resource: "configmaps",
+			Verb:     verb,
+		})
+	}
+	perms = append(perms, authorizationv1.ResourceAttributes{
+		Resource: "secrets",
+		Verb:     "get",
+	})
+
+	// Cluster-wide permissions on the ingresses we're wrapping.
+	for _, verb := range []string{"list", "watch", "patch"} {
+		perms = append(perms, authorizationv1.ResourceAttributes{
+			Group:    "networking.k8s.io",
+			Resource: "ingresses",
+			Verb:     verb,
+		})
+	}
+	perms = append(perms, authorizationv1.ResourceAttributes{
+		Group:       "networking.k8s.io",
+		Resource:    "ingresses",
+		Subresource: "status",
+		Verb:        "patch",
+	})
+
+	// The ingress classes of wrapped ingresses are checked to exist, and
+	// ours are created when missing.
+	verbs := []string{"get", "list", "watch"}
+	if p.cfg.ManageIngressClasses {
+		verbs = append(verbs, "create")
+	}
+	for _, verb := range verbs {
+		perms = append(perms, authorizationv1.ResourceAttributes{
+			Group:    "networking.k8s.io",
+			Resource: "ingressclasses",
+			Verb:     verb,
+		})
+	}
+
+	if p.cfg.IngressClassParams {
+		for _, verb := range []string{"get", "list", "watch"} {
+			perms = append(perms, authorizationv1.ResourceAttributes{
+				Group:    v1alpha1.GroupVersion.Group,
+				Resource: "anubisingressclassparams",
+				Verb:     verb,
+			})
+		}
+	}
+
+	return perms
+}
+
+// checkPermissions ensures that we have all of the permissions returned
+// by [preflight.requiredPermissions].
+func (p *preflight) checkPermissions(ctx context.Context) error {
+	var errs []error
+	for _, attrs := range p.requiredPermissions() {
+		ssar := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attrs},
+		}
+		if err := p.client.Create(ctx, ssar); err != nil {
+			return fmt.Errorf("failed to create access review: %w", err)
+		}
+
+		if !ssar.Status.Allowed {
+			errs = append(errs, fmt.Errorf("missing permission to %s %s (group=%q, subresource=%q, namespace=%q)",
+				attrs.Verb, attrs.Resource, attrs.Group, attrs.Subresource, attrs.Namespace))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// run runs all of the checks once, updating their state.
+func (p *preflight) run(ctx context.Context) {
+	for _, c := range p.checks() {
+		err := c.fn(ctx)
+
+		p.mu.Lock()
+		prevErr, wasFailing := p.failing[c.name]
+		if err != nil {
+			p.failing[c.name] = err
+		} else {
+			delete(p.failing, c.name)
+		}
+		p.mu.Unlock()
+
+		if err == nil {
+			preflightCheckFailing.WithLabelValues(p.cluster, c.name).Set(0)
+			if wasFailing {
+				p.log.Info("preflight check recovered", slog.String("check", c.name))
+			}
+			continue
+		}
+
+		preflightCheckFailing.WithLabelValues(p.cluster, c.name).Set(1)
+		if !wasFailing || prevErr.Error() != err.Error() {
+			p.log.Error("preflight check failed", slog.String("check", c.name), slog.String("err", err.Error()))
+			p.recorder.Eventf(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: p.cfg.Namespace}}, nil,
+				corev1.EventTypeWarning, "PreflightCheckFailed", "Preflight", "Preflight check %q failed: %v", c.name, err)
+		}
+	}
+
+	p.mu.Lock()
+	p.ran = true
+	p.mu.Unlock()
+}
+
+// Start implements [manager.Runnable].
+func (p *preflight) Start(ctx context.Context) error {
+	t := time.NewTicker(p.cfg.PreflightInterval)
+	defer t.Stop()
+
+	for {
+		p.run(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+	}
+}
+
+// NeedLeaderElection implements [manager.LeaderElectionRunnable].
+// Checks run on all replicas since they gate readiness.
+func (p *preflight) NeedLeaderElection() bool {
+	return false
+}
+
+// Check implements [healthz.Checker], failing while any of the checks
+// are failing (or haven't run yet).
+func (p *preflight) Check(_ *http.Request) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if !p.ran {
+		return errors.New("preflight checks have not run yet")
+	}
+
+	errs := make([]error, 0, len(p.failing))
+	for name, err := range p.failing {
+		errs = append(errs, fmt.Errorf("%s: %w", name, err))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,128 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"testing"
+
+	"go.rgst.io/jaredallard/slogext/v2"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/events"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestPreflight(t *testing.T) {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ingress-anubis"}}
+	wrapped := &networkingv1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx"},
+		Spec:       networkingv1.IngressClassSpec{Controller: "k8s.io/ingress-nginx"},
+	}
+	ours := func(controller string) *networkingv1.IngressClass {
+		return &networkingv1.IngressClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "anubis"},
+			Spec:       networkingv1.IngressClassSpec{Controller: controller},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		objs    []crclient.Object
+		denied  string
+		wantErr []string
+	}{
+		{
+			name: "should pass with a usable environment",
+			objs: []crclient.Object{namespace, wrapped},
+		},
+		{
+			name:    "should fail without the controller namespace",
+			objs:    []crclient.Object{wrapped},
+			wantErr: []string{"namespace: "},
+		},
+		{
+			name:    "should fail without the wrapped ingress class",
+			objs:    []crclient.Object{namespace},
+			wantErr: []string{"wrapped-ingress-class: "},
+		},
+		{
+			name:    "should fail without our ingress class when not managed",
+			env:     map[string]string{"MANAGE_INGRESS_CLASSES": "false"},
+			objs:    []crclient.Object{namespace, wrapped},
+			wantErr: []string{"ingress-classes: "},
+		},
+		{
+			name:    "should fail when our ingress class belongs to another controller",
+			objs:    []crclient.Object{namespace, wrapped, ours("example.com/other")},
+			wantErr: []string{`ingress class "anubis" belongs to controller "example.com/other"`},
+		},
+		{
+			name:    "should fail without permissions",
+			objs:    []crclient.Object{namespace, wrapped},
+			denied:  "networkpolicies",
+			wantErr: []string{"rbac: ", "missing permission to create networkpolicies"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := map[string]string{"NAMESPACE": "ingress-anubis"}
+			maps.Copy(env, tt.env)
+			cfg := testConfig(t, env)
+
+			client := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(tt.objs...).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Create: func(_ context.Context, _ crclient.WithWatch, obj crclient.Object, _ ...crclient.CreateOption) error {
+						ssar := obj.(*authorizationv1.SelfSubjectAccessReview)
+						ssar.Status.Allowed = ssar.Spec.ResourceAttributes.Resource != tt.denied
+						return nil
+					},
+				}).Build()
+			p := newPreflight(slogext.New(), cfg, LocalClusterName, client, client, events.NewFakeRecorder(100))
+
+			if err := p.Check(nil); err == nil {
+				t.Errorf("Check() = nil before running the checks")
+			}
+
+			p.run(t.Context())
+			err := p.Check(nil)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("Check() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Check() = nil, want an error containing %q", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Check() error = %v, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// ReferenceWebhookPath is the path that the [ReferenceProtector]
+// webhook is served on.
+const ReferenceWebhookPath = "/validate-v1-references"
+
+// ReferenceProtector is a validating admission webhook that denies
+// deleting ConfigMaps and Secrets that are still referenced by a
+// managed Anubis deployment (e.g., through envFrom or a volume mount).
+// Removing those out from under a running instance otherwise results
+// in crash looping pods and, in turn, an outage of the wrapped site.
+//
+// The webhook is only registered with, and only lists the deployments
+// of, the local cluster. Deployments in remote clusters (see
+// [config.Config.RemoteKubeconfigSecrets]) aren't protected.
+type ReferenceProtector struct {
+	cfg    *config.Config
+	client crclient.Reader
+}
+
+// Handle implements [admission.Handler].
+func (rp *ReferenceProtector) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Delete || req.Namespace != rp.cfg.Namespace {
+		return admission.Allowed("")
+	}
+
+	if req.Kind.Kind != "ConfigMap" && req.Kind.Kind != "Secret" {
+		return admission.Allowed("")
+	}
+
+	var deps appsv1.DeploymentList
+	if err := rp.client.List(ctx, &deps,
+		crclient.InNamespace(rp.cfg.Namespace),
+		crclient.MatchingLabels{ManagedLabel: "true"},
+	); err != nil {
+		return admission.Errored(http.StatusInternalServerError, fmt.Errorf("failed to list managed deployments: %w", err))
+	}
+
+	var users []string
+	for i := range deps.Items {
+		if podSpecReferences(&deps.Items[i].Spec.Template.Spec, req.Kind.Kind, req.Name) {
+			users = append(users, deps.Items[i].Name)
+		}
+	}
+	if len(users) == 0 {
+		return admission.Allowed("")
+	}
+
+	return admission.Denied(fmt.Sprintf("%s %q is still referenced by managed Anubis deployment(s): %s",
+		req.Kind.Kind, req.Name, strings.Join(users, ", ")))
+}
+
+// podSpecReferences returns true if the provided pod spec references
+// an object of the given kind ("ConfigMap" or "Secret") with the given
+// name.
+func podSpecReferences(spec *corev1.PodSpec, kind, name string) bool {
+	isConfigMap := kind == "ConfigMap"
+
+	if !isConfigMap {
+		for _, ref := range spec.ImagePullSecrets {
+			if ref.Name == name {
+				return true
+			}
+		}
+	}
+
+	containers := slices.Concat(spec.InitContainers, spec.Containers)
+	for i := range containers {
+		c := &containers[i]
+		for _, ef := range c.EnvFrom {
+			if isConfigMap && ef.ConfigMapRef != nil && ef.ConfigMapRef.Name == name {
+				return true
+			}
+			if !isConfigMap && ef.SecretRef != nil && ef.SecretRef.Name == name {
+				return true
+			}
+		}
+
+		for _, e := range c.Env {
+			if e.ValueFrom == nil {
+				continue
+			}
+			if isConfigMap && e.ValueFrom.ConfigMapKeyRef != nil && e.ValueFrom.ConfigMapKeyRef.Name == name {
+				return true
+			}
+			if !isConfigMap && e.ValueFrom.SecretKeyRef != nil && e.ValueFrom.SecretKeyRef.Name == name {
+				return true
+			}
+		}
+	}
+
+	for i := range spec.Volumes {
+		v := &spec.Volumes[i]
+		if isConfigMap && v.ConfigMap != nil && v.ConfigMap.Name == name {
+			return true
+		}
+		if !isConfigMap && v.Secret != nil && v.Secret.SecretName == name {
+			return true
+		}
+
+		if v.Projected == nil {
+			continue
+		}
+		for _, s := range v.Projected.Sources {
+			if isConfigMap && s.ConfigMap != nil && s.ConfigMap.Name == name {
+				return true
+			}
+			if !isConfigMap && s.Secret != nil && s.Secret.Name == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,208 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestPodSpecReferences(t *testing.T) {
+	cmKeyRef := &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "env"},
+	}}
+	secKeyRef := &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "env"},
+	}}
+
+	tests := []struct {
+		name string
+		spec corev1.PodSpec
+		kind string
+		want bool
+	}{
+		{name: "empty spec", spec: corev1.PodSpec{}, kind: "ConfigMap"},
+		{
+			name: "configmap env from",
+			spec: corev1.PodSpec{Containers: []corev1.Container{{EnvFrom: []corev1.EnvFromSource{{
+				ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "env"}},
+			}}}}},
+			kind: "ConfigMap",
+			want: true,
+		},
+		{
+			name: "secret env from",
+			spec: corev1.PodSpec{Containers: []corev1.Container{{EnvFrom: []corev1.EnvFromSource{{
+				SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "env"}},
+			}}}}},
+			kind: "Secret",
+			want: true,
+		},
+		{
+			name: "secret env from doesn't reference configmap",
+			spec: corev1.PodSpec{Containers: []corev1.Container{{EnvFrom: []corev1.EnvFromSource{{
+				SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "env"}},
+			}}}}},
+			kind: "ConfigMap",
+		},
+		{
+			name: "configmap env",
+			spec: corev1.PodSpec{Containers: []corev1.Container{{Env: []corev1.EnvVar{{Name: "A", ValueFrom: cmKeyRef}}}}},
+			kind: "ConfigMap",
+			want: true,
+		},
+		{
+			name: "secret env",
+			spec: corev1.PodSpec{Containers: []corev1.Container{{Env: []corev1.EnvVar{{Name: "A", ValueFrom: secKeyRef}}}}},
+			kind: "Secret",
+			want: true,
+		},
+		{
+			name: "literal env",
+			spec: corev1.PodSpec{Containers: []corev1.Container{{Env: []corev1.EnvVar{{Name: "A", Value: "env"}}}}},
+			kind: "ConfigMap",
+		},
+		{
+			name: "init container env",
+			spec: corev1.PodSpec{InitContainers: []corev1.Container{{Env: []corev1.EnvVar{{Name: "A", ValueFrom: secKeyRef}}}}},
+			kind: "Secret",
+			want: true,
+		},
+		{
+			name: "configmap volume",
+			spec: corev1.PodSpec{Volumes: []corev1.Volume{{Name: "policy", VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "env"}},
+			}}}},
+			kind: "ConfigMap",
+			want: true,
+		},
+		{
+			name: "secret volume",
+			spec: corev1.PodSpec{Volumes: []corev1.Volume{{Name: "key", VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: "env"},
+			}}}},
+			kind: "Secret",
+			want: true,
+		},
+		{
+			name: "secret volume with another name",
+			spec: corev1.PodSpec{Volumes: []corev1.Volume{{Name: "key", VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: "other"},
+			}}}},
+			kind: "Secret",
+		},
+		{
+			name: "projected configmap",
+			spec: corev1.PodSpec{Volumes: []corev1.Volume{{Name: "all", VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{{
+					ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "env"}},
+				}}},
+			}}}},
+			kind: "ConfigMap",
+			want: true,
+		},
+		{
+			name: "projected secret",
+			spec: corev1.PodSpec{Volumes: []corev1.Volume{{Name: "all", VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{{
+					Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "env"}},
+				}}},
+			}}}},
+			kind: "Secret",
+			want: true,
+		},
+		{
+			name: "image pull secret",
+			spec: corev1.PodSpec{ImagePullSecrets: []corev1.LocalObjectReference{{Name: "env"}}},
+			kind: "Secret",
+			want: true,
+		},
+		{
+			name: "image pull secret doesn't reference configmap",
+			spec: corev1.PodSpec{ImagePullSecrets: []corev1.LocalObjectReference{{Name: "env"}}},
+			kind: "ConfigMap",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := podSpecReferences(&tt.spec, tt.kind, "env"); got != tt.want {
+				t.Errorf("podSpecReferences() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReferenceProtectorHandle(t *testing.T) {
+	dep := func(name string, managed bool) *appsv1.Deployment {
+		d := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "ingress-anubis", Name: name}}
+		if managed {
+			d.Labels = map[string]string{ManagedLabel: "true"}
+		}
+		d.Spec.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "registry"}}
+		return d
+	}
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).
+		WithObjects(dep("ia-web", true), dep("ia-blog", true), dep("unmanaged", false)).Build()
+	rp := &ReferenceProtector{&config.Config{Namespace: "ingress-anubis"}, c}
+
+	request := func(op admissionv1.Operation, kind, namespace, name string) admission.Request {
+		return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: op,
+			Kind:      metav1.GroupVersionKind{Version: "v1", Kind: kind},
+			Namespace: namespace,
+			Name:      name,
+		}}
+	}
+
+	tests := []struct {
+		name    string
+		req     admission.Request
+		allowed bool
+		message string
+	}{
+		{
+			name:    "referenced secret",
+			req:     request(admissionv1.Delete, "Secret", "ingress-anubis", "registry"),
+			message: `Secret "registry" is still referenced by managed Anubis deployment(s): ia-blog, ia-web`,
+		},
+		{name: "unreferenced secret", req: request(admissionv1.Delete, "Secret", "ingress-anubis", "other"), allowed: true},
+		{name: "configmap of the same name", req: request(admissionv1.Delete, "ConfigMap", "ingress-anubis", "registry"), allowed: true},
+		{name: "other namespace", req: request(admissionv1.Delete, "Secret", "default", "registry"), allowed: true},
+		{name: "update", req: request(admissionv1.Update, "Secret", "ingress-anubis", "registry"), allowed: true},
+		{name: "other kind", req: request(admissionv1.Delete, "Service", "ingress-anubis", "registry"), allowed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := rp.Handle(t.Context(), tt.req)
+			if resp.Allowed != tt.allowed {
+				t.Fatalf("Handle() allowed = %v, want %v (%v)", resp.Allowed, tt.allowed, resp.Result)
+			}
+			if tt.message != "" && resp.Result.Message != tt.message {
+				t.Errorf("Handle() message = %q, want %q", resp.Result.Message, tt.message)
+			}
+		})
+	}
+}
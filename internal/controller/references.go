@@ -0,0 +1,148 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// configMapRefsIndex indexes ingresses by the ConfigMaps, as
+	// "namespace/name", referenced by their annotations.
+	configMapRefsIndex = "ingress-anubis.jaredallard.github.com/configmaps"
+
+	// secretRefsIndex indexes ingresses by the Secrets, as
+	// "namespace/name", referenced by their annotations.
+	secretRefsIndex = "ingress-anubis.jaredallard.github.com/secrets"
+)
+
+// referenceIndexes returns the functions of [configMapRefsIndex] and
+// [secretRefsIndex].
+func referenceIndexes(cfg *config.Config) map[string]crclient.IndexerFunc {
+	refs := func(obj crclient.Object, secret bool) []string {
+		ing, ok := obj.(*networkingv1.Ingress)
+		if !ok || ing.Labels[ManagedLabel] == "true" {
+			return nil
+		}
+
+		// Invalid annotations are reported by the reconciler.
+		icfg, err := cfg.GetIngressConfig(ing, nil)
+		if err != nil {
+			return nil
+		}
+
+		var keys []string
+		add := func(ns string, name *string) {
+			if name != nil && *name != "" {
+				keys = append(keys, ns+"/"+*name)
+			}
+		}
+		if secret {
+			add(cfg.Namespace, icfg.EnvFromSec)
+			add(cfg.Namespace, icfg.SigningKeySecret)
+		} else {
+			add(ing.Namespace, icfg.PolicyConfigMap)
+			add(cfg.Namespace, icfg.EnvFromCM)
+		}
+		return keys
+	}
+
+	return map[string]crclient.IndexerFunc{
+		configMapRefsIndex: func(obj crclient.Object) []string { return refs(obj, false) },
+		secretRefsIndex:    func(obj crclient.Object) []string { return refs(obj, true) },
+	}
+}
+
+// indexReferences registers [referenceIndexes] with the provided field
+// indexer.
+func indexReferences(ctx context.Context, cfg *config.Config, indexer crclient.FieldIndexer) error {
+	for name, fn := range referenceIndexes(cfg) {
+		if err := indexer.IndexField(ctx, &networkingv1.Ingress{}, name, fn); err != nil {
+			return fmt.Errorf("failed to index ingresses by %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// referenceRequests returns a [handler.TypedMapFunc] mapping ConfigMaps
+// (or Secrets, when secret is true) to the ingresses referencing them,
+// either through their annotations (e.g., a bot policy) or through the
+// environment of their anubis deployments (e.g., [config.Config.EnvFromCM]),
+// so that changes to them, or creating them when they were missing, are
+// applied right away.
+func referenceRequests[T crclient.Object](cfg *config.Config, r crclient.Reader,
+	secret bool) handler.TypedMapFunc[T, reconcile.Request] {
+	index := configMapRefsIndex
+	if secret {
+		index = secretRefsIndex
+	}
+
+	return func(ctx context.Context, obj T) []reconcile.Request {
+		if obj.GetLabels()[ManagedLabel] == "true" {
+			return nil
+		}
+
+		var reqs []reconcile.Request
+		var ings networkingv1.IngressList
+		if err := r.List(ctx, &ings, crclient.MatchingFields{index: obj.GetNamespace() + "/" + obj.GetName()}); err == nil {
+			for i := range ings.Items {
+				reqs = append(reqs, reconcile.Request{NamespacedName: crclient.ObjectKeyFromObject(&ings.Items[i])})
+			}
+		}
+
+		// Global and ingress class sources aren't referenced by ingresses.
+		if obj.GetNamespace() != cfg.Namespace {
+			return reqs
+		}
+
+		var deps appsv1.DeploymentList
+		if err := r.List(ctx, &deps, crclient.InNamespace(cfg.Namespace),
+			crclient.MatchingLabels{ManagedLabel: "true"}); err != nil {
+			return reqs
+		}
+		for i := range deps.Items {
+			dep := &deps.Items[i]
+			owner, ok := getOwner(dep.Labels)
+			if !ok {
+				continue
+			}
+			for _, c := range dep.Spec.Template.Spec.Containers {
+				if slices.ContainsFunc(c.EnvFrom, func(src corev1.EnvFromSource) bool {
+					if secret {
+						return src.SecretRef != nil && src.SecretRef.Name == obj.GetName()
+					}
+					return src.ConfigMapRef != nil && src.ConfigMapRef.Name == obj.GetName()
+				}) {
+					reqs = append(reqs, reconcile.Request{NamespacedName: owner})
+					break
+				}
+			}
+		}
+		return reqs
+	}
+}
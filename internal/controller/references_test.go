@@ -0,0 +1,115 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReferenceRequests(t *testing.T) {
+	web := []reconcile.Request{{NamespacedName: crclient.ObjectKey{Namespace: "default", Name: "web"}}}
+	meta := func(ns, name string, labels map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Namespace: ns, Name: name, Labels: labels}
+	}
+
+	tests := []struct {
+		name        string
+		env         map[string]string
+		annotations map[config.AnnotationKey]string
+		obj         crclient.Object
+		want        []reconcile.Request
+	}{
+		{
+			name:        "should map a policy configmap to its ingress",
+			annotations: map[config.AnnotationKey]string{config.AnnotationKeyPolicyConfigMap: "policy"},
+			obj:         &corev1.ConfigMap{ObjectMeta: meta("default", "policy", nil)},
+			want:        web,
+		},
+		{
+			name:        "should not map a policy configmap from another namespace",
+			annotations: map[config.AnnotationKey]string{config.AnnotationKeyPolicyConfigMap: "policy"},
+			obj:         &corev1.ConfigMap{ObjectMeta: meta("other", "policy", nil)},
+		},
+		{
+			name:        "should map a signing key secret to its ingress",
+			annotations: map[config.AnnotationKey]string{config.AnnotationKeySigningKeySecret: "key"},
+			obj:         &corev1.Secret{ObjectMeta: meta("ingress-anubis", "key", nil)},
+			want:        web,
+		},
+		{
+			name: "should map the global environment to every ingress using it",
+			env:  map[string]string{"ENV_FROM_CM": "anubis-env"},
+			obj:  &corev1.ConfigMap{ObjectMeta: meta("ingress-anubis", "anubis-env", nil)},
+			want: web,
+		},
+		{
+			name: "should map the global secret environment to every ingress using it",
+			env:  map[string]string{"ENV_FROM_SEC": "anubis-env"},
+			obj:  &corev1.Secret{ObjectMeta: meta("ingress-anubis", "anubis-env", nil)},
+			want: web,
+		},
+		{
+			name: "should not map a secret named like a referenced configmap",
+			env:  map[string]string{"ENV_FROM_CM": "anubis-env"},
+			obj:  &corev1.Secret{ObjectMeta: meta("ingress-anubis", "anubis-env", nil)},
+		},
+		{
+			name:        "should ignore managed objects",
+			annotations: map[config.AnnotationKey]string{config.AnnotationKeyPolicyConfigMap: "policy"},
+			obj:         &corev1.ConfigMap{ObjectMeta: meta("default", "policy", map[string]string{ManagedLabel: "true"})},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := map[string]string{"NAMESPACE": "ingress-anubis"}
+			maps.Copy(env, tt.env)
+			cfg := testConfig(t, env)
+
+			annotations := make(map[string]string, len(tt.annotations))
+			for k, v := range tt.annotations {
+				annotations[k.String()] = v
+			}
+			ing := testIngress(cfg, annotations)
+			ir := newTestReconciler(t, cfg, ing)
+			reconcileTestIngress(t, ir, crclient.ObjectKeyFromObject(ing))
+
+			var got []reconcile.Request
+			switch obj := tt.obj.(type) {
+			case *corev1.Secret:
+				got = referenceRequests[*corev1.Secret](cfg, ir.client, true)(t.Context(), obj)
+			case *corev1.ConfigMap:
+				got = referenceRequests[*corev1.ConfigMap](cfg, ir.client, false)(t.Context(), obj)
+			}
+
+			// The same ingress may be found both by index and deployment.
+			got = slices.Compact(got)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("referenceRequests() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
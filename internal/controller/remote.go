@@ -0,0 +1,159 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	"go.rgst.io/jaredallard/slogext/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+)
+
+// KubeconfigSecretKey is the key in a remote cluster Secret that
+// contains the kubeconfig to use. See
+// [config.Config.RemoteKubeconfigSecrets].
+const KubeconfigSecretKey = "kubeconfig"
+
+// newRemoteCluster creates a cluster from the kubeconfig stored in the
+// Secret secretName and registers it with the provided manager. The
+// kubeconfig is recorded with kw, so that changes to it are picked up.
+func (s *KubernetesService) newRemoteCluster(ctx context.Context, mgr ctrl.Manager,
+	kw *kubeconfigWatcher, secretName string) (cluster.Cluster, error) {
+	kubeconfig, err := kw.read(ctx, secretName)
+	if err != nil {
+		return nil, err
+	}
+	kw.kubeconfigs[secretName] = kubeconfig
+
+	remoteCfg, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+
+	cl, err := cluster.New(remoteCfg, func(o *cluster.Options) {
+		o.Scheme = mgr.GetScheme()
+		o.Client = clientOptions(s.cfg)
+		o.Cache = cacheOptions(s.cfg)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cluster: %w", err)
+	}
+
+	if err := mgr.Add(cl); err != nil {
+		return nil, fmt.Errorf("failed to add cluster to manager: %w", err)
+	}
+
+	return cl, nil
+}
+
+// kubeconfigWatcher watches the Secrets containing the kubeconfigs of
+// remote clusters (see [config.Config.RemoteKubeconfigSecrets]) for
+// changes, stopping the manager with [ErrConfigChanged] when one of
+// them changes. The clients of a cluster can't be replaced while it's
+// running, so the controller has to be started again to use the new
+// kubeconfig (e.g., rotated credentials).
+//
+// kubeconfigWatcher implements [manager.Runnable] and runs on all
+// replicas.
+type kubeconfigWatcher struct {
+	log    slogext.Logger
+	cfg    *config.Config
+	client crclient.Reader
+
+	// kubeconfigs contains the kubeconfig each remote cluster was
+	// created with, by the name of its Secret.
+	kubeconfigs map[string][]byte
+}
+
+// newKubeconfigWatcher creates a new [kubeconfigWatcher] reading
+// Secrets through the provided client. The manager's cache isn't
+// started when remote clusters are created, so it should read from the
+// API server directly.
+func newKubeconfigWatcher(log slogext.Logger, cfg *config.Config, client crclient.Reader) *kubeconfigWatcher {
+	return &kubeconfigWatcher{log: log, cfg: cfg, client: client, kubeconfigs: make(map[string][]byte)}
+}
+
+// read returns the kubeconfig stored in the Secret secretName.
+func (w *kubeconfigWatcher) read(ctx context.Context, secretName string) ([]byte, error) {
+	var sec corev1.Secret
+	if err := w.client.Get(ctx, crclient.ObjectKey{Namespace: w.cfg.Namespace, Name: secretName}, &sec); err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig secret: %w", err)
+	}
+
+	kubeconfig, ok := sec.Data[KubeconfigSecretKey]
+	if !ok {
+		return nil, fmt.Errorf("secret is missing key %q", KubeconfigSecretKey)
+	}
+	return kubeconfig, nil
+}
+
+// changed returns the name of the first Secret whose kubeconfig differs
+// from the one its cluster was created with, if any.
+func (w *kubeconfigWatcher) changed(ctx context.Context) (string, error) {
+	for _, secretName := range w.cfg.RemoteKubeconfigSecrets {
+		kubeconfig, err := w.read(ctx, secretName)
+		if err != nil {
+			return "", fmt.Errorf("failed to read kubeconfig secret %q: %w", secretName, err)
+		}
+		if !bytes.Equal(kubeconfig, w.kubeconfigs[secretName]) {
+			return secretName, nil
+		}
+	}
+
+	return "", nil
+}
+
+// Start implements [manager.Runnable].
+func (w *kubeconfigWatcher) Start(ctx context.Context) error {
+	t := time.NewTicker(w.cfg.ConfigReloadInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+
+		secretName, err := w.changed(ctx)
+		if err != nil {
+			w.log.Error("failed to check for kubeconfig changes", slog.String("err", err.Error()))
+			continue
+		}
+		if secretName != "" {
+			w.log.Info("kubeconfig secret changed, restarting", slog.String("secret", secretName))
+			return fmt.Errorf("kubeconfig secret %q changed: %w", secretName, ErrConfigChanged)
+		}
+	}
+}
+
+// NeedLeaderElection implements [manager.LeaderElectionRunnable]. All
+// replicas create the remote clusters, so all of them need to pick up
+// changes.
+func (w *kubeconfigWatcher) NeedLeaderElection() bool {
+	return false
+}
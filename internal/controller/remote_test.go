@@ -0,0 +1,69 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	"go.rgst.io/jaredallard/slogext/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestKubeconfigWatcher(t *testing.T) {
+	secret := func(name, kubeconfig string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ingress-anubis", Name: name},
+			Data:       map[string][]byte{KubeconfigSecretKey: []byte(kubeconfig)},
+		}
+	}
+	a, b := secret("cluster-a", "a"), secret("cluster-b", "b")
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(a, b).Build()
+	cfg := &config.Config{Namespace: "ingress-anubis", RemoteKubeconfigSecrets: []string{"cluster-a", "cluster-b"}}
+
+	w := newKubeconfigWatcher(slogext.New(), cfg, c)
+	for _, name := range cfg.RemoteKubeconfigSecrets {
+		kubeconfig, err := w.read(t.Context(), name)
+		if err != nil {
+			t.Fatalf("read(%s) error = %v", name, err)
+		}
+		w.kubeconfigs[name] = kubeconfig
+	}
+
+	if got, err := w.changed(t.Context()); err != nil || got != "" {
+		t.Errorf("changed() = %q, %v, want no changes", got, err)
+	}
+
+	b.Data[KubeconfigSecretKey] = []byte("rotated")
+	if err := c.Update(t.Context(), b); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got, err := w.changed(t.Context()); err != nil || got != "cluster-b" {
+		t.Errorf("changed() = %q, %v, want cluster-b", got, err)
+	}
+
+	if err := c.Delete(t.Context(), a); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := w.changed(t.Context()); err == nil {
+		t.Error("changed() expected error for a missing secret")
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	"go.rgst.io/jaredallard/slogext/v2"
+	"golang.org/x/time/rate"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/ptr"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// rolloutLimiter limits how many managed deployments are rolled over a
+// period of time, so that a fleet-wide configuration change doesn't
+// restart every Anubis instance at once. See
+// [config.Config.RolloutLimit]. A nil rolloutLimiter never limits.
+type rolloutLimiter struct {
+	l *rate.Limiter
+}
+
+// newRolloutLimiter creates a [rolloutLimiter] from the provided
+// configuration. If rollouts are not limited, nil is returned.
+func newRolloutLimiter(cfg *config.Config) *rolloutLimiter {
+	if cfg.RolloutLimit <= 0 {
+		return nil
+	}
+
+	every := cfg.RolloutLimitPeriod / time.Duration(cfg.RolloutLimit)
+	return &rolloutLimiter{rate.NewLimiter(rate.Every(every), cfg.RolloutLimit)}
+}
+
+// reserve attempts to reserve a rollout. If one is not available, the
+// amount of time to wait before trying again is returned. Otherwise, 0
+// is returned and the rollout may proceed.
+func (rl *rolloutLimiter) reserve() time.Duration {
+	if rl == nil {
+		return 0
+	}
+
+	r := rl.l.Reserve()
+	if d := r.Delay(); d > 0 {
+		r.Cancel()
+		return d
+	}
+
+	return 0
+}
+
+// imageRollout rolls managed deployments to a new anubis image (e.g.,
+// when [config.Config.AnubisVersion] changes) in batches, see
+// [config.Config.ImageRolloutBatchSize]. A nil imageRollout never holds
+// back deployments.
+type imageRollout struct {
+	log    slogext.Logger
+	cfg    *config.Config
+	client crclient.Reader
+
+	mu sync.Mutex
+
+	// batch contains the deployments rolled in the current batch.
+	batch []crclient.ObjectKey
+
+	// started is when the current batch started.
+	started time.Time
+
+	// gated is true when the next batch is held back because the current
+	// one isn't available, see [config.Config.ImageRolloutHealthGate].
+	gated bool
+}
+
+// newImageRollout creates an [imageRollout] for a cluster. If image
+// rollouts aren't batched, nil is returned.
+func newImageRollout(log slogext.Logger, cfg *config.Config, client crclient.Reader) *imageRollout {
+	if cfg.ImageRolloutBatchSize <= 0 {
+		return nil
+	}
+	return &imageRollout{log: log, cfg: cfg, client: client}
+}
+
+// reserve attempts to reserve a rollout of the deployment with the
+// provided key to a new image. If it's held back, the amount of time to
+// wait before trying again is returned. Otherwise, 0 is returned and the
+// rollout may proceed.
+func (r *imageRollout) reserve(ctx context.Context, key crclient.ObjectKey) (time.Duration, error) {
+	if r == nil {
+		return 0, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Deployments of the current batch may be rolled again, e.g., to roll
+	// back a bad release.
+	if slices.Contains(r.batch, key) {
+		return 0, nil
+	}
+
+	if len(r.batch) >= r.cfg.ImageRolloutBatchSize {
+		if wait := time.Until(r.started.Add(r.cfg.ImageRolloutBatchInterval)); wait > 0 {
+			return wait, nil
+		}
+
+		if r.cfg.ImageRolloutHealthGate {
+			available, err := r.available(ctx)
+			if err != nil {
+				return 0, err
+			}
+			if !available {
+				if !r.gated {
+					r.log.Warn("holding back image rollout until the previous batch is available",
+						slog.Any("deployments", r.batch))
+				}
+				r.gated = true
+				return r.cfg.ImageRolloutBatchInterval, nil
+			}
+			r.gated = false
+		}
+		r.batch = nil
+	}
+
+	if len(r.batch) == 0 {
+		r.started = time.Now()
+	}
+	r.batch = append(r.batch, key)
+	return 0, nil
+}
+
+// available returns true if all of the deployments of the current batch
+// have rolled out and are available. Deleted deployments are ignored.
+func (r *imageRollout) available(ctx context.Context) (bool, error) {
+	for _, key := range r.batch {
+		var dep appsv1.Deployment
+		if err := r.client.Get(ctx, key, &dep); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return false, fmt.Errorf("failed to get deployment %s: %w", key, err)
+		}
+
+		if !deploymentAvailable(&dep) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// deploymentAvailable returns true if the provided deployment has
+// rolled out and all of its replicas are available.
+func deploymentAvailable(dep *appsv1.Deployment) bool {
+	replicas := ptr.Deref(dep.Spec.Replicas, 1)
+	return dep.Status.ObservedGeneration >= dep.Generation && dep.Status.UpdatedReplicas >= replicas &&
+		dep.Status.AvailableReplicas >= replicas
+}
+
+// containerImage returns the image of the container with the provided
+// name in spec, if any.
+func containerImage(spec *corev1.PodSpec, name string) string {
+	if i := slices.IndexFunc(spec.Containers, func(c corev1.Container) bool { return c.Name == name }); i >= 0 {
+		return spec.Containers[i].Image
+	}
+	return ""
+}
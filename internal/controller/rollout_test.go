@@ -0,0 +1,177 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	"go.rgst.io/jaredallard/slogext/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRolloutLimit(t *testing.T) {
+	tests := []struct {
+		name           string
+		limit          int
+		wantDifficulty []string
+		wantDeferred   []bool
+	}{
+		{
+			name:           "unlimited",
+			limit:          0,
+			wantDifficulty: []string{"5", "6"},
+			wantDeferred:   []bool{false, false},
+		},
+		{
+			name:           "limited",
+			limit:          1,
+			wantDifficulty: []string{"5", "5"},
+			wantDeferred:   []bool{false, true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, map[string]string{
+				"NAMESPACE":            "ingress-anubis",
+				"ROLLOUT_LIMIT":        strconv.Itoa(tt.limit),
+				"ROLLOUT_LIMIT_PERIOD": "1h",
+			})
+			ing := testIngress(cfg, nil)
+			ir := newTestReconciler(t, cfg, ing)
+
+			// Creating the deployment is never limited.
+			if res := reconcileTestIngress(t, ir, crclient.ObjectKeyFromObject(ing)); res.RequeueAfter > 0 {
+				t.Fatalf("Reconcile() deferred the creation of the deployment")
+			}
+
+			for i, difficulty := range []string{"5", "6"} {
+				if err := ir.client.Get(t.Context(), crclient.ObjectKeyFromObject(ing), ing); err != nil {
+					t.Fatalf("failed to get ingress: %v", err)
+				}
+				ing.Annotations = map[string]string{config.AnnotationKeyDifficulty.String(): difficulty}
+				if err := ir.client.Update(t.Context(), ing); err != nil {
+					t.Fatalf("failed to update ingress: %v", err)
+				}
+
+				res := reconcileTestIngress(t, ir, crclient.ObjectKeyFromObject(ing))
+				if deferred := res.RequeueAfter > 0; deferred != tt.wantDeferred[i] {
+					t.Errorf("rollout %d deferred = %v, want %v", i, deferred, tt.wantDeferred[i])
+				}
+				if got := testDeploymentEnv(t, ir, "ia-web-82b3ade9")["DIFFICULTY"]; got != tt.wantDifficulty[i] {
+					t.Errorf("rollout %d DIFFICULTY = %q, want %q", i, got, tt.wantDifficulty[i])
+				}
+			}
+		})
+	}
+}
+
+func TestImageRolloutReserve(t *testing.T) {
+	deployment := func(name string, available bool) *appsv1.Deployment {
+		dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ingress-anubis"}}
+		if available {
+			dep.Status = appsv1.DeploymentStatus{UpdatedReplicas: 1, AvailableReplicas: 1}
+		}
+		return dep
+	}
+
+	tests := []struct {
+		name       string
+		batchSize  int
+		interval   time.Duration
+		healthGate bool
+		objs       []crclient.Object
+		reserve    []string
+		wantHeld   []bool
+	}{
+		{
+			name:      "should roll up to the batch size",
+			batchSize: 2,
+			interval:  time.Hour,
+			reserve:   []string{"a", "b", "c"},
+			wantHeld:  []bool{false, false, true},
+		},
+		{
+			name:      "should roll deployments of the current batch again",
+			batchSize: 1,
+			interval:  time.Hour,
+			reserve:   []string{"a", "a"},
+			wantHeld:  []bool{false, false},
+		},
+		{
+			name:      "should start the next batch after the interval",
+			batchSize: 1,
+			interval:  time.Nanosecond,
+			reserve:   []string{"a", "b"},
+			wantHeld:  []bool{false, false},
+		},
+		{
+			name:       "should hold back the next batch until the previous one is available",
+			batchSize:  1,
+			interval:   time.Nanosecond,
+			healthGate: true,
+			objs:       []crclient.Object{deployment("a", false)},
+			reserve:    []string{"a", "b"},
+			wantHeld:   []bool{false, true},
+		},
+		{
+			name:       "should start the next batch once the previous one is available",
+			batchSize:  1,
+			interval:   time.Nanosecond,
+			healthGate: true,
+			objs:       []crclient.Object{deployment("a", true)},
+			reserve:    []string{"a", "b"},
+			wantHeld:   []bool{false, false},
+		},
+		{
+			name:       "should ignore deleted deployments",
+			batchSize:  1,
+			interval:   time.Nanosecond,
+			healthGate: true,
+			reserve:    []string{"a", "b"},
+			wantHeld:   []bool{false, false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				ImageRolloutBatchSize:     tt.batchSize,
+				ImageRolloutBatchInterval: tt.interval,
+				ImageRolloutHealthGate:    tt.healthGate,
+			}
+			client := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(tt.objs...).Build()
+			r := newImageRollout(slogext.New(), cfg, client)
+
+			for i, name := range tt.reserve {
+				wait, err := r.reserve(t.Context(), crclient.ObjectKey{Namespace: "ingress-anubis", Name: name})
+				if err != nil {
+					t.Fatalf("reserve() error = %v", err)
+				}
+				if held := wait > 0; held != tt.wantHeld[i] {
+					t.Errorf("reserve(%q) held = %v, want %v", name, held, tt.wantHeld[i])
+				}
+			}
+		})
+	}
+}
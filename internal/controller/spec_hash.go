@@ -0,0 +1,140 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SpecHashAnnotation is the annotation containing the hash of the
+// desired state of a resource when it was last applied, see
+// [appliedVersions].
+const SpecHashAnnotation = "ingress-anubis.jaredallard.github.com/spec-hash"
+
+// specHash returns a hash of the desired state of a resource, as
+// returned by [runtime.DefaultUnstructuredConverter.ToUnstructured].
+func specHash(u map[string]any) (string, error) {
+	// Maps are encoded with sorted keys, so this is stable.
+	b, err := json.Marshal(u)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:]), nil
+}
+
+// appliedVersions tracks the resource version of each resource after it
+// was last applied. A resource whose [SpecHashAnnotation] matches its
+// desired state is only up to date if it wasn't changed since, e.g., by
+// someone editing it by hand, which the hash alone can't tell.
+//
+// It's kept in memory, so every resource is applied once after a
+// restart.
+//
+// With [config.Config.DryRun], nothing is applied, so it instead tracks
+// the changes that were reported for each resource, see
+// [appliedVersions.reported].
+type appliedVersions struct {
+	mu       sync.Mutex
+	versions map[appliedKey]string
+	dryRuns  map[appliedKey]string
+}
+
+// appliedKey identifies a resource in [appliedVersions].
+type appliedKey struct {
+	// owner is the ingress owning the resource, see [getOwner].
+	owner crclient.ObjectKey
+	gvk   schema.GroupVersionKind
+	key   crclient.ObjectKey
+}
+
+// newAppliedKey returns the [appliedKey] of obj.
+func newAppliedKey(gvk schema.GroupVersionKind, obj crclient.Object) appliedKey {
+	owner, _ := getOwner(obj.GetLabels())
+	return appliedKey{owner, gvk, crclient.ObjectKeyFromObject(obj)}
+}
+
+// newAppliedVersions creates an empty [appliedVersions].
+func newAppliedVersions() *appliedVersions {
+	return &appliedVersions{versions: make(map[appliedKey]string), dryRuns: make(map[appliedKey]string)}
+}
+
+// upToDate returns true if current, the existing resource, was last
+// applied with the provided hash and wasn't changed since.
+func (av *appliedVersions) upToDate(gvk schema.GroupVersionKind, current crclient.Object, hash string) bool {
+	if current.GetAnnotations()[SpecHashAnnotation] != hash {
+		return false
+	}
+
+	av.mu.Lock()
+	defer av.mu.Unlock()
+	rv, ok := av.versions[newAppliedKey(gvk, current)]
+	return ok && rv == current.GetResourceVersion()
+}
+
+// record records the resource version of obj, which was just applied.
+func (av *appliedVersions) record(gvk schema.GroupVersionKind, obj crclient.Object) {
+	av.mu.Lock()
+	defer av.mu.Unlock()
+	av.versions[newAppliedKey(gvk, obj)] = obj.GetResourceVersion()
+}
+
+// dryRunVersion identifies the changes a dry run of applying a
+// resource with the provided hash reports against current, the existing
+// resource (if any).
+func dryRunVersion(current crclient.Object, hash string) string {
+	return hash + "/" + current.GetResourceVersion()
+}
+
+// reported returns true if the changes of applying obj with the
+// provided hash to current were already reported by a dry run, see
+// [appliedVersions.recordReported].
+func (av *appliedVersions) reported(gvk schema.GroupVersionKind, obj, current crclient.Object, hash string) bool {
+	av.mu.Lock()
+	defer av.mu.Unlock()
+	v, ok := av.dryRuns[newAppliedKey(gvk, obj)]
+	return ok && v == dryRunVersion(current, hash)
+}
+
+// recordReported records that the changes of applying obj with the
+// provided hash to current were reported by a dry run, so that they're
+// only reported again once either changes.
+func (av *appliedVersions) recordReported(gvk schema.GroupVersionKind, obj, current crclient.Object, hash string) {
+	av.mu.Lock()
+	defer av.mu.Unlock()
+	av.dryRuns[newAppliedKey(gvk, obj)] = dryRunVersion(current, hash)
+}
+
+// forget forgets the resources owned by the provided ingress, which was
+// deleted.
+func (av *appliedVersions) forget(owner crclient.ObjectKey) {
+	av.mu.Lock()
+	defer av.mu.Unlock()
+	isOwned := func(k appliedKey, _ string) bool {
+		return k.owner == owner
+	}
+	maps.DeleteFunc(av.versions, isOwned)
+	maps.DeleteFunc(av.dryRuns, isOwned)
+}
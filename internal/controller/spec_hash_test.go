@@ -0,0 +1,125 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestReconcileSkipsUpToDate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(t *testing.T, ir *IngressReconciler)
+
+		// want are the resources, as "Kind/name", applied again. If nil,
+		// every resource is expected to be applied again.
+		want []string
+	}{
+		{
+			name:   "should not apply unchanged resources",
+			modify: func(*testing.T, *IngressReconciler) {},
+			want:   []string{},
+		},
+		{
+			name: "should apply resources changed by hand",
+			modify: func(t *testing.T, ir *IngressReconciler) {
+				dep := testDeployment(t, ir, "ia-web-82b3ade9")
+				dep.Spec.Replicas = ptr.To[int32](5)
+				if err := ir.client.Update(t.Context(), dep); err != nil {
+					t.Fatalf("failed to update deployment: %v", err)
+				}
+			},
+			want: []string{"Deployment/ia-web-82b3ade9"},
+		},
+		{
+			name: "should apply resources whose desired state changed",
+			modify: func(t *testing.T, ir *IngressReconciler) {
+				ing := testIngress(ir.cfg, nil)
+				if err := ir.client.Get(t.Context(), crclient.ObjectKeyFromObject(ing), ing); err != nil {
+					t.Fatalf("failed to get ingress: %v", err)
+				}
+				ing.Annotations = map[string]string{config.AnnotationKeyDifficulty.String(): "6"}
+				if err := ir.client.Update(t.Context(), ing); err != nil {
+					t.Fatalf("failed to update ingress: %v", err)
+				}
+			},
+			// The annotations of the ingress are copied to the child
+			// ingress.
+			want: []string{"Deployment/ia-web-82b3ade9", "Ingress/ia-web-82b3ade9"},
+		},
+		{
+			name: "should apply every resource once after a restart",
+			modify: func(_ *testing.T, ir *IngressReconciler) {
+				ir.applied = newAppliedVersions()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, map[string]string{"NAMESPACE": "ingress-anubis"})
+			ing := testIngress(cfg, nil)
+			ir := newTestReconciler(t, cfg, ing)
+
+			var mu sync.Mutex
+			var applied []string
+			ir.client = interceptor.NewClient(ir.client.(crclient.WithWatch), interceptor.Funcs{
+				Apply: func(ctx context.Context, c crclient.WithWatch, obj runtime.ApplyConfiguration, opts ...crclient.ApplyOption) error {
+					if u, ok := obj.(interface {
+						GetKind() string
+						GetName() string
+					}); ok {
+						mu.Lock()
+						applied = append(applied, u.GetKind()+"/"+u.GetName())
+						mu.Unlock()
+					}
+					return c.Apply(ctx, obj, opts...)
+				},
+			})
+
+			reconcileTestIngress(t, ir, crclient.ObjectKeyFromObject(ing))
+			all := applied
+			slices.Sort(all)
+			if len(all) == 0 {
+				t.Fatal("expected the first reconcile to apply resources")
+			}
+
+			applied = []string{}
+			tt.modify(t, ir)
+			reconcileTestIngress(t, ir, crclient.ObjectKeyFromObject(ing))
+			slices.Sort(applied)
+
+			want := tt.want
+			if want == nil {
+				want = all
+			}
+			if diff := cmp.Diff(want, applied); diff != "" {
+				t.Errorf("applied resources mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
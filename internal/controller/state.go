@@ -0,0 +1,136 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// StateAnnotationPrefix is the prefix of the annotations used to expose
+// the reconciliation state of an ingress on the ingress itself, so that
+// it can be inspected without access to the controller namespace.
+const StateAnnotationPrefix = "status.ingress-anubis.jaredallard.github.com/"
+
+const (
+	// ObservedGenerationAnnotation contains the generation of the ingress
+	// that was last reconciled.
+	ObservedGenerationAnnotation = StateAnnotationPrefix + "observed-generation"
+
+	// LastReconciledAnnotation contains the time (RFC 3339) the ingress
+	// was last reconciled at.
+	LastReconciledAnnotation = StateAnnotationPrefix + "last-reconciled"
+
+	// ResourcesAnnotation contains a comma-separated list of the
+	// resources created for the ingress, as kind/name, in the controller
+	// namespace.
+	ResourcesAnnotation = StateAnnotationPrefix + "resources"
+
+	// ErrorAnnotation contains the error the last reconciliation failed
+	// with. It's removed once the ingress reconciles successfully.
+	ErrorAnnotation = StateAnnotationPrefix + "error"
+)
+
+// recordState records the result of reconciling the provided ingress,
+// reconcileErr, in the state annotations (see [StateAnnotationPrefix])
+// on it.
+func (ir *IngressReconciler) recordState(ctx context.Context, ing *networkingv1.Ingress,
+	req reconcile.Request, reconcileErr error) error {
+	owned, err := ir.listOwned(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	resources := make([]string, 0, len(owned))
+	for _, obj := range owned {
+		gvk, err := ir.client.GroupVersionKindFor(obj)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, gvk.Kind+"/"+obj.GetName())
+	}
+	slices.Sort(resources)
+
+	orig := ing.DeepCopy()
+	if ing.Annotations == nil {
+		ing.Annotations = make(map[string]string)
+	}
+	ing.Annotations[ObservedGenerationAnnotation] = strconv.FormatInt(ing.Generation, 10)
+	ing.Annotations[LastReconciledAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	ing.Annotations[ResourcesAnnotation] = strings.Join(slices.Compact(resources), ",")
+	if reconcileErr != nil {
+		ing.Annotations[ErrorAnnotation] = reconcileErr.Error()
+	} else {
+		delete(ing.Annotations, ErrorAnnotation)
+	}
+
+	if equality.Semantic.DeepEqual(orig.Annotations, ing.Annotations) {
+		return nil
+	}
+	if err := ir.client.Patch(ctx, ing, crclient.StrategicMergeFrom(orig)); err != nil {
+		return fmt.Errorf("failed to record state: %w", err)
+	}
+	return nil
+}
+
+// clearState removes the state annotations (see
+// [StateAnnotationPrefix]) from the provided ingress, if any.
+func (ir *IngressReconciler) clearState(ctx context.Context, ing *networkingv1.Ingress) error {
+	orig := ing.DeepCopy()
+	maps.DeleteFunc(ing.Annotations, func(k, _ string) bool { return strings.HasPrefix(k, StateAnnotationPrefix) })
+	if len(orig.Annotations) == len(ing.Annotations) {
+		return nil
+	}
+
+	if err := ir.client.Patch(ctx, ing, crclient.StrategicMergeFrom(orig)); err != nil {
+		return fmt.Errorf("failed to clear state: %w", err)
+	}
+	return nil
+}
+
+// stateChangedPredicate returns a predicate filtering out updates that
+// only change the state annotations (see [StateAnnotationPrefix]),
+// which would otherwise cause every reconciliation to trigger another
+// one.
+func stateChangedPredicate[T crclient.Object]() predicate.TypedPredicate[T] {
+	return predicate.TypedFuncs[T]{
+		UpdateFunc: func(e event.TypedUpdateEvent[T]) bool {
+			strip := func(obj T) crclient.Object {
+				//nolint:errcheck // Why: DeepCopyObject always returns the same type.
+				c := obj.DeepCopyObject().(crclient.Object)
+				c.SetResourceVersion("")
+				c.SetManagedFields(nil)
+				c.SetAnnotations(stripAnnotations(c.GetAnnotations(), prefixPatterns([]string{StateAnnotationPrefix})))
+				return c
+			}
+			return !equality.Semantic.DeepEqual(strip(e.ObjectOld), strip(e.ObjectNew))
+		},
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	networkingv1 "k8s.io/api/networking/v1"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestRecordState(t *testing.T) {
+	tests := []struct {
+		name          string
+		env           map[string]string
+		annotations   map[string]string
+		wantResources string
+		wantErr       string
+	}{
+		{
+			name:          "should record the created resources",
+			wantResources: "Deployment/ia-web-82b3ade9,Ingress/ia-web-82b3ade9,Service/ia-web-82b3ade9",
+		},
+		{
+			name:          "should record the created direct services",
+			annotations:   map[string]string{config.AnnotationKeyMode.String(): string(config.ModeShadow)},
+			wantResources: "Deployment/ia-web-82b3ade9,Ingress/ia-web-82b3ade9,Service/ia-web-82b3ade9,Service/ia-web-82b3ade9-direct",
+		},
+		{
+			name:        "should record errors",
+			env:         map[string]string{"WRAPPED_INGRESS_DIALECT": "traefik"},
+			annotations: map[string]string{config.AnnotationKeyMode.String(): string(config.ModeShadow)},
+			wantErr:     `shadow mode is not supported with wrapped ingress dialect "traefik"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := map[string]string{"NAMESPACE": "ingress-anubis"}
+			maps.Copy(env, tt.env)
+			cfg := testConfig(t, env)
+			ing := testIngress(cfg, tt.annotations)
+			ir := newTestReconciler(t, cfg, ing)
+			req := reconcile.Request{NamespacedName: crclient.ObjectKeyFromObject(ing)}
+
+			// The first reconciliation only adds the finalizer.
+			for range 2 {
+				if _, err := ir.Reconcile(t.Context(), req); err != nil && tt.wantErr == "" {
+					t.Fatalf("Reconcile() error = %v", err)
+				}
+			}
+
+			var got networkingv1.Ingress
+			if err := ir.client.Get(t.Context(), req.NamespacedName, &got); err != nil {
+				t.Fatalf("failed to get ingress: %v", err)
+			}
+			if got.Annotations[ObservedGenerationAnnotation] == "" || got.Annotations[LastReconciledAnnotation] == "" {
+				t.Errorf("ingress is missing the observed generation or last reconciled time: %v", got.Annotations)
+			}
+			if res := got.Annotations[ResourcesAnnotation]; res != tt.wantResources {
+				t.Errorf("%s = %q, want %q", ResourcesAnnotation, res, tt.wantResources)
+			}
+			if errMsg := got.Annotations[ErrorAnnotation]; (errMsg == "") != (tt.wantErr == "") || !strings.Contains(errMsg, tt.wantErr) {
+				t.Errorf("%s = %q, want %q", ErrorAnnotation, errMsg, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// statusReconciler mirrors the status of managed (child) ingresses to
+// the ingresses owning them, see [IngressReconciler.mirrorStatus]. It
+// runs as its own controller, keyed by the owning ingress and fed by the
+// changes to its children (see [ownerRequests]), so that addresses are
+// propagated as soon as they're assigned instead of waiting behind
+// reconciliations of other ingresses.
+type statusReconciler struct {
+	ir *IngressReconciler
+}
+
+// Reconcile implements [reconcile.Reconciler].
+func (sr *statusReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	return reconcile.Result{}, sr.ir.mirrorStatus(ctx, req.NamespacedName)
+}
+
+// statusChangedPredicate returns a predicate filtering out updates to
+// ingresses that don't change their load balancer status.
+func statusChangedPredicate[T crclient.Object]() predicate.TypedPredicate[T] {
+	return predicate.TypedFuncs[T]{
+		UpdateFunc: func(e event.TypedUpdateEvent[T]) bool {
+			oldIng, ok := any(e.ObjectOld).(*networkingv1.Ingress)
+			newIng, ok2 := any(e.ObjectNew).(*networkingv1.Ingress)
+			return !ok || !ok2 || !equality.Semantic.DeepEqual(oldIng.Status.LoadBalancer, newIng.Status.LoadBalancer)
+		},
+	}
+}
+
+// mirrorStatus mirrors the load balancer status of the managed (child)
+// ingresses owned by the ingress with the provided key to it, so that
+// consumers of its status (e.g., external-dns) get the addresses its
+// traffic is actually served on. The addresses of all of its children
+// are merged, see [mergeLoadBalancerStatus].
+//
+// Ingresses without children are left alone unless they use one of our
+// ingress classes, since their status is otherwise managed by another
+// ingress controller. Otherwise, their status is cleared.
+func (ir *IngressReconciler) mirrorStatus(ctx context.Context, key crclient.ObjectKey) error {
+	owningIng := &networkingv1.Ingress{}
+	if err := ir.client.Get(ctx, key, owningIng); err != nil {
+		return crclient.IgnoreNotFound(err)
+	}
+	if !owningIng.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	var children networkingv1.IngressList
+	if err := ir.client.List(ctx, &children, crclient.InNamespace(ir.cfg.Namespace),
+		crclient.MatchingFields{ownerIndex: key.String()}); err != nil {
+		return fmt.Errorf("failed to list wrapped ingresses: %w", err)
+	}
+	children.Items = slices.DeleteFunc(children.Items, func(ing networkingv1.Ingress) bool {
+		return !ing.DeletionTimestamp.IsZero()
+	})
+	if len(children.Items) == 0 {
+		ours, err := ir.hasIngressClass(ctx, owningIng)
+		if err != nil || !ours {
+			return err
+		}
+	}
+
+	status := mergeLoadBalancerStatus(children.Items)
+	if equality.Semantic.DeepEqual(owningIng.Status.LoadBalancer, status) {
+		return nil
+	}
+
+	patch := crclient.StrategicMergeFrom(owningIng.DeepCopy())
+	owningIng.Status.LoadBalancer = status
+	if err := ir.client.Status().Patch(ctx, owningIng, patch); err != nil {
+		return fmt.Errorf("failed to update status: %w", err)
+	}
+	return nil
+}
+
+// mergeLoadBalancerStatus returns the load balancer status of the
+// provided ingresses combined, without duplicate entries and sorted so
+// that it doesn't change with the order of the ingresses.
+func mergeLoadBalancerStatus(ings []networkingv1.Ingress) networkingv1.IngressLoadBalancerStatus {
+	var entries []networkingv1.IngressLoadBalancerIngress
+	for i := range ings {
+		for _, e := range ings[i].Status.LoadBalancer.Ingress {
+			if !slices.ContainsFunc(entries, func(o networkingv1.IngressLoadBalancerIngress) bool {
+				return equality.Semantic.DeepEqual(e, o)
+			}) {
+				entries = append(entries, e)
+			}
+		}
+	}
+	slices.SortStableFunc(entries, func(a, b networkingv1.IngressLoadBalancerIngress) int {
+		return cmp.Or(strings.Compare(a.IP, b.IP), strings.Compare(a.Hostname, b.Hostname))
+	})
+	return networkingv1.IngressLoadBalancerStatus{Ingress: entries}
+}
@@ -0,0 +1,140 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestMergeLoadBalancerStatus(t *testing.T) {
+	ing := func(entries ...networkingv1.IngressLoadBalancerIngress) networkingv1.Ingress {
+		var ing networkingv1.Ingress
+		ing.Status.LoadBalancer.Ingress = entries
+		return ing
+	}
+	lb1 := networkingv1.IngressLoadBalancerIngress{IP: "10.0.0.1"}
+	lb2 := networkingv1.IngressLoadBalancerIngress{IP: "10.0.0.2"}
+	host := networkingv1.IngressLoadBalancerIngress{Hostname: "lb.example.com"}
+
+	tests := []struct {
+		name string
+		ings []networkingv1.Ingress
+		want []networkingv1.IngressLoadBalancerIngress
+	}{
+		{name: "no children", want: nil},
+		{name: "single child", ings: []networkingv1.Ingress{ing(lb2, lb1)}, want: []networkingv1.IngressLoadBalancerIngress{lb1, lb2}},
+		{
+			name: "duplicates across children",
+			ings: []networkingv1.Ingress{ing(lb1, host), ing(host, lb2, lb1)},
+			want: []networkingv1.IngressLoadBalancerIngress{host, lb1, lb2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeLoadBalancerStatus(tt.ings)
+			if diff := cmp.Diff(tt.want, got.Ingress); diff != "" {
+				t.Errorf("mergeLoadBalancerStatus() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStatusReconcilerReconcile(t *testing.T) {
+	lb1 := networkingv1.IngressLoadBalancerIngress{IP: "10.0.0.1"}
+	lb2 := networkingv1.IngressLoadBalancerIngress{IP: "10.0.0.2"}
+
+	tests := []struct {
+		name string
+
+		// class overrides the ingress class of the ingress.
+		class string
+
+		// reconcile reconciles the ingress first, creating its child.
+		reconcile bool
+		status    []networkingv1.IngressLoadBalancerIngress
+		child     []networkingv1.IngressLoadBalancerIngress
+		want      []networkingv1.IngressLoadBalancerIngress
+	}{
+		{
+			name:      "should mirror the addresses of the child",
+			reconcile: true,
+			child:     []networkingv1.IngressLoadBalancerIngress{lb1},
+			want:      []networkingv1.IngressLoadBalancerIngress{lb1},
+		},
+		{
+			name:      "should replace stale addresses",
+			reconcile: true,
+			status:    []networkingv1.IngressLoadBalancerIngress{lb2},
+			child:     []networkingv1.IngressLoadBalancerIngress{lb1},
+			want:      []networkingv1.IngressLoadBalancerIngress{lb1},
+		},
+		{
+			name:   "should clear the status of ingresses without children",
+			status: []networkingv1.IngressLoadBalancerIngress{lb1},
+		},
+		{
+			name:   "should leave ingresses of other classes alone",
+			class:  "other",
+			status: []networkingv1.IngressLoadBalancerIngress{lb1},
+			want:   []networkingv1.IngressLoadBalancerIngress{lb1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, map[string]string{"NAMESPACE": "ingress-anubis"})
+			ing := testIngress(cfg, nil)
+			if tt.class != "" {
+				ing.Spec.IngressClassName = &tt.class
+			}
+			ing.Status.LoadBalancer.Ingress = tt.status
+			ir := newTestReconciler(t, cfg, ing)
+			key := crclient.ObjectKeyFromObject(ing)
+
+			if tt.reconcile {
+				reconcileTestIngress(t, ir, key)
+
+				child := &networkingv1.Ingress{}
+				if err := ir.client.Get(t.Context(), types.NamespacedName{Namespace: "ingress-anubis", Name: "ia-web-82b3ade9"}, child); err != nil {
+					t.Fatalf("failed to get child ingress: %v", err)
+				}
+				child.Status.LoadBalancer.Ingress = tt.child
+				if err := ir.client.Status().Update(t.Context(), child); err != nil {
+					t.Fatalf("failed to update child status: %v", err)
+				}
+			}
+
+			if _, err := (&statusReconciler{ir}).Reconcile(t.Context(), reconcile.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+
+			got := &networkingv1.Ingress{}
+			if err := ir.client.Get(t.Context(), key, got); err != nil {
+				t.Fatalf("failed to get ingress: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got.Status.LoadBalancer.Ingress); diff != "" {
+				t.Errorf("status mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	"go.rgst.io/jaredallard/slogext/v2"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// SourceSecretAnnotation is the annotation on copies of TLS secrets
+// containing the namespace and name of the secret they were copied
+// from. See [IngressReconciler.reconcileTLSSecrets].
+const SourceSecretAnnotation = "ingress-anubis.jaredallard.github.com/source-secret"
+
+// tlsSecretName returns the name of the copy of the TLS secret name for
+// the provided request.
+func (ir *IngressReconciler) tlsSecretName(req reconcile.Request, name string) string {
+	return ir.hashedResourceName(req.Name, req.String()+"/tls/"+name) + "-tls"
+}
+
+// reconcileTLSSecrets copies the TLS secrets referenced by the provided
+// ingress into the controller namespace, since the child ingress can't
+// reference secrets in other namespaces. A map of the names of the
+// secrets to the names of their copies is returned. Secrets that don't
+// exist are skipped, leaving the wrapped ingress controller to fall
+// back to its default certificate as it would for the original ingress.
+func (ir *IngressReconciler) reconcileTLSSecrets(ctx context.Context, ing *networkingv1.Ingress,
+	req reconcile.Request) (map[string]string, error) {
+	copies := make(map[string]string)
+	for _, tls := range ing.Spec.TLS {
+		if tls.SecretName == "" {
+			continue
+		}
+		if _, ok := copies[tls.SecretName]; ok {
+			continue
+		}
+
+		var src corev1.Secret
+		if err := ir.client.Get(ctx, crclient.ObjectKey{Namespace: req.Namespace, Name: tls.SecretName}, &src); err != nil {
+			if apierrors.IsNotFound(err) {
+				ir.recorder.Eventf(ing, nil, corev1.EventTypeWarning, "TLSSecretNotFound", "Reconcile",
+					"TLS secret %q does not exist", tls.SecretName)
+				continue
+			}
+			return nil, fmt.Errorf("failed to get TLS secret %q: %w", tls.SecretName, err)
+		}
+
+		sec := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      ir.tlsSecretName(req, tls.SecretName),
+				Namespace: ir.cfg.Namespace,
+			},
+		}
+		if _, err := ir.apply(ctx, sec, func() error {
+			sec.Labels = map[string]string{
+				ManagedLabel:        "true",
+				OwnerNamespaceLabel: req.Namespace,
+				OwnerNameLabel:      req.Name,
+			}
+			sec.Annotations = map[string]string{SourceSecretAnnotation: crclient.ObjectKeyFromObject(&src).String()}
+			sec.Type = src.Type
+			sec.Data = src.Data
+			return nil
+		}); err != nil {
+			return nil, fmt.Errorf("failed to reconcile TLS secret %q: %w", tls.SecretName, err)
+		}
+		copies[tls.SecretName] = sec.Name
+	}
+
+	return copies, nil
+}
+
+// secretReflector periodically updates the copies of TLS secrets made by
+// [IngressReconciler.reconcileTLSSecrets] when their source changes,
+// e.g., when a certificate is renewed. Secrets in other namespaces
+// aren't watched, to avoid caching every secret in the cluster.
+type secretReflector struct {
+	log    slogext.Logger
+	cfg    *config.Config
+	client crclient.Client
+}
+
+// newSecretReflector creates a new secretReflector for a cluster.
+func newSecretReflector(log slogext.Logger, cfg *config.Config, client crclient.Client) *secretReflector {
+	return &secretReflector{log, cfg, client}
+}
+
+// sync updates all copies of TLS secrets whose source changed.
+func (sr *secretReflector) sync(ctx context.Context) error {
+	var secs corev1.SecretList
+	if err := sr.client.List(ctx, &secs, crclient.InNamespace(sr.cfg.Namespace),
+		crclient.MatchingLabels{ManagedLabel: "true"}); err != nil {
+		return fmt.Errorf("failed to list TLS secrets: %w", err)
+	}
+
+	for i := range secs.Items {
+		sec := &secs.Items[i]
+		ns, name, ok := strings.Cut(sec.Annotations[SourceSecretAnnotation], "/")
+		if !ok {
+			continue
+		}
+
+		// Secrets that no longer exist are pruned by the reconciler.
+		var src corev1.Secret
+		if err := sr.client.Get(ctx, crclient.ObjectKey{Namespace: ns, Name: name}, &src); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get TLS secret %s/%s: %w", ns, name, err)
+		}
+		if maps.EqualFunc(sec.Data, src.Data, bytes.Equal) {
+			continue
+		}
+
+		patch := crclient.MergeFrom(sec.DeepCopy())
+		sec.Data = src.Data
+		if err := sr.client.Patch(ctx, sec, patch); err != nil {
+			return fmt.Errorf("failed to update TLS secret %s: %w", sec.Name, err)
+		}
+		sr.log.Info("updated TLS secret", slog.String("name", sec.Name), slog.String("source", src.Namespace+"/"+src.Name))
+	}
+
+	return nil
+}
+
+// Start implements [manager.Runnable].
+func (sr *secretReflector) Start(ctx context.Context) error {
+	t := time.NewTicker(sr.cfg.TLSSecretSyncInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+
+		if err := sr.sync(ctx); err != nil {
+			sr.log.Error("failed to sync TLS secrets", slog.String("err", err.Error()))
+		}
+	}
+}
+
+// NeedLeaderElection implements [manager.LeaderElectionRunnable].
+func (sr *secretReflector) NeedLeaderElection() bool {
+	return true
+}
@@ -0,0 +1,172 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.rgst.io/jaredallard/slogext/v2"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// tlsSecret returns a TLS secret with the provided certificate.
+func tlsSecret(namespace, name, cert string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte(cert), corev1.TLSPrivateKeyKey: []byte("key")},
+	}
+}
+
+func TestReconcileTLSSecrets(t *testing.T) {
+	tests := []struct {
+		name     string
+		secrets  []string
+		objs     []crclient.Object
+		wantCopy map[string]bool
+	}{
+		{
+			name:     "should copy TLS secrets",
+			secrets:  []string{"web-tls"},
+			objs:     []crclient.Object{tlsSecret("default", "web-tls", "cert")},
+			wantCopy: map[string]bool{"web-tls": true},
+		},
+		{
+			name:     "should copy TLS secrets used by multiple hosts once",
+			secrets:  []string{"web-tls", "web-tls"},
+			objs:     []crclient.Object{tlsSecret("default", "web-tls", "cert")},
+			wantCopy: map[string]bool{"web-tls": true},
+		},
+		{
+			name:     "should skip missing TLS secrets",
+			secrets:  []string{"web-tls", "missing"},
+			objs:     []crclient.Object{tlsSecret("default", "web-tls", "cert")},
+			wantCopy: map[string]bool{"web-tls": true, "missing": false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, map[string]string{"NAMESPACE": "ingress-anubis"})
+			ing := testIngress(cfg, nil)
+			for _, name := range tt.secrets {
+				ing.Spec.TLS = append(ing.Spec.TLS, networkingv1.IngressTLS{SecretName: name})
+			}
+			ir := newTestReconciler(t, cfg, append(tt.objs, ing)...)
+			req := reconcile.Request{NamespacedName: crclient.ObjectKeyFromObject(ing)}
+			reconcileTestIngress(t, ir, req.NamespacedName)
+
+			var secs corev1.SecretList
+			if err := ir.client.List(t.Context(), &secs, crclient.InNamespace("ingress-anubis")); err != nil {
+				t.Fatalf("failed to list secrets: %v", err)
+			}
+			var copies int
+			for _, copied := range tt.wantCopy {
+				if copied {
+					copies++
+				}
+			}
+			if len(secs.Items) != copies {
+				t.Errorf("got %d copies of TLS secrets, want %d", len(secs.Items), copies)
+			}
+
+			var child networkingv1.Ingress
+			if err := ir.client.Get(t.Context(), crclient.ObjectKey{Namespace: "ingress-anubis", Name: "ia-web-82b3ade9"}, &child); err != nil {
+				t.Fatalf("failed to get child ingress: %v", err)
+			}
+			for i, tls := range child.Spec.TLS {
+				src := tt.secrets[i]
+				if !tt.wantCopy[src] {
+					if tls.SecretName != src {
+						t.Errorf("secret of TLS %d = %q, want the original %q", i, tls.SecretName, src)
+					}
+					continue
+				}
+
+				want := ir.tlsSecretName(req, src)
+				if tls.SecretName != want {
+					t.Errorf("secret of TLS %d = %q, want the copy %q", i, tls.SecretName, want)
+				}
+				var sec corev1.Secret
+				if err := ir.client.Get(t.Context(), crclient.ObjectKey{Namespace: "ingress-anubis", Name: want}, &sec); err != nil {
+					t.Fatalf("failed to get copy of TLS secret: %v", err)
+				}
+				if diff := cmp.Diff(tlsSecret("default", src, "cert").Data, sec.Data); diff != "" {
+					t.Errorf("copy of TLS secret mismatch (-want +got):\n%s", diff)
+				}
+				if got := sec.Annotations[SourceSecretAnnotation]; got != "default/"+src {
+					t.Errorf("%s = %q, want %q", SourceSecretAnnotation, got, "default/"+src)
+				}
+			}
+		})
+	}
+}
+
+func TestSecretReflectorSync(t *testing.T) {
+	copied := func(cert string) *corev1.Secret {
+		sec := tlsSecret("ingress-anubis", "ia-web-tls", cert)
+		sec.Labels = map[string]string{ManagedLabel: "true"}
+		sec.Annotations = map[string]string{SourceSecretAnnotation: "default/web-tls"}
+		return sec
+	}
+
+	tests := []struct {
+		name     string
+		objs     []crclient.Object
+		wantCert string
+	}{
+		{
+			name:     "should update outdated copies",
+			objs:     []crclient.Object{tlsSecret("default", "web-tls", "renewed"), copied("expired")},
+			wantCert: "renewed",
+		},
+		{
+			name:     "should keep up to date copies",
+			objs:     []crclient.Object{tlsSecret("default", "web-tls", "cert"), copied("cert")},
+			wantCert: "cert",
+		},
+		{
+			name:     "should keep copies of missing secrets",
+			objs:     []crclient.Object{copied("cert")},
+			wantCert: "cert",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, map[string]string{"NAMESPACE": "ingress-anubis"})
+			client := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(tt.objs...).Build()
+			if err := newSecretReflector(slogext.New(), cfg, client).sync(t.Context()); err != nil {
+				t.Fatalf("sync() error = %v", err)
+			}
+
+			var sec corev1.Secret
+			if err := client.Get(t.Context(), crclient.ObjectKey{Namespace: "ingress-anubis", Name: "ia-web-tls"}, &sec); err != nil {
+				t.Fatalf("failed to get copy of TLS secret: %v", err)
+			}
+			if got := string(sec.Data[corev1.TLSCertKey]); got != tt.wantCert {
+				t.Errorf("certificate = %q, want %q", got, tt.wantCert)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jaredallard/ingress-anubis/internal/config"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// anubisChallengeMarker is contained in the body of the challenge page
+// served by Anubis.
+const anubisChallengeMarker = "anubis_challenge"
+
+// verifyUserAgent is the user-agent used for verification requests. It
+// is browser-like to ensure that Anubis' default policy challenges it.
+const verifyUserAgent = "Mozilla/5.0 (compatible; ingress-anubis-verify)"
+
+// errRoutingPending is returned by [routeVerifier.verify] when the
+// ingress doesn't have an address to verify against yet.
+var errRoutingPending = errors.New("ingress has no load balancer address yet")
+
+// routeVerifier verifies that requests to an ingress are actually
+// served by Anubis, catching misconfigured wrapped ingress controllers.
+// A nil routeVerifier disables verification. See
+// [config.Config.VerifyRouting].
+type routeVerifier struct {
+	// cluster is the name of the cluster the verified ingresses are in,
+	// reported in [routingVerified].
+	cluster string
+
+	// timeout is the timeout of a single verification request.
+	timeout time.Duration
+
+	// interval is how often a failing ingress is re-verified.
+	interval time.Duration
+
+	mu       sync.Mutex
+	verified map[types.NamespacedName]bool
+}
+
+// newRouteVerifier creates a new [routeVerifier] for the ingresses of
+// cluster from the provided configuration, returning nil if
+// verification is disabled.
+func newRouteVerifier(cfg *config.Config, cluster string) *routeVerifier {
+	if !cfg.VerifyRouting {
+		return nil
+	}
+
+	return &routeVerifier{
+		cluster:  cluster,
+		timeout:  cfg.VerifyRoutingTimeout,
+		interval: cfg.VerifyRoutingInterval,
+		verified: make(map[types.NamespacedName]bool),
+	}
+}
+
+// getVerifyTarget returns the address and host to verify the provided ingress
+// with, as well as if TLS should be used.
+func getVerifyTarget(ing *networkingv1.Ingress) (addr, host string, useTLS bool, err error) {
+	for _, lb := range ing.Status.LoadBalancer.Ingress {
+		if addr = lb.IP; addr == "" {
+			addr = lb.Hostname
+		}
+		if addr != "" {
+			break
+		}
+	}
+	if addr == "" {
+		return "", "", false, errRoutingPending
+	}
+
+	for _, r := range ing.Spec.Rules {
+		// Wildcards can't be requested.
+		if r.Host != "" && !strings.HasPrefix(r.Host, "*") {
+			host = r.Host
+			break
+		}
+	}
+
+	for _, t := range ing.Spec.TLS {
+		for _, h := range t.Hosts {
+			if host != "" && h == host {
+				useTLS = true
+			}
+		}
+	}
+
+	return addr, host, useTLS, nil
+}
+
+// verifyURL returns the URL of the root of the provided load balancer
+// address, an IP or hostname.
+func verifyURL(scheme, addr string) string {
+	// IPv6 addresses have to be bracketed.
+	if ip := net.ParseIP(addr); ip != nil && strings.Contains(addr, ":") {
+		addr = "[" + addr + "]"
+	}
+	return (&url.URL{Scheme: scheme, Host: addr, Path: "/"}).String()
+}
+
+// verify requests the provided ingress through its load balancer
+// address and ensures that the Anubis challenge is served.
+func (rv *routeVerifier) verify(ctx context.Context, ing *networkingv1.Ingress) error {
+	addr, host, useTLS, err := getVerifyTarget(ing)
+	if err != nil {
+		return err
+	}
+
+	scheme := "http"
+	if useTLS {
+		scheme = "https"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, rv.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, verifyURL(scheme, addr), http.NoBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", verifyUserAgent)
+	req.Header.Set("Accept", "text/html")
+	if host != "" {
+		req.Host = host
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				ServerName: host,
+				//nolint:gosec // Why: We're only verifying routing, not the certificate.
+				InsecureSkipVerify: true,
+			},
+		},
+		// Redirects (e.g., to a login page) mean Anubis wasn't in front.
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	defer client.CloseIdleConnections()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to request %s: %w", req.URL, err)
+	}
+	defer resp.Body.Close()
+
+	// Challenge pages are small, don't read entire sites.
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if !strings.Contains(string(body), anubisChallengeMarker) {
+		return fmt.Errorf("anubis challenge was not served for host %q via %s (status %d)", host, addr, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// observe records the result of verifying the ingress with the provided
+// key, returning true if it changed from the last result.
+func (rv *routeVerifier) observe(key types.NamespacedName, ok bool) bool {
+	rv.mu.Lock()
+	defer rv.mu.Unlock()
+
+	prev, seen := rv.verified[key]
+	rv.verified[key] = ok
+	if ok {
+		routingVerified.WithLabelValues(rv.cluster, key.Namespace, key.Name).Set(1)
+	} else {
+		routingVerified.WithLabelValues(rv.cluster, key.Namespace, key.Name).Set(0)
+	}
+
+	return !seen || prev != ok
+}
+
+// forget removes all state for the ingress with the provided key, e.g.,
+// because it was deleted.
+func (rv *routeVerifier) forget(key types.NamespacedName) {
+	if rv == nil {
+		return
+	}
+
+	rv.mu.Lock()
+	defer rv.mu.Unlock()
+
+	delete(rv.verified, key)
+	routingVerified.DeleteLabelValues(rv.cluster, key.Namespace, key.Name)
+}
@@ -0,0 +1,36 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package controller
+
+import "testing"
+
+func TestVerifyURL(t *testing.T) {
+	tests := []struct {
+		scheme, addr, want string
+	}{
+		{"http", "10.0.0.1", "http://10.0.0.1/"},
+		{"https", "lb.example.com", "https://lb.example.com/"},
+		{"http", "2001:db8::1", "http://[2001:db8::1]/"},
+		{"https", "::ffff:10.0.0.1", "https://[::ffff:10.0.0.1]/"},
+	}
+	for _, tt := range tests {
+		if got := verifyURL(tt.scheme, tt.addr); got != tt.want {
+			t.Errorf("verifyURL(%q, %q) = %q, want %q", tt.scheme, tt.addr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package translate
+
+import (
+	"strconv"
+	"strings"
+)
+
+// RuleFunc translates the value of a single ingress-nginx annotation,
+// returning nil if the value can't be translated.
+type RuleFunc func(value string) map[string]string
+
+// Rules is a [Translator] backed by a RuleFunc per ingress-nginx
+// annotation (without [SourcePrefix]). Annotations without a rule can't
+// be translated.
+type Rules map[string]RuleFunc
+
+// Translate implements [Translator].
+func (r Rules) Translate(key, value string) (map[string]string, bool) {
+	fn, ok := r[strings.TrimPrefix(key, SourcePrefix)]
+	if !ok {
+		return nil, false
+	}
+
+	resp := fn(value)
+	return resp, resp != nil
+}
+
+// same returns a [RuleFunc] that copies the value as-is to key.
+func same(key string) RuleFunc {
+	return func(value string) map[string]string {
+		return map[string]string{key: value}
+	}
+}
+
+// seconds returns a [RuleFunc] that converts an ingress-nginx timeout
+// (in seconds) into a duration (e.g., "60s") set on key.
+func seconds(key string) RuleFunc {
+	return func(value string) map[string]string {
+		if _, err := strconv.Atoi(value); err != nil {
+			return nil
+		}
+		return map[string]string{key: value + "s"}
+	}
+}
+
+// millis returns a [RuleFunc] that converts an ingress-nginx timeout (in
+// seconds) into milliseconds set on key.
+func millis(key string) RuleFunc {
+	return func(value string) map[string]string {
+		s, err := strconv.Atoi(value)
+		if err != nil {
+			return nil
+		}
+		return map[string]string{key: strconv.Itoa(s * 1000)}
+	}
+}
+
+// haproxyRules translates to HAProxy Ingress annotations. See:
+// https://haproxy-ingress.github.io/docs/configuration/keys/
+var haproxyRules = Rules{
+	"proxy-body-size":        same("haproxy-ingress.github.io/proxy-body-size"),
+	"proxy-connect-timeout":  seconds("haproxy-ingress.github.io/timeout-connect"),
+	"proxy-read-timeout":     seconds("haproxy-ingress.github.io/timeout-server"),
+	"ssl-redirect":           same("haproxy-ingress.github.io/ssl-redirect"),
+	"force-ssl-redirect":     same("haproxy-ingress.github.io/ssl-redirect"),
+	"whitelist-source-range": same("haproxy-ingress.github.io/allowlist-source-range"),
+	"allowlist-source-range": same("haproxy-ingress.github.io/allowlist-source-range"),
+	"rewrite-target":         same("haproxy-ingress.github.io/rewrite-target"),
+	"enable-cors":            same("haproxy-ingress.github.io/cors-enable"),
+	"cors-allow-origin":      same("haproxy-ingress.github.io/cors-allow-origin"),
+	"affinity":               same("haproxy-ingress.github.io/affinity"),
+	"session-cookie-name":    same("haproxy-ingress.github.io/session-cookie-name"),
+	"limit-connections":      same("haproxy-ingress.github.io/limit-connections"),
+	"limit-rps":              same("haproxy-ingress.github.io/limit-rps"),
+	"app-root":               same("haproxy-ingress.github.io/app-root"),
+	"auth-url":               same("haproxy-ingress.github.io/auth-url"),
+	"auth-signin":            same("haproxy-ingress.github.io/auth-signin"),
+	"ssl-passthrough":        same("haproxy-ingress.github.io/ssl-passthrough"),
+}
+
+// kongRules translates to Kong Ingress Controller annotations. See:
+// https://developer.konghq.com/kubernetes-ingress-controller/reference/annotations/
+var kongRules = Rules{
+	"proxy-connect-timeout": millis("konghq.com/connect-timeout"),
+	"proxy-read-timeout":    millis("konghq.com/read-timeout"),
+	"proxy-send-timeout":    millis("konghq.com/write-timeout"),
+	"ssl-redirect":          kongSSLRedirect,
+	"force-ssl-redirect":    kongSSLRedirect,
+}
+
+// kongSSLRedirect translates ssl-redirect into only accepting HTTPS and
+// redirecting HTTP requests.
+func kongSSLRedirect(value string) map[string]string {
+	redirect, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil
+	}
+	if !redirect {
+		return map[string]string{"konghq.com/protocols": "http,https"}
+	}
+
+	return map[string]string{
+		"konghq.com/protocols":                  "https",
+		"konghq.com/https-redirect-status-code": "308",
+	}
+}
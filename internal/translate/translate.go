@@ -0,0 +1,117 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+// Package translate converts ingress-nginx annotations into their
+// equivalents for other ingress controllers, so that changing the
+// ingress controller wrapped by Anubis doesn't silently drop behavior
+// like body size limits or timeouts.
+package translate
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// SourcePrefix is the prefix of the annotations that are translated.
+const SourcePrefix = "nginx.ingress.kubernetes.io/"
+
+// Dialect is the annotation dialect of an ingress controller.
+type Dialect string
+
+// Contains the supported dialects.
+const (
+	// DialectNginx is the dialect of ingress-nginx. Since annotations are
+	// written for ingress-nginx, no translation is done.
+	DialectNginx Dialect = "nginx"
+
+	// DialectTraefik is the dialect of Traefik.
+	DialectTraefik Dialect = "traefik"
+
+	// DialectHAProxy is the dialect of HAProxy Ingress
+	// (haproxy-ingress.github.io).
+	DialectHAProxy Dialect = "haproxy"
+
+	// DialectKong is the dialect of the Kong Ingress Controller.
+	DialectKong Dialect = "kong"
+)
+
+// Translator translates ingress-nginx annotations into those of another
+// dialect.
+type Translator interface {
+	// Translate returns the annotations equivalent to the provided
+	// ingress-nginx annotation, or false if it can't be translated.
+	Translate(key, value string) (map[string]string, bool)
+}
+
+// translators contains all registered translators by dialect.
+var translators = map[Dialect]Translator{
+	DialectNginx:   Rules{},
+	DialectTraefik: Rules{},
+	DialectHAProxy: haproxyRules,
+	DialectKong:    kongRules,
+}
+
+// Register registers a [Translator] for the provided dialect, replacing
+// any existing one. It is not safe to call concurrently with
+// [Annotations].
+func Register(d Dialect, t Translator) {
+	translators[d] = t
+}
+
+// Get returns the [Translator] for the provided dialect.
+func Get(d Dialect) (Translator, error) {
+	t, ok := translators[d]
+	if !ok {
+		return nil, fmt.Errorf("unknown annotation dialect %q", d)
+	}
+	return t, nil
+}
+
+// Annotations returns a copy of annotations with all ingress-nginx
+// annotations translated to the provided dialect added. The original
+// annotations are kept. The keys of ingress-nginx annotations that
+// couldn't be translated are returned, sorted.
+func Annotations(d Dialect, annotations map[string]string) (map[string]string, []string, error) {
+	if d == DialectNginx {
+		return maps.Clone(annotations), nil, nil
+	}
+
+	t, err := Get(d)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp := maps.Clone(annotations)
+	var untranslated []string
+	for k, v := range annotations {
+		if !strings.HasPrefix(k, SourcePrefix) {
+			continue
+		}
+
+		translated, ok := t.Translate(k, v)
+		if !ok {
+			untranslated = append(untranslated, k)
+			continue
+		}
+		maps.Insert(resp, maps.All(translated))
+	}
+	slices.Sort(untranslated)
+
+	return resp, untranslated, nil
+}
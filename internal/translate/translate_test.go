@@ -0,0 +1,100 @@
+// Copyright (C) 2026 ingress-anubis contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// SPDX-License-Identifier: GPL-3.0
+
+package translate
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAnnotations(t *testing.T) {
+	type args struct {
+		d           Dialect
+		annotations map[string]string
+	}
+	tests := []struct {
+		name             string
+		args             args
+		want             map[string]string
+		wantUntranslated []string
+		wantErr          bool
+	}{
+		{
+			name: "should not translate nginx",
+			args: args{DialectNginx, map[string]string{SourcePrefix + "proxy-body-size": "8m"}},
+			want: map[string]string{SourcePrefix + "proxy-body-size": "8m"},
+		},
+		{
+			name: "should translate to haproxy",
+			args: args{DialectHAProxy, map[string]string{
+				SourcePrefix + "proxy-body-size":    "8m",
+				SourcePrefix + "proxy-read-timeout": "60",
+				"unrelated":                         "value",
+			}},
+			want: map[string]string{
+				SourcePrefix + "proxy-body-size":            "8m",
+				SourcePrefix + "proxy-read-timeout":         "60",
+				"unrelated":                                 "value",
+				"haproxy-ingress.github.io/proxy-body-size": "8m",
+				"haproxy-ingress.github.io/timeout-server":  "60s",
+			},
+		},
+		{
+			name: "should translate timeouts to kong in milliseconds",
+			args: args{DialectKong, map[string]string{SourcePrefix + "proxy-read-timeout": "60"}},
+			want: map[string]string{
+				SourcePrefix + "proxy-read-timeout": "60",
+				"konghq.com/read-timeout":           "60000",
+			},
+		},
+		{
+			name: "should return untranslated annotations",
+			args: args{DialectTraefik, map[string]string{
+				SourcePrefix + "proxy-read-timeout": "60",
+				SourcePrefix + "proxy-body-size":    "8m",
+			}},
+			want: map[string]string{
+				SourcePrefix + "proxy-read-timeout": "60",
+				SourcePrefix + "proxy-body-size":    "8m",
+			},
+			wantUntranslated: []string{SourcePrefix + "proxy-body-size", SourcePrefix + "proxy-read-timeout"},
+		},
+		{
+			name:    "should fail on an unknown dialect",
+			args:    args{Dialect("unknown"), map[string]string{}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, untranslated, err := Annotations(tt.args.d, tt.args.annotations)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Annotations() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Annotations() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantUntranslated, untranslated); diff != "" {
+				t.Errorf("Annotations() untranslated mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}